@@ -30,7 +30,7 @@ func init() {
 	os.Setenv("BLOG_PORT", "8082")
 
 	// Database URL must be set in Lambda Environment Variables
-	// JWT_SECRET must be set in Lambda Environment Variables
+	// JWT_KEYS_DIR must point at the signing keys (e.g. a mounted secret)
 
 	log.Println("Initializing Lambda Monolith...")
 
@@ -8,41 +8,41 @@ TRUNCATE TABLE users, posts, interactions, follows, bookmarks, tags, post_tags C
 -- ============================================================================
 -- USERS (25 diverse users)
 -- ============================================================================
-INSERT INTO users (id, email, name, password_hash, bio, avatar_url, created_at, updated_at) VALUES
+INSERT INTO users (id, email, name, bio, avatar_url, created_at, updated_at) VALUES
 -- Tech Writers & Engineers
-('00000000-0000-0000-0000-000000000001', 'alice@example.com', 'Alice Chen', '$2a$10$hash', 'Senior Staff Engineer @Google | Distributed Systems | Go & Kubernetes enthusiast', 'https://i.pravatar.cc/150?img=1', NOW() - INTERVAL '6 months', NOW()),
-('00000000-0000-0000-0000-000000000002', 'bob@example.com', 'Bob Martinez', '$2a$10$hash', 'Full-stack developer | React, Node.js, TypeScript | Building scalable apps', 'https://i.pravatar.cc/150?img=12', NOW() - INTERVAL '4 months', NOW()),
-('00000000-0000-0000-0000-000000000003', 'charlie@example.com', 'Charlie Kim', '$2a$10$hash', 'DevOps Engineer | Terraform | Docker | CI/CD pipelines | Cloud architecture', 'https://i.pravatar.cc/150?img=33', NOW() - INTERVAL '5 months', NOW()),
-('00000000-0000-0000-0000-000000000004', 'diana@example.com', 'Diana Prince', '$2a$10$hash', 'Product Manager @Meta | UX Research | Data-driven decisions', 'https://i.pravatar.cc/150?img=47', NOW() - INTERVAL '3 months', NOW()),
-('00000000-0000-0000-0000-000000000005', 'test@example.com', 'Sarah Johnson', '$2a$10$hash', 'Tech Lead @Netflix | Microservices | Author of "Scale at Speed"', 'https://i.pravatar.cc/150?img=50', NOW() - INTERVAL '7 months', NOW()),
+('00000000-0000-0000-0000-000000000001', 'alice@example.com', 'Alice Chen', 'Senior Staff Engineer @Google | Distributed Systems | Go & Kubernetes enthusiast', 'https://i.pravatar.cc/150?img=1', NOW() - INTERVAL '6 months', NOW()),
+('00000000-0000-0000-0000-000000000002', 'bob@example.com', 'Bob Martinez', 'Full-stack developer | React, Node.js, TypeScript | Building scalable apps', 'https://i.pravatar.cc/150?img=12', NOW() - INTERVAL '4 months', NOW()),
+('00000000-0000-0000-0000-000000000003', 'charlie@example.com', 'Charlie Kim', 'DevOps Engineer | Terraform | Docker | CI/CD pipelines | Cloud architecture', 'https://i.pravatar.cc/150?img=33', NOW() - INTERVAL '5 months', NOW()),
+('00000000-0000-0000-0000-000000000004', 'diana@example.com', 'Diana Prince', 'Product Manager @Meta | UX Research | Data-driven decisions', 'https://i.pravatar.cc/150?img=47', NOW() - INTERVAL '3 months', NOW()),
+('00000000-0000-0000-0000-000000000005', 'test@example.com', 'Sarah Johnson', 'Tech Lead @Netflix | Microservices | Author of "Scale at Speed"', 'https://i.pravatar.cc/150?img=50', NOW() - INTERVAL '7 months', NOW()),
 
 -- Content Creators
-('00000000-0000-0000-0000-000000000006', 'emily@example.com', 'Emily Rodriguez', '$2a$10$hash', 'Technical Writer | Making complex topics simple | Ex-Amazon', 'https://i.pravatar.cc/150?img=5', NOW() - INTERVAL '2 months', NOW()),
-('00000000-0000-0000-0000-000000000007', 'frank@example.com', 'Frank Zhang', '$2a$10$hash', 'Frontend Architect | CSS Grid & Animations | Speaker', 'https://i.pravatar.cc/150?img=15', NOW() - INTERVAL '8 months', NOW()),
-('00000000-0000-0000-0000-000000000008', 'grace@example.com', 'Grace Thompson', '$2a$10$hash', 'ML Engineer | PyTorch | Computer Vision | Ph.D. Stanford', 'https://i.pravatar.cc/150?img=28', NOW() - INTERVAL '1 year', NOW()),
-('00000000-0000-0000-0000-000000000009', 'henry@example.com', 'Henry Okonkwo', '$2a$10$hash', 'Blockchain Developer | Solidity | Web3 | DeFi protocols', 'https://i.pravatar.cc/150?img=52', NOW() - INTERVAL '3 months', NOW()),
-('00000000-0000-0000-0000-000000000010', 'isabel@example.com', 'Isabel Santos', '$2a$10$hash', 'Security Researcher | Penetration Testing | Bug Bounty Hunter', 'https://i.pravatar.cc/150?img=45', NOW() - INTERVAL '5 months', NOW()),
+('00000000-0000-0000-0000-000000000006', 'emily@example.com', 'Emily Rodriguez', 'Technical Writer | Making complex topics simple | Ex-Amazon', 'https://i.pravatar.cc/150?img=5', NOW() - INTERVAL '2 months', NOW()),
+('00000000-0000-0000-0000-000000000007', 'frank@example.com', 'Frank Zhang', 'Frontend Architect | CSS Grid & Animations | Speaker', 'https://i.pravatar.cc/150?img=15', NOW() - INTERVAL '8 months', NOW()),
+('00000000-0000-0000-0000-000000000008', 'grace@example.com', 'Grace Thompson', 'ML Engineer | PyTorch | Computer Vision | Ph.D. Stanford', 'https://i.pravatar.cc/150?img=28', NOW() - INTERVAL '1 year', NOW()),
+('00000000-0000-0000-0000-000000000009', 'henry@example.com', 'Henry Okonkwo', 'Blockchain Developer | Solidity | Web3 | DeFi protocols', 'https://i.pravatar.cc/150?img=52', NOW() - INTERVAL '3 months', NOW()),
+('00000000-0000-0000-0000-000000000010', 'isabel@example.com', 'Isabel Santos', 'Security Researcher | Penetration Testing | Bug Bounty Hunter', 'https://i.pravatar.cc/150?img=45', NOW() - INTERVAL '5 months', NOW()),
 
 -- Designers & Creative
-('00000000-0000-0000-0000-000000000011', 'jack@example.com', 'Jack Morrison', '$2a$10$hash', 'Senior UX Designer @Airbnb | Design Systems | Figma expert', 'https://i.pravatar.cc/150?img=60', NOW() - INTERVAL '4 months', NOW()),
-('00000000-0000-0000-0000-000000000012', 'kate@example.com', 'Kate Williams', '$2a$10$hash', 'Illustrator & Animator | Creative coding | p5.js', 'https://i.pravatar.cc/150?img=20', NOW() - INTERVAL '6 months', NOW()),
-('00000000-0000-0000-0000-000000000013', 'liam@example.com', 'Liam Patel', '$2a$10$hash', 'Mobile Developer | Flutter & React Native | 50+ apps shipped', 'https://i.pravatar.cc/150?img=31', NOW() - INTERVAL '9 months', NOW()),
-('00000000-0000-0000-0000-000000000014', 'maya@example.com', 'Maya Cohen', '$2a$10$hash', 'Data Scientist | NLP | Transformers | Ex-OpenAI researcher', 'https://i.pravatar.cc/150?img=24', NOW() - INTERVAL '2 months', NOW()),
-('00000000-0000-0000-0000-000000000015', 'noah@example.com', 'Noah Schmidt', '$2a$10$hash', 'Game Developer | Unity & Unreal Engine | VR/AR enthusiast', 'https://i.pravatar.cc/150?img=56', NOW() - INTERVAL '7 months', NOW()),
+('00000000-0000-0000-0000-000000000011', 'jack@example.com', 'Jack Morrison', 'Senior UX Designer @Airbnb | Design Systems | Figma expert', 'https://i.pravatar.cc/150?img=60', NOW() - INTERVAL '4 months', NOW()),
+('00000000-0000-0000-0000-000000000012', 'kate@example.com', 'Kate Williams', 'Illustrator & Animator | Creative coding | p5.js', 'https://i.pravatar.cc/150?img=20', NOW() - INTERVAL '6 months', NOW()),
+('00000000-0000-0000-0000-000000000013', 'liam@example.com', 'Liam Patel', 'Mobile Developer | Flutter & React Native | 50+ apps shipped', 'https://i.pravatar.cc/150?img=31', NOW() - INTERVAL '9 months', NOW()),
+('00000000-0000-0000-0000-000000000014', 'maya@example.com', 'Maya Cohen', 'Data Scientist | NLP | Transformers | Ex-OpenAI researcher', 'https://i.pravatar.cc/150?img=24', NOW() - INTERVAL '2 months', NOW()),
+('00000000-0000-0000-0000-000000000015', 'noah@example.com', 'Noah Schmidt', 'Game Developer | Unity & Unreal Engine | VR/AR enthusiast', 'https://i.pravatar.cc/150?img=56', NOW() - INTERVAL '7 months', NOW()),
 
 -- Industry Experts
-('00000000-0000-0000-0000-000000000016', 'olivia@example.com', 'Olivia Brown', '$2a$10$hash', 'Engineering Manager @Stripe | Team building | Career advice', 'https://i.pravatar.cc/150?img=10', NOW() - INTERVAL '1 year 2 months', NOW()),
-('00000000-0000-0000-0000-000000000017', 'peter@example.com', 'Peter Ivanov', '$2a$10$hash', 'Database Architect | PostgreSQL expert | Performance tuning wizard', 'https://i.pravatar.cc/150?img=67', NOW() - INTERVAL '3 months', NOW()),
-('00000000-0000-0000-0000-000000000018', 'quinn@example.com', 'Quinn Taylor', '$2a$10$hash', 'Startup Founder | SaaS growth hacker | 2x YC alumni', 'https://i.pravatar.cc/150?img=42', NOW() - INTERVAL '5 months', NOW()),
-('00000000-0000-0000-0000-000000000019', 'rachel@example.com', 'Rachel Green', '$2a$10$hash', 'Tech Recruiter | Career coach | Helping devs land dream jobs', 'https://i.pravatar.cc/150?img=9', NOW() - INTERVAL '8 months', NOW()),
-('00000000-0000-0000-0000-000000000020', 'sam@example.com', 'Sam Lee', '$2a$10$hash', 'Site Reliability Engineer | Incident management | Observability', 'https://i.pravatar.cc/150?img=70', NOW() - INTERVAL '6 months', NOW()),
+('00000000-0000-0000-0000-000000000016', 'olivia@example.com', 'Olivia Brown', 'Engineering Manager @Stripe | Team building | Career advice', 'https://i.pravatar.cc/150?img=10', NOW() - INTERVAL '1 year 2 months', NOW()),
+('00000000-0000-0000-0000-000000000017', 'peter@example.com', 'Peter Ivanov', 'Database Architect | PostgreSQL expert | Performance tuning wizard', 'https://i.pravatar.cc/150?img=67', NOW() - INTERVAL '3 months', NOW()),
+('00000000-0000-0000-0000-000000000018', 'quinn@example.com', 'Quinn Taylor', 'Startup Founder | SaaS growth hacker | 2x YC alumni', 'https://i.pravatar.cc/150?img=42', NOW() - INTERVAL '5 months', NOW()),
+('00000000-0000-0000-0000-000000000019', 'rachel@example.com', 'Rachel Green', 'Tech Recruiter | Career coach | Helping devs land dream jobs', 'https://i.pravatar.cc/150?img=9', NOW() - INTERVAL '8 months', NOW()),
+('00000000-0000-0000-0000-000000000020', 'sam@example.com', 'Sam Lee', 'Site Reliability Engineer | Incident management | Observability', 'https://i.pravatar.cc/150?img=70', NOW() - INTERVAL '6 months', NOW()),
 
 -- Emerging Voices
-('00000000-0000-0000-0000-000000000021', 'tina@example.com', 'Tina Nguyen', '$2a$10$hash', 'Junior Developer documenting my learning journey | #100DaysOfCode', 'https://i.pravatar.cc/150?img=32', NOW() - INTERVAL '2 weeks', NOW()),
-('00000000-0000-0000-0000-000000000022', 'uma@example.com', 'Uma Patel', '$2a$10$hash', 'API Design enthusiast | REST vs GraphQL | Documentation nerd', 'https://i.pravatar.cc/150?img=44', NOW() - INTERVAL '1 month', NOW()),
-('00000000-0000-0000-0000-000000000023', 'victor@example.com', 'Victor Osei', '$2a$10$hash', 'Cloud Solutions Architect | AWS Certified | Serverless fanatic', 'https://i.pravatar.cc/150?img=68', NOW() - INTERVAL '10 months', NOW()),
-('00000000-0000-0000-0000-000000000024', 'wendy@example.com', 'Wendy Liu', '$2a$10$hash', 'QA Engineer | Test automation | Cypress & Playwright', 'https://i.pravatar.cc/150?img=16', NOW() - INTERVAL '4 months', NOW()),
-('00000000-0000-0000-0000-000000000025', 'xavier@example.com', 'Xavier Torres', '$2a$10$hash', 'Indie hacker | Building products solo | $10k MRR journey', 'https://i.pravatar.cc/150?img=51', NOW() - INTERVAL '3 months', NOW());
+('00000000-0000-0000-0000-000000000021', 'tina@example.com', 'Tina Nguyen', 'Junior Developer documenting my learning journey | #100DaysOfCode', 'https://i.pravatar.cc/150?img=32', NOW() - INTERVAL '2 weeks', NOW()),
+('00000000-0000-0000-0000-000000000022', 'uma@example.com', 'Uma Patel', 'API Design enthusiast | REST vs GraphQL | Documentation nerd', 'https://i.pravatar.cc/150?img=44', NOW() - INTERVAL '1 month', NOW()),
+('00000000-0000-0000-0000-000000000023', 'victor@example.com', 'Victor Osei', 'Cloud Solutions Architect | AWS Certified | Serverless fanatic', 'https://i.pravatar.cc/150?img=68', NOW() - INTERVAL '10 months', NOW()),
+('00000000-0000-0000-0000-000000000024', 'wendy@example.com', 'Wendy Liu', 'QA Engineer | Test automation | Cypress & Playwright', 'https://i.pravatar.cc/150?img=16', NOW() - INTERVAL '4 months', NOW()),
+('00000000-0000-0000-0000-000000000025', 'xavier@example.com', 'Xavier Torres', 'Indie hacker | Building products solo | $10k MRR journey', 'https://i.pravatar.cc/150?img=51', NOW() - INTERVAL '3 months', NOW());
 
 -- ============================================================================
 -- TAGS (30 categories)
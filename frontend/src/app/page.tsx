@@ -1,4 +1,5 @@
 import Link from "next/link";
+import TrendingPosts from "@/components/TrendingPosts";
 
 export default function Home() {
   return (
@@ -52,50 +53,7 @@ export default function Home() {
             <h3 className="text-sm font-semibold tracking-wide text-gray-500 uppercase mb-8">
               Trending on Minimum
             </h3>
-            <div className="grid grid-cols-1 md:grid-cols-3 gap-8">
-              {/* Preview Cards */}
-              <div className="flex space-x-4">
-                <div className="text-4xl font-bold text-gray-300">01</div>
-                <div className="flex-1">
-                  <div className="flex items-center space-x-2 mb-2">
-                    <div className="w-5 h-5 rounded-full bg-gray-300"></div>
-                    <span className="text-xs font-medium">Alice Chen</span>
-                  </div>
-                  <h4 className="font-bold text-base mb-1 hover:text-gray-600 cursor-pointer">
-                    Building Scalable Microservices with Go
-                  </h4>
-                  <p className="text-xs text-gray-500">8 min read</p>
-                </div>
-              </div>
-
-              <div className="flex space-x-4">
-                <div className="text-4xl font-bold text-gray-300">02</div>
-                <div className="flex-1">
-                  <div className="flex items-center space-x-2 mb-2">
-                    <div className="w-5 h-5 rounded-full bg-gray-300"></div>
-                    <span className="text-xs font-medium">Bob Martinez</span>
-                  </div>
-                  <h4 className="font-bold text-base mb-1 hover:text-gray-600 cursor-pointer">
-                    React Performance Optimization
-                  </h4>
-                  <p className="text-xs text-gray-500">5 min read</p>
-                </div>
-              </div>
-
-              <div className="flex space-x-4">
-                <div className="text-4xl font-bold text-gray-300">03</div>
-                <div className="flex-1">
-                  <div className="flex items-center space-x-2 mb-2">
-                    <div className="w-5 h-5 rounded-full bg-gray-300"></div>
-                    <span className="text-xs font-medium">Sarah Johnson</span>
-                  </div>
-                  <h4 className="font-bold text-base mb-1 hover:text-gray-600 cursor-pointer">
-                    My Journey Learning Kubernetes
-                  </h4>
-                  <p className="text-xs text-gray-500">6 min read</p>
-                </div>
-              </div>
-            </div>
+            <TrendingPosts />
           </div>
         </div>
 
@@ -66,10 +66,12 @@ export default function EditPostPage() {
         try {
             await updatePost(
                 postId,
+                post?.version ?? 0,
                 title,
                 content,
                 tags.split(',').map(t => t.trim()).filter(Boolean),
-                coverImage
+                coverImage,
+                post?.subtitle
             );
             router.push(`/post/${postId}`);
         } catch (error: any) {
@@ -179,9 +179,13 @@ export default function PostPage() {
 
                 {/* Content */}
                 <div className="prose prose-lg max-w-none">
-                    <p className="text-xl leading-relaxed text-gray-800 mb-6">
-                        {post.content}
-                    </p>
+                    {post.content_html ? (
+                        <div dangerouslySetInnerHTML={{ __html: post.content_html }} />
+                    ) : (
+                        <p className="text-xl leading-relaxed text-gray-800 mb-6">
+                            {post.content}
+                        </p>
+                    )}
 
                     {/* Placeholder for more content */}
                     <p className="leading-relaxed text-gray-700">
@@ -119,7 +119,7 @@ export const PostCard = ({ post }: PostCardProps) => {
                                 {post.title}
                             </h2>
                             <p className="text-base text-gray-500 font-serif leading-snug line-clamp-2 sm:line-clamp-3">
-                                {post.content}
+                                {post.subtitle || post.excerpt}
                             </p>
                         </div>
                     </Link>
@@ -0,0 +1,45 @@
+'use client';
+
+import Link from 'next/link';
+import { useEffect, useState } from 'react';
+import { getTrending, Post } from '@/services/api';
+
+export default function TrendingPosts() {
+    const [posts, setPosts] = useState<Post[]>([]);
+
+    useEffect(() => {
+        getTrending('week', undefined, 6)
+            .then(trending => setPosts(trending.map(t => t.post)))
+            .catch(() => setPosts([]));
+    }, []);
+
+    if (posts.length === 0) {
+        return <p className="text-sm text-gray-500">Nothing is trending yet.</p>;
+    }
+
+    return (
+        <div className="grid grid-cols-1 md:grid-cols-3 gap-8">
+            {posts.map((post, i) => (
+                <div key={post.id} className="flex space-x-4">
+                    <div className="text-4xl font-bold text-gray-300">{String(i + 1).padStart(2, '0')}</div>
+                    <div className="flex-1">
+                        <div className="flex items-center space-x-2 mb-2">
+                            {post.author?.avatar_url ? (
+                                <img src={post.author.avatar_url} alt="" className="w-5 h-5 rounded-full object-cover" />
+                            ) : (
+                                <div className="w-5 h-5 rounded-full bg-gray-300"></div>
+                            )}
+                            <span className="text-xs font-medium">{post.author?.name}</span>
+                        </div>
+                        <Link href={`/post/${post.id}`}>
+                            <h4 className="font-bold text-base mb-1 hover:text-gray-600 cursor-pointer">
+                                {post.title}
+                            </h4>
+                        </Link>
+                        <p className="text-xs text-gray-500">{post.reading_time || 1} min read</p>
+                    </div>
+                </div>
+            ))}
+        </div>
+    );
+}
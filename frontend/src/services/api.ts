@@ -9,6 +9,10 @@ export interface Post {
     title: string;
     subtitle?: string;
     content: string;
+    // Rendered from content and sanitized by the server
+    content_html?: string;
+    excerpt?: string;
+    toc?: { level: number; id: string; text: string }[];
     author_id: string;
     author?: {
         id?: string;
@@ -18,12 +22,15 @@ export interface Post {
     created_at: string;
     published_at?: string;
     reading_time?: number;
+    word_count?: number;
     image_url?: string;
     cover_image?: string;
     tags?: string[];
     // Social
     claps_count?: number;
     is_bookmarked?: boolean;
+    // Sent back on update so concurrent edits are detected
+    version?: number;
 }
 
 export interface ProfileUpdate {
@@ -89,6 +96,25 @@ export const getPostsByTag = async (
     };
 };
 
+export type TrendingWindow = 'day' | 'week' | 'month';
+
+export const getTrending = async (
+    period: TrendingWindow = 'week',
+    tag?: string,
+    limit: number = 10
+): Promise<{ post: Post; score: number }[]> => {
+    const params = new URLSearchParams({ window: period, limit: limit.toString() });
+    if (tag) params.set('tag', tag);
+
+    const response = await api.get<ApiResponse<{ posts: { post: Post; score: number }[]; computed_at: string }>>(
+        `/api/v1/posts/trending?${params.toString()}`
+    );
+    if (!response.data.success) {
+        throw new Error(response.data.error?.message || 'Failed to fetch trending posts');
+    }
+    return response.data.data.posts;
+};
+
 export const createPost = async (title: string, content: string, tags?: string[], coverImage?: string): Promise<Post> => {
     const response = await api.post<ApiResponse<Post>>('/api/v1/posts', { 
         title, 
@@ -110,12 +136,18 @@ export const getPost = async (postId: string): Promise<Post> => {
     return response.data.data;
 };
 
-export const updatePost = async (postId: string, title: string, content: string, tags?: string[], coverImage?: string): Promise<Post> => {
+// A subtitle left undefined keeps the stored one
+export const updatePost = async (postId: string, version: number, title: string, content: string, tags?: string[], coverImage?: string, subtitle?: string): Promise<Post> => {
     const response = await api.put<ApiResponse<Post>>(`/api/v1/posts/${postId}`, { 
         title, 
+        subtitle,
         content,
         tags,
-        cover_image: coverImage
+        cover_image: coverImage,
+        version
+    }, {
+        // Let 409 through so the conflict message reaches the editor
+        validateStatus: (status) => status < 500
     });
     if (!response.data.success) {
         throw new Error(response.data.error?.message || 'Failed to update post');
@@ -2,8 +2,10 @@ package common
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +15,91 @@ type Config struct {
 	DatabaseConfig DatabaseConfig
 	AllowedOrigins []string
 	OTELEndpoint   string
+	Auth           AuthConfig
+	Mail           MailConfig
+	OAuthProviders []OAuthProviderConfig
+
+	// AppURL is the public address of the web app, used to build links in emails
+	AppURL string
+	// APIURL is the public address of the gateway, used for OAuth callbacks
+	APIURL string
+
+	// DeletedUserContent decides what happens to a deleted user's posts and
+	// comments: DeletedUserAnonymize keeps them under a placeholder name,
+	// DeletedUserCascade removes them
+	DeletedUserContent string
+
+	// TrendingInterval is how often the blog service recomputes trending
+	// post rankings, and TrendingWindows the periods they rank posts over
+	TrendingInterval time.Duration
+	TrendingWindows  []TrendingWindow
+}
+
+// TrendingWindow is a period that posts are ranked over. Engagement counts
+// half as much for every HalfLife it is old, so posts rise and fall
+// smoothly rather than dropping off at the end of the period.
+type TrendingWindow struct {
+	Name     string
+	Period   time.Duration
+	HalfLife time.Duration
+}
+
+const (
+	DeletedUserAnonymize = "anonymize"
+	DeletedUserCascade   = "cascade"
+)
+
+// UsernameRedirectPeriod is how long a changed handle keeps resolving to its
+// old owner. Nobody else can claim it until then, so links shared under the
+// old handle can't be taken over.
+const UsernameRedirectPeriod = 30 * 24 * time.Hour
+
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Signing keys: PEM files in KeysDir, rotated every KeyRotationInterval
+	// and kept for KeyRetention after they stop signing
+	KeysDir             string
+	KeyAlgorithm        string
+	KeyRotationInterval time.Duration
+	KeyRetention        time.Duration
+
+	// Argon2id cost for new password hashes. Memory is in KiB. Raising
+	// these upgrades existing hashes as users sign in.
+	PasswordMemory      int
+	PasswordIterations  int
+	PasswordParallelism int
+
+	// AccountDeletionGrace is how long a deleted account can still be
+	// restored by signing in before its data is erased
+	AccountDeletionGrace time.Duration
+}
+
+type MailConfig struct {
+	Driver   string // "smtp", "file" or "memory"
+	From     string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Dir      string // Where the file driver writes .eml files
+}
+
+// OAuthProviderConfig registers an OpenID Connect provider for social login
+type OAuthProviderConfig struct {
+	Name         string // Used in URLs, e.g. /api/v1/auth/oauth/google/start
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Issuers assumed for well-known providers when OAUTH_<NAME>_ISSUER is unset
+var knownIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+	"gitlab": "https://gitlab.com",
 }
 
 type DatabaseConfig struct {
@@ -25,6 +112,9 @@ type DatabaseConfig struct {
 }
 
 func LoadConfig() *Config {
+	appURL := strings.TrimRight(getEnv("APP_URL", "http://localhost:3000"), "/")
+	apiURL := strings.TrimRight(getEnv("API_URL", "http://localhost:8080"), "/")
+
 	return &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 		ServiceName: getEnv("SERVICE_NAME", "unknown-service"),
@@ -39,7 +129,78 @@ func LoadConfig() *Config {
 		},
 		AllowedOrigins: getEnvList("ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://127.0.0.1:3000"}),
 		OTELEndpoint:   getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+		Auth: AuthConfig{
+			AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+			KeysDir:             getEnv("JWT_KEYS_DIR", ""),
+			KeyAlgorithm:        getEnv("JWT_KEY_ALGORITHM", "EdDSA"),
+			KeyRotationInterval: getEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+			KeyRetention:        getEnvDuration("JWT_KEY_RETENTION", 24*time.Hour),
+
+			PasswordMemory:      getEnvInt("PASSWORD_ARGON2_MEMORY", 19*1024),
+			PasswordIterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 2),
+			PasswordParallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 1),
+
+			AccountDeletionGrace: getEnvDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:   getEnv("MAIL_DRIVER", "file"),
+			From:     getEnv("MAIL_FROM", "Minimum <no-reply@minimum.local>"),
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			Dir:      getEnv("MAIL_DIR", filepath.Join(os.TempDir(), "minimum-mail")),
+		},
+		OAuthProviders: loadOAuthProviders(apiURL),
+		AppURL:         appURL,
+		APIURL:         apiURL,
+
+		DeletedUserContent: getEnv("DELETED_USER_CONTENT", DeletedUserAnonymize),
+		TrendingInterval:   getEnvDuration("TRENDING_INTERVAL", 10*time.Minute),
+		TrendingWindows:    loadTrendingWindows(),
+	}
+}
+
+// loadTrendingWindows reads the day, week and month trending windows from
+// TRENDING_<NAME>_PERIOD and TRENDING_<NAME>_HALF_LIFE
+func loadTrendingWindows() []TrendingWindow {
+	windows := []TrendingWindow{
+		{Name: "day", Period: 24 * time.Hour, HalfLife: 6 * time.Hour},
+		{Name: "week", Period: 7 * 24 * time.Hour, HalfLife: 36 * time.Hour},
+		{Name: "month", Period: 30 * 24 * time.Hour, HalfLife: 7 * 24 * time.Hour},
+	}
+	for i, w := range windows {
+		prefix := "TRENDING_" + strings.ToUpper(w.Name) + "_"
+		windows[i].Period = getEnvDuration(prefix+"PERIOD", w.Period)
+		windows[i].HalfLife = getEnvDuration(prefix+"HALF_LIFE", w.HalfLife)
+	}
+	return windows
+}
+
+// loadOAuthProviders reads the providers named in OAUTH_PROVIDERS from
+// OAUTH_<NAME>_* variables. Providers without a client ID are skipped.
+func loadOAuthProviders(apiURL string) []OAuthProviderConfig {
+	var providers []OAuthProviderConfig
+	for _, name := range getEnvList("OAUTH_PROVIDERS", nil) {
+		name = strings.ToLower(name)
+		prefix := "OAUTH_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		p := OAuthProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", knownIssuers[name]),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", apiURL+"/api/v1/auth/oauth/"+name+"/callback"),
+			Scopes:       getEnvList(prefix+"SCOPES", nil),
+		}
+		if p.ClientID == "" || p.Issuer == "" {
+			continue
+		}
+		providers = append(providers, p)
 	}
+	return providers
 }
 
 func getEnv(key, fallback string) string {
@@ -58,6 +219,16 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+// getEnvDuration parses a Go duration string such as "15m" or "720h"
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
 // getEnvList parses a comma-separated environment variable into a string slice
 func getEnvList(key string, fallback []string) []string {
 	if value, ok := os.LookupEnv(key); ok {
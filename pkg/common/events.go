@@ -0,0 +1,8 @@
+package common
+
+// User lifecycle events published by the auth service
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
@@ -40,3 +40,13 @@ func RespondError(c *gin.Context, status int, code, message string) {
 		Error:   &APIError{Code: code, Message: message},
 	})
 }
+
+// RespondErrorData is RespondError with data the client needs to recover,
+// such as the current version after a conflict
+func RespondErrorData(c *gin.Context, status int, code, message string, data interface{}) {
+	c.JSON(status, APIResponse{
+		Success: false,
+		Data:    data,
+		Error:   &APIError{Code: code, Message: message},
+	})
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Posts and profiles carry a version that every update bumps. Writers send
+// the version they last read, and a stale one is rejected with
+// VersionConflictError.
+
+const versionConflictReason = "VERSION_MISMATCH"
+
+// VersionConflictError is a FailedPrecondition error carrying the current
+// version, so clients can refetch and retry
+func VersionConflictError(resource string, current int64) error {
+	st := status.New(codes.FailedPrecondition, resource+" was changed since it was read")
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   versionConflictReason,
+		Metadata: map[string]string{"current_version": strconv.FormatInt(current, 10)},
+	})
+	if err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// ConflictVersion returns the current version carried by an error from
+// VersionConflictError
+func ConflictVersion(err error) (int64, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == versionConflictReason {
+			v, err := strconv.ParseInt(info.Metadata["current_version"], 10, 64)
+			return v, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,181 @@
+// Package diff computes line and word diffs of text with Myers' O(ND)
+// algorithm
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Chunk is a run of text that both sides share, or that only one side has.
+// Joining the Equal and Delete chunks of a diff gives the old text back, and
+// joining the Equal and Insert chunks gives the new text.
+type Chunk struct {
+	Op   Op
+	Text string
+}
+
+// maxEdits bounds the work done on very different inputs. Past it, the
+// differing middle is reported as one deletion and one insertion.
+const maxEdits = 1000
+
+// Lines diffs a and b line by line
+func Lines(a, b string) []Chunk {
+	return diff(splitLines(a), splitLines(b))
+}
+
+// Words diffs a and b word by word. Runs of whitespace count as words, so
+// changes in spacing show up too.
+func Words(a, b string) []Chunk {
+	return diff(splitWords(a), splitWords(b))
+}
+
+// splitLines splits s after each newline
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitWords splits s into alternating runs of whitespace and other
+// characters
+func splitWords(s string) []string {
+	var words []string
+	start, space := 0, false
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != space {
+			words = append(words, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+func diff(a, b []string) []Chunk {
+	// Common ends are cheap to strip and often most of the text
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out chunks
+	out.add(Equal, a[:prefix]...)
+	out.edits(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	out.add(Equal, a[len(a)-suffix:]...)
+	return out
+}
+
+type chunks []Chunk
+
+// add appends tokens, merging them into the last chunk when it has the
+// same op
+func (c *chunks) add(op Op, tokens ...string) {
+	if len(tokens) == 0 {
+		return
+	}
+	text := strings.Join(tokens, "")
+	if n := len(*c); n > 0 && (*c)[n-1].Op == op {
+		(*c)[n-1].Text += text
+		return
+	}
+	*c = append(*c, Chunk{Op: op, Text: text})
+}
+
+// edits appends a shortest edit script from a to b
+func (c *chunks) edits(a, b []string) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		c.add(Delete, a...)
+		c.add(Insert, b...)
+		return
+	}
+
+	// v[k] is the furthest x reached on diagonal k = x - y. trace keeps v
+	// as it was before each round d, limited to the diagonals -d..d, for
+	// walking the path back afterwards.
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+	found := false
+	for d := 0; d <= min(n+m, maxEdits) && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		c.add(Delete, a...)
+		c.add(Insert, b...)
+		return
+	}
+
+	// Walk back from the end, collecting the script in reverse
+	var script []Chunk
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			script = append(script, Chunk{Equal, a[x]})
+		}
+		if x == prevX {
+			y--
+			script = append(script, Chunk{Insert, b[y]})
+		} else {
+			x--
+			script = append(script, Chunk{Delete, a[x]})
+		}
+	}
+	for x > 0 {
+		x--
+		script = append(script, Chunk{Equal, a[x]})
+	}
+
+	for i := len(script) - 1; i >= 0; i-- {
+		c.add(script[i].Op, script[i].Text)
+	}
+}
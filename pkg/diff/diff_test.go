@@ -0,0 +1,65 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\n"
+	b := "one\n2\nthree\nfour\nfive\n"
+
+	want := []Chunk{
+		{Equal, "one\n"},
+		{Delete, "two\n"},
+		{Insert, "2\n"},
+		{Equal, "three\nfour\n"},
+		{Insert, "five\n"},
+	}
+	if got := Lines(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestWords(t *testing.T) {
+	want := []Chunk{
+		{Equal, "the "},
+		{Delete, "quick"},
+		{Insert, "slow"},
+		{Equal, " brown fox"},
+		{Insert, " jumps"},
+	}
+	if got := Words("the quick brown fox", "the slow brown fox jumps"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %q, want %q", got, want)
+	}
+}
+
+// Whatever the diff, each side must be recoverable from it
+func TestChunksRebuildBothSides(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", ""},
+		{"", "new\ntext"},
+		{"old\ntext", ""},
+		{"a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc"},
+		{"same", "same"},
+		{"no newline at end", "no newline at end\n"},
+		{strings.Repeat("x\n", 1500), strings.Repeat("y\n", 1500)}, // past maxEdits
+	}
+	for _, tt := range tests {
+		for name, chunks := range map[string][]Chunk{"Lines": Lines(tt.a, tt.b), "Words": Words(tt.a, tt.b)} {
+			var before, after strings.Builder
+			for _, c := range chunks {
+				if c.Op != Insert {
+					before.WriteString(c.Text)
+				}
+				if c.Op != Delete {
+					after.WriteString(c.Text)
+				}
+			}
+			if before.String() != tt.a || after.String() != tt.b {
+				t.Errorf("%s(%q, %q) = %q, does not rebuild both sides", name, tt.a, tt.b, chunks)
+			}
+		}
+	}
+}
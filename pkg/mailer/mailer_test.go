@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(Config{Driver: "file", Dir: dir, From: "Minimum <no-reply@example.com>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Send(context.Background(), Message{To: "alice@example.com", Subject: "Réinitialiser", Text: "hello"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("wrote %d files, want 1", len(entries))
+	}
+	data, _ := os.ReadFile(dir + "/" + entries[0].Name())
+	for _, want := range []string{"To: alice@example.com\r\n", "Subject: =?utf-8?q?R=C3=A9initialiser?=\r\n", "\r\n\r\nhello"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("message missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestMemoryMailer(t *testing.T) {
+	m := &MemoryMailer{}
+	m.Send(context.Background(), Message{To: "a@example.com"})
+	m.Send(context.Background(), Message{To: "b@example.com"})
+
+	got := m.Messages()
+	if len(got) != 2 || got[1].To != "b@example.com" {
+		t.Errorf("Messages() = %+v", got)
+	}
+}
+
+func TestNewUnknownDriver(t *testing.T) {
+	if _, err := New(Config{Driver: "pigeon"}); err == nil {
+		t.Error("New() accepted an unknown driver")
+	}
+}
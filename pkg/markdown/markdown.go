@@ -0,0 +1,163 @@
+// Package markdown renders post content, written in CommonMark with GitHub's
+// tables, strikethrough, task lists and autolinks, to sanitized HTML
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Version identifies the rendering pipeline. Bump it whenever Render's
+// output changes, so stored posts are rendered again.
+const Version = 2
+
+const (
+	// Excerpts are cut at a word boundary near this many characters
+	excerptLength = 200
+
+	// Average adult reading speed, for estimating reading time
+	wordsPerMinute = 230
+)
+
+// Heading is an entry in a document's table of contents
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+type Document struct {
+	HTML        string
+	Excerpt     string
+	TOC         []Heading
+	WordCount   int
+	ReadingTime int // In minutes, at least 1
+}
+
+// Raw HTML is let through goldmark and left to the sanitizer, which keeps
+// only the allowlisted subset
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render turns Markdown source into sanitized HTML, with a plain text
+// excerpt and the document's headings
+func Render(source string) (*Document, error) {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	out := &Document{
+		HTML: policy.Sanitize(buf.String()),
+		TOC:  []Heading{},
+	}
+
+	var excerpt strings.Builder
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			id, _ := n.AttributeString("id")
+			idBytes, _ := id.([]byte)
+			out.TOC = append(out.TOC, Heading{Level: n.Level, ID: string(idBytes), Text: plainText(n, src)})
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph:
+			if excerpt.Len() < excerptLength {
+				if excerpt.Len() > 0 {
+					excerpt.WriteByte(' ')
+				}
+				excerpt.WriteString(plainText(n, src))
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Excerpt = truncate(excerpt.String(), excerptLength)
+
+	// Every word counts, including those in headings and code
+	out.WordCount = len(strings.Fields(plainText(doc, src)))
+	out.ReadingTime = max((out.WordCount+wordsPerMinute-1)/wordsPerMinute, 1)
+
+	return out, nil
+}
+
+// plainText returns the text inside n, without markup
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			// Keep the last word of a block apart from the next one
+			if n.Type() == ast.TypeBlock {
+				b.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(src))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				b.WriteByte(' ')
+				segment := lines.At(i)
+				b.Write(segment.Value(src))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// truncate shortens s to at most limit characters, cutting at a space when
+// there is one
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)[:limit]
+	cut := string(runes)
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
@@ -0,0 +1,88 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderSanitizes(t *testing.T) {
+	source := "Hello <script>alert(1)</script>**world**\n\n" +
+		"[click](javascript:alert(1)) <img src=x onerror=alert(1)>\n"
+
+	doc, err := Render(source)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, bad := range []string{"<script", "javascript:", "onerror"} {
+		if strings.Contains(doc.HTML, bad) {
+			t.Errorf("Render() HTML contains %q: %s", bad, doc.HTML)
+		}
+	}
+	if !strings.Contains(doc.HTML, "<strong>world</strong>") {
+		t.Errorf("Render() HTML = %s, want emphasis kept", doc.HTML)
+	}
+}
+
+func TestRenderGFM(t *testing.T) {
+	source := "| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfmt.Println(\"hi\")\n```\n\n- [x] done\n"
+
+	doc, err := Render(source)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"<table>", "<td>1</td>", `<code class="language-go">`, `type="checkbox"`} {
+		if !strings.Contains(doc.HTML, want) {
+			t.Errorf("Render() HTML = %s, want it to contain %q", doc.HTML, want)
+		}
+	}
+}
+
+func TestRenderTOCAndExcerpt(t *testing.T) {
+	source := "# Getting started\n\nFirst *steps* with\nthe tool.\n\n## Install it\n\nRun the installer.\n"
+
+	doc, err := Render(source)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := []Heading{
+		{Level: 1, ID: "getting-started", Text: "Getting started"},
+		{Level: 2, ID: "install-it", Text: "Install it"},
+	}
+	if !reflect.DeepEqual(doc.TOC, want) {
+		t.Errorf("Render() TOC = %+v, want %+v", doc.TOC, want)
+	}
+	if !strings.Contains(doc.HTML, `<h2 id="install-it">`) {
+		t.Errorf("Render() HTML = %s, want heading anchors", doc.HTML)
+	}
+	if doc.Excerpt != "First steps with the tool. Run the installer." {
+		t.Errorf("Render() excerpt = %q", doc.Excerpt)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q, want unchanged", got)
+	}
+	if got := truncate("one two three four", 12); got != "one two…" {
+		t.Errorf("truncate() = %q, want cut at a space", got)
+	}
+}
+
+func TestRenderWordCount(t *testing.T) {
+	doc, err := Render("# One two\n\nthree *four*\n\n```\nfive six\n```\n")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if doc.WordCount != 6 || doc.ReadingTime != 1 {
+		t.Errorf("Render() word count = %d, reading time = %d, want 6, 1", doc.WordCount, doc.ReadingTime)
+	}
+
+	doc, err = Render(strings.Repeat("word ", 500))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if doc.ReadingTime != 3 {
+		t.Errorf("Render() reading time = %d for 500 words, want 3", doc.ReadingTime)
+	}
+}
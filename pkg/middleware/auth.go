@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -10,8 +11,15 @@ import (
 	authpb "project/pkg/proto/auth"
 )
 
-// AuthMiddleware validates JWT tokens using the auth service
-func AuthMiddleware(authClient authpb.AuthServiceClient) gin.HandlerFunc {
+// Context keys set by the auth middlewares
+const (
+	userIDKey    = "userId"
+	sessionIDKey = "sessionId"
+	identityKey  = "identity"
+)
+
+// AuthMiddleware verifies bearer tokens and rejects unauthenticated requests
+func AuthMiddleware(verifier *TokenVerifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -40,11 +48,18 @@ func AuthMiddleware(authClient authpb.AuthServiceClient) gin.HandlerFunc {
 		}
 		token := parts[1]
 
-		// Validate token with auth service
-		resp, err := authClient.Validate(c.Request.Context(), &authpb.ValidateRequest{
-			Token: token,
-		})
+		identity, err := verifier.Verify(c.Request.Context(), token)
 		if err != nil {
+			if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrSessionRevoked) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"success": false,
+					"error": gin.H{
+						"code":    "INVALID_TOKEN",
+						"message": "Token is invalid or expired",
+					},
+				})
+				return
+			}
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 				"success": false,
 				"error": gin.H{
@@ -55,25 +70,17 @@ func AuthMiddleware(authClient authpb.AuthServiceClient) gin.HandlerFunc {
 			return
 		}
 
-		if !resp.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "INVALID_TOKEN",
-					"message": "Token is invalid or expired",
-				},
-			})
+		// Expose verified claims to downstream handlers
+		setIdentity(c, identity)
+		if !authorize(c, identity) {
 			return
 		}
-
-		// Set user ID in context for downstream handlers
-		c.Set("userId", resp.UserId)
 		c.Next()
 	}
 }
 
-// OptionalAuthMiddleware extracts user ID if token present, but doesn't require it
-func OptionalAuthMiddleware(authClient authpb.AuthServiceClient) gin.HandlerFunc {
+// OptionalAuthMiddleware extracts the caller if a token is present, but doesn't require it
+func OptionalAuthMiddleware(verifier *TokenVerifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -88,20 +95,26 @@ func OptionalAuthMiddleware(authClient authpb.AuthServiceClient) gin.HandlerFunc
 		}
 		token := parts[1]
 
-		resp, err := authClient.Validate(c.Request.Context(), &authpb.ValidateRequest{
-			Token: token,
-		})
-		if err == nil && resp.Valid {
-			c.Set("userId", resp.UserId)
+		if identity, err := verifier.Verify(c.Request.Context(), token); err == nil {
+			setIdentity(c, identity)
+			if !authorize(c, identity) {
+				return
+			}
 		}
 
 		c.Next()
 	}
 }
 
+func setIdentity(c *gin.Context, identity *Identity) {
+	c.Set(userIDKey, identity.UserID)
+	c.Set(sessionIDKey, identity.SessionID)
+	c.Set(identityKey, identity)
+}
+
 // GetUserID retrieves the user ID from context, returns empty string if not authenticated
 func GetUserID(c *gin.Context) string {
-	if userId, exists := c.Get("userId"); exists {
+	if userId, exists := c.Get(userIDKey); exists {
 		if id, ok := userId.(string); ok {
 			return id
 		}
@@ -109,6 +122,34 @@ func GetUserID(c *gin.Context) string {
 	return ""
 }
 
+// GetSessionID retrieves the session ID of the verified token, if any
+func GetSessionID(c *gin.Context) string {
+	if sessionId, exists := c.Get(sessionIDKey); exists {
+		if id, ok := sessionId.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// GetPermissions retrieves the permissions granted by the caller's roles
+func GetPermissions(c *gin.Context) []string {
+	if identity := GetIdentity(c); identity != nil {
+		return identity.Permissions
+	}
+	return nil
+}
+
+// GetIdentity retrieves all verified claims, or nil if not authenticated
+func GetIdentity(c *gin.Context) *Identity {
+	if identity, exists := c.Get(identityKey); exists {
+		if id, ok := identity.(*Identity); ok {
+			return id
+		}
+	}
+	return nil
+}
+
 // NewAuthClient creates a new auth service client
 func NewAuthClient(conn *grpc.ClientConn) authpb.AuthServiceClient {
 	return authpb.NewAuthServiceClient(conn)
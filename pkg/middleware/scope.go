@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys for the access requirements of a route
+const (
+	requiredScopeKey = "requiredScope"
+	sessionOnlyKey   = "sessionOnly"
+)
+
+// RequireScopes sets the scope a personal access token needs for the routes
+// it guards: readScope for GET and HEAD, writeScope for everything else.
+// It can be applied to a whole group ahead of the auth middlewares, which
+// enforce it once the caller is known.
+func RequireScopes(readScope, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := writeScope
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = readScope
+		}
+		c.Set(requiredScopeKey, scope)
+
+		if identity := GetIdentity(c); identity != nil && !authorize(c, identity) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope is RequireScopes for routes that need scope whatever their
+// method, such as reads that are made with POST
+func RequireScope(scope string) gin.HandlerFunc {
+	return RequireScopes(scope, scope)
+}
+
+// SessionOnly rejects personal access tokens, for routes such as token and
+// 2FA management that must only be reachable from an interactive login
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(sessionOnlyKey, true)
+
+		if identity := GetIdentity(c); identity != nil && !authorize(c, identity) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission rejects callers whose roles don't grant permission. It
+// must run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := GetIdentity(c)
+		if identity == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "UNAUTHORIZED",
+					"message": "Authorization header required",
+				},
+			})
+			return
+		}
+		if !identity.HasPermission(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "INSUFFICIENT_PERMISSIONS",
+					"message": "Missing the " + permission + " permission",
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorize checks the caller against the route's requirements, aborting
+// with 403 if they are not met
+func authorize(c *gin.Context, identity *Identity) bool {
+	if !identity.IsAccessToken() {
+		return true
+	}
+
+	if c.GetBool(sessionOnlyKey) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "SESSION_REQUIRED",
+				"message": "This endpoint cannot be used with a personal access token",
+			},
+		})
+		return false
+	}
+
+	if scope := c.GetString(requiredScopeKey); scope != "" && !identity.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "INSUFFICIENT_SCOPE",
+				"message": "Token is missing the " + scope + " scope",
+			},
+		})
+		return false
+	}
+
+	return true
+}
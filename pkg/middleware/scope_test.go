@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"project/pkg/tokens"
+)
+
+func TestRequireScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ring, _ := tokens.NewKeyring("", tokens.AlgEdDSA)
+	ring.Generate()
+	v := NewTokenVerifier(&fakeAuthClient{keyring: ring}, zap.NewNop(), time.Minute)
+
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	posts := r.Group("/posts")
+	posts.Use(RequireScopes(tokens.ScopeRead, tokens.ScopePostsWrite))
+	posts.GET("", OptionalAuthMiddleware(v), ok)
+	posts.POST("", AuthMiddleware(v), ok)
+	notifications := r.Group("/notifications")
+	notifications.Use(RequireScope(tokens.ScopeRead))
+	notifications.POST("/read", AuthMiddleware(v), ok)
+	r.GET("/tokens", AuthMiddleware(v), SessionOnly(), ok)
+
+	session := signTestToken(t, ring, "session-1")
+	pat := tokens.PersonalAccessTokenPrefix + "secret" // granted read only
+
+	tests := []struct {
+		method, path, token string
+		want                int
+	}{
+		{http.MethodGet, "/posts", pat, http.StatusOK},
+		{http.MethodPost, "/posts", pat, http.StatusForbidden},
+		{http.MethodPost, "/posts", session, http.StatusOK},
+		{http.MethodPost, "/notifications/read", pat, http.StatusOK},
+		{http.MethodGet, "/tokens", pat, http.StatusForbidden},
+		{http.MethodGet, "/tokens", session, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s with %.5s token = %d, want %d", tt.method, tt.path, tt.token, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ring, _ := tokens.NewKeyring("", tokens.AlgEdDSA)
+	ring.Generate()
+	v := NewTokenVerifier(&fakeAuthClient{keyring: ring}, zap.NewNop(), time.Minute)
+	v.refreshKeys(context.Background())
+	v.syncRevocations(context.Background())
+
+	r := gin.New()
+	r.DELETE("/comments", AuthMiddleware(v), RequirePermission(tokens.PermCommentsDeleteAny), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	sign := func(roles ...string) string {
+		token, err := ring.Sign(&tokens.Claims{
+			UserID:    "user-1",
+			SessionID: "session-1",
+			Roles:     roles,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    tokens.Issuer,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"plain user", sign(), http.StatusForbidden},
+		{"moderator", sign(tokens.RoleModerator), http.StatusOK},
+		{"admin", sign(tokens.RoleAdmin), http.StatusOK},
+		{"unknown role", sign("superuser"), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodDelete, "/comments", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	authpb "project/pkg/proto/auth"
+	"project/pkg/tokens"
+)
+
+var (
+	ErrInvalidToken   = errors.New("token is invalid or expired")
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// Identity is the verified caller behind a token
+type Identity struct {
+	UserID    string
+	SessionID string
+	Email     string
+	Name      string
+
+	// Set for personal access tokens, which are limited to Scopes.
+	// Session tokens from an interactive login carry every permission.
+	AccessTokenID string
+	Scopes        []string
+
+	// Granted by the user's roles, e.g. moderators may delete any comment
+	Roles       []string
+	Permissions []string
+}
+
+// IsAccessToken reports whether the caller used a personal access token
+func (i *Identity) IsAccessToken() bool {
+	return i.AccessTokenID != ""
+}
+
+// HasScope reports whether the caller may act within scope
+func (i *Identity) HasScope(scope string) bool {
+	if !i.IsAccessToken() {
+		return true
+	}
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether the caller's roles grant permission
+func (i *Identity) HasPermission(permission string) bool {
+	return slices.Contains(i.Permissions, permission)
+}
+
+// TokenVerifier checks access tokens locally against the auth service's
+// public keys, and keeps a short-lived list of revoked sessions polled from
+// the auth service. When that list is stale (auth unreachable) it falls
+// back to asking the auth service directly, so revocation is never ignored.
+type TokenVerifier struct {
+	authClient   authpb.AuthServiceClient
+	logger       *zap.Logger
+	keys         *tokens.KeySet
+	pollInterval time.Duration
+
+	mu         sync.RWMutex
+	revoked    map[string]time.Time // session ID -> when it can be forgotten
+	lastSync   time.Time
+	serverTime int64
+
+	keyRefreshMu   sync.Mutex
+	lastKeyRefresh time.Time
+}
+
+// NewTokenVerifier creates a verifier. Call Start to begin syncing keys
+// and revocations; until the first sync succeeds every token is checked
+// remotely.
+func NewTokenVerifier(authClient authpb.AuthServiceClient, logger *zap.Logger, pollInterval time.Duration) *TokenVerifier {
+	return &TokenVerifier{
+		authClient:   authClient,
+		logger:       logger,
+		keys:         tokens.NewKeySet(),
+		pollInterval: pollInterval,
+		revoked:      make(map[string]time.Time),
+	}
+}
+
+// Start syncs keys and revocations until ctx is cancelled
+func (v *TokenVerifier) Start(ctx context.Context) {
+	v.refreshKeys(ctx)
+	v.syncRevocations(ctx)
+
+	revocationTicker := time.NewTicker(v.pollInterval)
+	keyTicker := time.NewTicker(5 * time.Minute)
+	defer revocationTicker.Stop()
+	defer keyTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-revocationTicker.C:
+			v.syncRevocations(ctx)
+		case <-keyTicker.C:
+			v.refreshKeys(ctx)
+		}
+	}
+}
+
+// Verify authenticates a bearer token
+func (v *TokenVerifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	// Personal access tokens are opaque and can only be checked by auth
+	if tokens.IsPersonalAccessToken(token) || !v.fresh() {
+		return v.verifyRemote(ctx, token)
+	}
+
+	claims, err := tokens.Parse(token, v.keys.Keyfunc)
+	if errors.Is(err, tokens.ErrUnknownKey) && v.refreshKeysForUnknownKid(ctx, token) {
+		// The auth service rotated keys since our last fetch
+		claims, err = tokens.Parse(token, v.keys.Keyfunc)
+	}
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.SessionID == "" || claims.Type != "" {
+		return nil, ErrInvalidToken
+	}
+
+	v.mu.RLock()
+	_, revoked := v.revoked[claims.SessionID]
+	v.mu.RUnlock()
+	if revoked {
+		return nil, ErrSessionRevoked
+	}
+
+	return &Identity{
+		UserID:      claims.UserID,
+		SessionID:   claims.SessionID,
+		Email:       claims.Email,
+		Name:        claims.Name,
+		Roles:       claims.Roles,
+		Permissions: tokens.PermissionsFor(claims.Roles),
+	}, nil
+}
+
+// fresh reports whether local verification can be trusted right now
+func (v *TokenVerifier) fresh() bool {
+	if v.keys.Len() == 0 {
+		return false
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Since(v.lastSync) < 3*v.pollInterval
+}
+
+func (v *TokenVerifier) verifyRemote(ctx context.Context, token string) (*Identity, error) {
+	resp, err := v.authClient.Validate(ctx, &authpb.ValidateRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Valid {
+		return nil, ErrInvalidToken
+	}
+	return &Identity{
+		UserID:        resp.UserId,
+		SessionID:     resp.SessionId,
+		AccessTokenID: resp.AccessTokenId,
+		Scopes:        resp.Scopes,
+		Roles:         resp.Roles,
+		Permissions:   resp.Permissions,
+	}, nil
+}
+
+// PublicKeys fetches the current signing keys from the auth service and
+// refreshes the local key set with them
+func (v *TokenVerifier) PublicKeys(ctx context.Context) ([]tokens.JWK, error) {
+	resp, err := v.authClient.GetJWKS(ctx, &authpb.GetJWKSRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := make([]tokens.JWK, 0, len(resp.Keys))
+	for _, k := range resp.Keys {
+		jwks = append(jwks, tokens.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Alg: k.Alg,
+			Use: k.Use,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+		})
+	}
+	if err := v.keys.Replace(jwks); err != nil {
+		v.logger.Warn("skipped unparseable signing key", zap.Error(err))
+	}
+	return jwks, nil
+}
+
+func (v *TokenVerifier) refreshKeys(ctx context.Context) bool {
+	if _, err := v.PublicKeys(ctx); err != nil {
+		v.logger.Warn("failed to fetch signing keys", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// refreshKeysForUnknownKid refetches keys when a token names a kid we have
+// not seen, at most once every few seconds so bogus tokens cannot turn the
+// gateway into an amplifier against the auth service
+func (v *TokenVerifier) refreshKeysForUnknownKid(ctx context.Context, token string) bool {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return false
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	if kid == "" {
+		return false
+	}
+
+	v.keyRefreshMu.Lock()
+	defer v.keyRefreshMu.Unlock()
+	if v.keys.Has(kid) {
+		return true
+	}
+	if time.Since(v.lastKeyRefresh) < 10*time.Second {
+		return false
+	}
+	v.lastKeyRefresh = time.Now()
+	return v.refreshKeys(ctx) && v.keys.Has(kid)
+}
+
+func (v *TokenVerifier) syncRevocations(ctx context.Context) {
+	v.mu.RLock()
+	since := v.serverTime
+	v.mu.RUnlock()
+	if since > 0 {
+		// Overlap polls slightly so revocations committed mid-poll are not missed
+		since -= 5
+	}
+
+	resp, err := v.authClient.ListRevokedSessions(ctx, &authpb.ListRevokedSessionsRequest{Since: since})
+	if err != nil {
+		v.logger.Warn("failed to sync revoked sessions", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, s := range resp.Sessions {
+		v.revoked[s.SessionId] = time.Unix(s.ExpiresAt, 0)
+	}
+	for id, forgetAt := range v.revoked {
+		if now.After(forgetAt) {
+			delete(v.revoked, id)
+		}
+	}
+	v.serverTime = resp.ServerTime
+	v.lastSync = now
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	authpb "project/pkg/proto/auth"
+	"project/pkg/tokens"
+)
+
+// fakeAuthClient serves keys and revocations from memory
+type fakeAuthClient struct {
+	authpb.AuthServiceClient
+	keyring       *tokens.Keyring
+	revoked       []*authpb.RevokedSession
+	validateCalls int
+}
+
+func (f *fakeAuthClient) GetJWKS(ctx context.Context, in *authpb.GetJWKSRequest, opts ...grpc.CallOption) (*authpb.GetJWKSResponse, error) {
+	var keys []*authpb.JWK
+	for _, k := range f.keyring.JWKS() {
+		keys = append(keys, &authpb.JWK{Kty: k.Kty, Kid: k.Kid, Alg: k.Alg, Use: k.Use, Crv: k.Crv, X: k.X})
+	}
+	return &authpb.GetJWKSResponse{Keys: keys}, nil
+}
+
+func (f *fakeAuthClient) ListRevokedSessions(ctx context.Context, in *authpb.ListRevokedSessionsRequest, opts ...grpc.CallOption) (*authpb.ListRevokedSessionsResponse, error) {
+	return &authpb.ListRevokedSessionsResponse{Sessions: f.revoked, ServerTime: time.Now().Unix()}, nil
+}
+
+func (f *fakeAuthClient) Validate(ctx context.Context, in *authpb.ValidateRequest, opts ...grpc.CallOption) (*authpb.ValidateResponse, error) {
+	f.validateCalls++
+	if tokens.IsPersonalAccessToken(in.Token) {
+		return &authpb.ValidateResponse{Valid: true, UserId: "pat-user", AccessTokenId: "pat-1", Scopes: []string{tokens.ScopeRead}}, nil
+	}
+	return &authpb.ValidateResponse{Valid: true, UserId: "remote-user", SessionId: "remote-session"}, nil
+}
+
+func signTestToken(t *testing.T, ring *tokens.Keyring, sessionID string) string {
+	t.Helper()
+	now := time.Now()
+	token, err := ring.Sign(&tokens.Claims{
+		UserID:    "user-1",
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokens.Issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestTokenVerifier(t *testing.T) {
+	ring, _ := tokens.NewKeyring("", tokens.AlgEdDSA)
+	ring.Generate()
+	client := &fakeAuthClient{
+		keyring: ring,
+		revoked: []*authpb.RevokedSession{{SessionId: "revoked", ExpiresAt: time.Now().Add(time.Minute).Unix()}},
+	}
+	v := NewTokenVerifier(client, zap.NewNop(), time.Minute)
+	ctx := context.Background()
+
+	// Before the first sync tokens are checked remotely
+	if id, err := v.Verify(ctx, signTestToken(t, ring, "active")); err != nil || id.UserID != "remote-user" {
+		t.Fatalf("Verify() before sync = %+v, %v; want remote validation", id, err)
+	}
+
+	v.refreshKeys(ctx)
+	v.syncRevocations(ctx)
+
+	id, err := v.Verify(ctx, signTestToken(t, ring, "active"))
+	if err != nil || id.UserID != "user-1" || id.SessionID != "active" {
+		t.Errorf("Verify() = %+v, %v; want local identity", id, err)
+	}
+	if _, err := v.Verify(ctx, signTestToken(t, ring, "revoked")); err != ErrSessionRevoked {
+		t.Errorf("Verify() revoked session error = %v, want ErrSessionRevoked", err)
+	}
+	if _, err := v.Verify(ctx, "not-a-token"); err != ErrInvalidToken {
+		t.Errorf("Verify() garbage error = %v, want ErrInvalidToken", err)
+	}
+
+	// A key rotated after the last fetch is picked up on demand
+	ring.Generate()
+	if _, err := v.Verify(ctx, signTestToken(t, ring, "active")); err != nil {
+		t.Errorf("Verify() with rotated key error = %v", err)
+	}
+
+	if client.validateCalls != 1 {
+		t.Errorf("remote Validate called %d times, want 1", client.validateCalls)
+	}
+}
@@ -0,0 +1,88 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"project/pkg/oidc"
+	"project/pkg/oidc/oidctest"
+)
+
+// authorize follows the authorization URL to the mock provider and returns
+// the code and state it redirects back with
+func authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want 302", resp.StatusCode)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "42", Email: "ada@example.com", EmailVerified: true, Name: "Ada"})
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "mock",
+		Issuer:       server.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app.test/callback",
+	}, nil)
+	ctx := context.Background()
+
+	verifier, _ := oidc.GenerateVerifier()
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	code, state := authorize(t, authURL)
+	if state != "state-1" {
+		t.Errorf("state = %q, want state-1", state)
+	}
+
+	// The code is bound to the PKCE verifier
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Error("Exchange() with the wrong verifier succeeded")
+	}
+
+	code, _ = authorize(t, authURL)
+	raw, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, raw, "other-nonce"); !errors.Is(err, oidc.ErrNonceMismatch) {
+		t.Errorf("VerifyIDToken() with wrong nonce error = %v, want ErrNonceMismatch", err)
+	}
+
+	idToken, err := provider.VerifyIDToken(ctx, raw, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if idToken.Subject != "42" || idToken.Email != "ada@example.com" || !idToken.EmailVerified || idToken.Name != "Ada" {
+		t.Errorf("VerifyIDToken() = %+v", idToken)
+	}
+
+	// A token minted for another client must not be accepted
+	other := oidc.NewProvider(oidc.Config{Name: "mock", Issuer: server.Issuer(), ClientID: "other"}, nil)
+	if _, err := other.VerifyIDToken(ctx, raw, "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken() for another audience error = %v, want ErrInvalidIDToken", err)
+	}
+}
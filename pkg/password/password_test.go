@@ -0,0 +1,60 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var cheap = Argon2Params{Memory: 64, Iterations: 1}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	h := NewArgon2id(cheap)
+
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("Hash() = %q, want a PHC argon2id string", hash)
+	}
+
+	needsRehash, err := h.Verify("correct horse", hash)
+	if err != nil || needsRehash {
+		t.Errorf("Verify() = %v, %v, want false, nil", needsRehash, err)
+	}
+	if _, err := h.Verify("battery staple", hash); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() with the wrong password error = %v, want ErrMismatch", err)
+	}
+}
+
+func TestVerifyFlagsOutdatedHashes(t *testing.T) {
+	h := NewArgon2id(cheap)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if needsRehash, err := h.Verify("secret", string(legacy)); err != nil || !needsRehash {
+		t.Errorf("Verify(bcrypt) = %v, %v, want true, nil", needsRehash, err)
+	}
+	if _, err := h.Verify("wrong", string(legacy)); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify(bcrypt) with the wrong password error = %v, want ErrMismatch", err)
+	}
+
+	// Raising the cost makes hashes made before it outdated
+	weak, err := h.Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := NewArgon2id(Argon2Params{Memory: 128, Iterations: 1})
+	if needsRehash, err := stronger.Verify("secret", weak); err != nil || !needsRehash {
+		t.Errorf("Verify() after raising memory = %v, %v, want true, nil", needsRehash, err)
+	}
+
+	if _, err := h.Verify("secret", ""); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Verify(\"\") error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -9,6 +9,7 @@ package blog
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -325,6 +326,7 @@ type DeleteCommentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CommentId     string                 `protobuf:"bytes,1,opt,name=comment_id,json=commentId,proto3" json:"comment_id,omitempty"`
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization check
+	Permissions   []string               `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"`     // Caller's role permissions, for moderator overrides
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -373,6 +375,13 @@ func (x *DeleteCommentRequest) GetUserId() string {
 	return ""
 }
 
+func (x *DeleteCommentRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
 type DeleteCommentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -545,6 +554,16 @@ type Post struct {
 	Tags          []string               `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
 	ClapsCount    int32                  `protobuf:"varint,9,opt,name=claps_count,json=clapsCount,proto3" json:"claps_count,omitempty"`
 	IsBookmarked  bool                   `protobuf:"varint,10,opt,name=is_bookmarked,json=isBookmarked,proto3" json:"is_bookmarked,omitempty"`
+	Status        string                 `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"` // "draft", "scheduled", "published" or "archived"
+	PublishedAt   string                 `protobuf:"bytes,12,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	ScheduledAt   string                 `protobuf:"bytes,13,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`  // Set while the post is waiting to be published
+	Version       int64                  `protobuf:"varint,14,opt,name=version,proto3" json:"version,omitempty"`                            // Bumped by every change; send it back with updates
+	ContentHtml   string                 `protobuf:"bytes,15,opt,name=content_html,json=contentHtml,proto3" json:"content_html,omitempty"`  // content rendered from Markdown and sanitized
+	Excerpt       string                 `protobuf:"bytes,16,opt,name=excerpt,proto3" json:"excerpt,omitempty"`                             // Plain text from the start of the post
+	Toc           []*TocEntry            `protobuf:"bytes,17,rep,name=toc,proto3" json:"toc,omitempty"`                                     // Headings of content_html, in order
+	ReadingTime   int32                  `protobuf:"varint,18,opt,name=reading_time,json=readingTime,proto3" json:"reading_time,omitempty"` // Minutes
+	WordCount     int32                  `protobuf:"varint,19,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	Subtitle      string                 `protobuf:"bytes,20,opt,name=subtitle,proto3" json:"subtitle,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -649,6 +668,136 @@ func (x *Post) GetIsBookmarked() bool {
 	return false
 }
 
+func (x *Post) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Post) GetPublishedAt() string {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return ""
+}
+
+func (x *Post) GetScheduledAt() string {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return ""
+}
+
+func (x *Post) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Post) GetContentHtml() string {
+	if x != nil {
+		return x.ContentHtml
+	}
+	return ""
+}
+
+func (x *Post) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+func (x *Post) GetToc() []*TocEntry {
+	if x != nil {
+		return x.Toc
+	}
+	return nil
+}
+
+func (x *Post) GetReadingTime() int32 {
+	if x != nil {
+		return x.ReadingTime
+	}
+	return 0
+}
+
+func (x *Post) GetWordCount() int32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *Post) GetSubtitle() string {
+	if x != nil {
+		return x.Subtitle
+	}
+	return ""
+}
+
+type TocEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         int32                  `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"` // Anchor of the heading in content_html
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TocEntry) Reset() {
+	*x = TocEntry{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TocEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TocEntry) ProtoMessage() {}
+
+func (x *TocEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TocEntry.ProtoReflect.Descriptor instead.
+func (*TocEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TocEntry) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *TocEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TocEntry) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
 type Author struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -661,7 +810,7 @@ type Author struct {
 
 func (x *Author) Reset() {
 	*x = Author{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[9]
+	mi := &file_pkg_proto_blog_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -673,7 +822,7 @@ func (x *Author) String() string {
 func (*Author) ProtoMessage() {}
 
 func (x *Author) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[9]
+	mi := &file_pkg_proto_blog_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -686,7 +835,7 @@ func (x *Author) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Author.ProtoReflect.Descriptor instead.
 func (*Author) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{9}
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Author) GetId() string {
@@ -727,13 +876,15 @@ type User struct {
 	Followers     int32                  `protobuf:"varint,6,opt,name=followers,proto3" json:"followers,omitempty"`
 	Following     int32                  `protobuf:"varint,7,opt,name=following,proto3" json:"following,omitempty"`
 	IsFollowing   bool                   `protobuf:"varint,8,opt,name=is_following,json=isFollowing,proto3" json:"is_following,omitempty"` // Computed
+	Username      string                 `protobuf:"bytes,9,opt,name=username,proto3" json:"username,omitempty"`
+	Version       int64                  `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"` // Profile version in the auth service, for UpdateUser
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *User) Reset() {
 	*x = User{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[10]
+	mi := &file_pkg_proto_blog_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -745,7 +896,7 @@ func (x *User) String() string {
 func (*User) ProtoMessage() {}
 
 func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[10]
+	mi := &file_pkg_proto_blog_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -758,7 +909,7 @@ func (x *User) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use User.ProtoReflect.Descriptor instead.
 func (*User) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{10}
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *User) GetId() string {
@@ -817,6 +968,20 @@ func (x *User) GetIsFollowing() bool {
 	return false
 }
 
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type ListPostsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
@@ -830,7 +995,7 @@ type ListPostsRequest struct {
 
 func (x *ListPostsRequest) Reset() {
 	*x = ListPostsRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[11]
+	mi := &file_pkg_proto_blog_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -842,7 +1007,7 @@ func (x *ListPostsRequest) String() string {
 func (*ListPostsRequest) ProtoMessage() {}
 
 func (x *ListPostsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[11]
+	mi := &file_pkg_proto_blog_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -855,7 +1020,7 @@ func (x *ListPostsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPostsRequest.ProtoReflect.Descriptor instead.
 func (*ListPostsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{11}
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ListPostsRequest) GetPage() int32 {
@@ -895,7 +1060,7 @@ func (x *ListPostsRequest) GetSearchQuery() string {
 
 type ListPostsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"` // Without content or content_html; use excerpt and GetPost
 	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -903,7 +1068,7 @@ type ListPostsResponse struct {
 
 func (x *ListPostsResponse) Reset() {
 	*x = ListPostsResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[12]
+	mi := &file_pkg_proto_blog_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -915,7 +1080,7 @@ func (x *ListPostsResponse) String() string {
 func (*ListPostsResponse) ProtoMessage() {}
 
 func (x *ListPostsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[12]
+	mi := &file_pkg_proto_blog_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -928,7 +1093,7 @@ func (x *ListPostsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPostsResponse.ProtoReflect.Descriptor instead.
 func (*ListPostsResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{12}
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ListPostsResponse) GetPosts() []*Post {
@@ -945,32 +1110,30 @@ func (x *ListPostsResponse) GetTotal() int32 {
 	return 0
 }
 
-type CreatePostRequest struct {
+type GetHomeFeedRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
-	AuthorId      string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
-	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
-	CoverImage    string                 `protobuf:"bytes,5,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"` // next_cursor of the previous page; empty for the first
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreatePostRequest) Reset() {
-	*x = CreatePostRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[13]
+func (x *GetHomeFeedRequest) Reset() {
+	*x = GetHomeFeedRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreatePostRequest) String() string {
+func (x *GetHomeFeedRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreatePostRequest) ProtoMessage() {}
+func (*GetHomeFeedRequest) ProtoMessage() {}
 
-func (x *CreatePostRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[13]
+func (x *GetHomeFeedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -981,68 +1144,55 @@ func (x *CreatePostRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreatePostRequest.ProtoReflect.Descriptor instead.
-func (*CreatePostRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *CreatePostRequest) GetTitle() string {
-	if x != nil {
-		return x.Title
-	}
-	return ""
+// Deprecated: Use GetHomeFeedRequest.ProtoReflect.Descriptor instead.
+func (*GetHomeFeedRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *CreatePostRequest) GetContent() string {
+func (x *GetHomeFeedRequest) GetUserId() string {
 	if x != nil {
-		return x.Content
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *CreatePostRequest) GetAuthorId() string {
+func (x *GetHomeFeedRequest) GetCursor() string {
 	if x != nil {
-		return x.AuthorId
+		return x.Cursor
 	}
 	return ""
 }
 
-func (x *CreatePostRequest) GetTags() []string {
-	if x != nil {
-		return x.Tags
-	}
-	return nil
-}
-
-func (x *CreatePostRequest) GetCoverImage() string {
+func (x *GetHomeFeedRequest) GetLimit() int32 {
 	if x != nil {
-		return x.CoverImage
+		return x.Limit
 	}
-	return ""
+	return 0
 }
 
-type CreatePostResponse struct {
+type GetHomeFeedResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	Items         []*FeedItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Empty after the last page
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreatePostResponse) Reset() {
-	*x = CreatePostResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[14]
+func (x *GetHomeFeedResponse) Reset() {
+	*x = GetHomeFeedResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreatePostResponse) String() string {
+func (x *GetHomeFeedResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreatePostResponse) ProtoMessage() {}
+func (*GetHomeFeedResponse) ProtoMessage() {}
 
-func (x *CreatePostResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[14]
+func (x *GetHomeFeedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1053,41 +1203,50 @@ func (x *CreatePostResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreatePostResponse.ProtoReflect.Descriptor instead.
-func (*CreatePostResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use GetHomeFeedResponse.ProtoReflect.Descriptor instead.
+func (*GetHomeFeedResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *CreatePostResponse) GetPost() *Post {
+func (x *GetHomeFeedResponse) GetItems() []*FeedItem {
 	if x != nil {
-		return x.Post
+		return x.Items
 	}
 	return nil
 }
 
-type GetPostRequest struct {
+func (x *GetHomeFeedResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// Feeds list new posts from followed authors and tags, then posts trending
+// over the last month once those run out. Posts the user has read are left out.
+type FeedItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
-	CurrentUserId string                 `protobuf:"bytes,2,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"` // For is_bookmarked and similar computations
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`     // Without content, like ListPosts
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // "following", "tag" or "popular"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetPostRequest) Reset() {
-	*x = GetPostRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[15]
+func (x *FeedItem) Reset() {
+	*x = FeedItem{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetPostRequest) String() string {
+func (x *FeedItem) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPostRequest) ProtoMessage() {}
+func (*FeedItem) ProtoMessage() {}
 
-func (x *GetPostRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[15]
+func (x *FeedItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1098,47 +1257,50 @@ func (x *GetPostRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPostRequest.ProtoReflect.Descriptor instead.
-func (*GetPostRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use FeedItem.ProtoReflect.Descriptor instead.
+func (*FeedItem) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *GetPostRequest) GetPostId() string {
+func (x *FeedItem) GetPost() *Post {
 	if x != nil {
-		return x.PostId
+		return x.Post
 	}
-	return ""
+	return nil
 }
 
-func (x *GetPostRequest) GetCurrentUserId() string {
+func (x *FeedItem) GetReason() string {
 	if x != nil {
-		return x.CurrentUserId
+		return x.Reason
 	}
 	return ""
 }
 
-type GetPostResponse struct {
+type ListTrendingRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	Window        string                 `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"` // "day", "week" or "month"; defaults to "week"
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`       // Only posts with this tag
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	CurrentUserId string                 `protobuf:"bytes,4,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"` // Hides posts of users blocked either way
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetPostResponse) Reset() {
-	*x = GetPostResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[16]
+func (x *ListTrendingRequest) Reset() {
+	*x = ListTrendingRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetPostResponse) String() string {
+func (x *ListTrendingRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPostResponse) ProtoMessage() {}
+func (*ListTrendingRequest) ProtoMessage() {}
 
-func (x *GetPostResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[16]
+func (x *ListTrendingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1149,45 +1311,2475 @@ func (x *GetPostResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPostResponse.ProtoReflect.Descriptor instead.
-func (*GetPostResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use ListTrendingRequest.ProtoReflect.Descriptor instead.
+func (*ListTrendingRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *GetPostResponse) GetPost() *Post {
+func (x *ListTrendingRequest) GetWindow() string {
 	if x != nil {
-		return x.Post
+		return x.Window
 	}
-	return nil
+	return ""
 }
 
-type UpdatePostRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
-	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
-	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
-	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
-	CoverImage    string                 `protobuf:"bytes,6,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ListTrendingRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
 }
 
-func (x *UpdatePostRequest) Reset() {
+func (x *ListTrendingRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListTrendingRequest) GetCurrentUserId() string {
+	if x != nil {
+		return x.CurrentUserId
+	}
+	return ""
+}
+
+// Trending posts are ranked by claps, comments, bookmarks and reads within
+// the window, each weighted less the older it is. Rankings are recomputed
+// periodically, as of computed_at.
+type ListTrendingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*TrendingPost        `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`                             // Highest score first
+	ComputedAt    string                 `protobuf:"bytes,2,opt,name=computed_at,json=computedAt,proto3" json:"computed_at,omitempty"` // When the rankings were computed; empty without posts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrendingResponse) Reset() {
+	*x = ListTrendingResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrendingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrendingResponse) ProtoMessage() {}
+
+func (x *ListTrendingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrendingResponse.ProtoReflect.Descriptor instead.
+func (*ListTrendingResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListTrendingResponse) GetPosts() []*TrendingPost {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+func (x *ListTrendingResponse) GetComputedAt() string {
+	if x != nil {
+		return x.ComputedAt
+	}
+	return ""
+}
+
+type TrendingPost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"` // Without content, like ListPosts
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrendingPost) Reset() {
+	*x = TrendingPost{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrendingPost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendingPost) ProtoMessage() {}
+
+func (x *TrendingPost) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendingPost.ProtoReflect.Descriptor instead.
+func (*TrendingPost) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TrendingPost) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+func (x *TrendingPost) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type SearchPostsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Web search syntax: words, "quoted phrases", or, and -excluded words
+	Query         string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Tag           string `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`       // Only posts with this tag
+	Author        string `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"` // Only posts by this user, by ID or @handle
+	Page          int32  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	CurrentUserId string `protobuf:"bytes,6,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"` // Hides posts of users blocked either way
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchPostsRequest) Reset() {
+	*x = SearchPostsRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchPostsRequest) ProtoMessage() {}
+
+func (x *SearchPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchPostsRequest.ProtoReflect.Descriptor instead.
+func (*SearchPostsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchPostsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchPostsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *SearchPostsRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *SearchPostsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchPostsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchPostsRequest) GetCurrentUserId() string {
+	if x != nil {
+		return x.CurrentUserId
+	}
+	return ""
+}
+
+type SearchPostsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Hits  []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"` // Best matches first
+	Total int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Matches per tag and per author, each counted with the other filter
+	// applied but not its own, so other choices stay visible
+	Tags          []*Facet `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Authors       []*Facet `protobuf:"bytes,4,rep,name=authors,proto3" json:"authors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchPostsResponse) Reset() {
+	*x = SearchPostsResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchPostsResponse) ProtoMessage() {}
+
+func (x *SearchPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchPostsResponse.ProtoReflect.Descriptor instead.
+func (*SearchPostsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SearchPostsResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *SearchPostsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchPostsResponse) GetTags() []*Facet {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SearchPostsResponse) GetAuthors() []*Facet {
+	if x != nil {
+		return x.Authors
+	}
+	return nil
+}
+
+type SearchHit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Post  *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"` // Without content, like ListPosts
+	Rank  float64                `protobuf:"fixed64,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	// Title and content fragments, HTML-escaped, with matches in <mark>
+	TitleHighlight string `protobuf:"bytes,3,opt,name=title_highlight,json=titleHighlight,proto3" json:"title_highlight,omitempty"`
+	Snippet        string `protobuf:"bytes,4,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SearchHit) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+func (x *SearchHit) GetRank() float64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchHit) GetTitleHighlight() string {
+	if x != nil {
+		return x.TitleHighlight
+	}
+	return ""
+}
+
+func (x *SearchHit) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type Facet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"` // Tag name or author ID
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"` // Author name; the tag name again for tags
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Facet) Reset() {
+	*x = Facet{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Facet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Facet) ProtoMessage() {}
+
+func (x *Facet) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Facet.ProtoReflect.Descriptor instead.
+func (*Facet) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Facet) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Facet) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Facet) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Types         []string               `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`                                        // Any of "user", "tag" and "post"; empty means all
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                       // Per type
+	CurrentUserId string                 `protobuf:"bytes,4,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"` // Hides users blocked either way, and their posts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetCurrentUserId() string {
+	if x != nil {
+		return x.CurrentUserId
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Users, then tags, then posts, each best match first
+	Results       []*SearchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`     // "user", "tag" or "post"; the field of that name is set
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"` // Relevance within the type
+	User          *UserSummary           `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Tag           *TagSummary            `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+	Post          *SearchHit             `protobuf:"bytes,5,opt,name=post,proto3" json:"post,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SearchResult) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SearchResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchResult) GetUser() *UserSummary {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *SearchResult) GetTag() *TagSummary {
+	if x != nil {
+		return x.Tag
+	}
+	return nil
+}
+
+func (x *SearchResult) GetPost() *SearchHit {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+type UserSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,4,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	Bio           string                 `protobuf:"bytes,5,opt,name=bio,proto3" json:"bio,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserSummary) Reset() {
+	*x = UserSummary{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserSummary) ProtoMessage() {}
+
+func (x *UserSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserSummary.ProtoReflect.Descriptor instead.
+func (*UserSummary) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UserSummary) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserSummary) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UserSummary) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserSummary) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *UserSummary) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+type TagSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	PostCount     int32                  `protobuf:"varint,2,opt,name=post_count,json=postCount,proto3" json:"post_count,omitempty"` // Published posts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagSummary) Reset() {
+	*x = TagSummary{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagSummary) ProtoMessage() {}
+
+func (x *TagSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagSummary.ProtoReflect.Descriptor instead.
+func (*TagSummary) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TagSummary) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TagSummary) GetPostCount() int32 {
+	if x != nil {
+		return x.PostCount
+	}
+	return 0
+}
+
+type SuggestSearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	CurrentUserId string                 `protobuf:"bytes,3,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestSearchRequest) Reset() {
+	*x = SuggestSearchRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestSearchRequest) ProtoMessage() {}
+
+func (x *SuggestSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestSearchRequest.ProtoReflect.Descriptor instead.
+func (*SuggestSearchRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SuggestSearchRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *SuggestSearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SuggestSearchRequest) GetCurrentUserId() string {
+	if x != nil {
+		return x.CurrentUserId
+	}
+	return ""
+}
+
+type SuggestSearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suggestions   []*Suggestion          `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestSearchResponse) Reset() {
+	*x = SuggestSearchResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestSearchResponse) ProtoMessage() {}
+
+func (x *SuggestSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestSearchResponse.ProtoReflect.Descriptor instead.
+func (*SuggestSearchResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SuggestSearchResponse) GetSuggestions() []*Suggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type Suggestion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                            // "user" or "tag"
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`                          // User ID or tag name
+	Label         string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`                          // Name to show
+	Username      string                 `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`                    // For users
+	AvatarUrl     string                 `protobuf:"bytes,5,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"` // For users
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suggestion) Reset() {
+	*x = Suggestion{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestion) ProtoMessage() {}
+
+func (x *Suggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestion.ProtoReflect.Descriptor instead.
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Suggestion) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Suggestion) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Suggestion) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Suggestion) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Suggestion) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+type CreatePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	AuthorId      string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	CoverImage    string                 `protobuf:"bytes,5,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`                              // "draft" or "published" (the default)
+	ScheduledAt   string                 `protobuf:"bytes,7,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"` // RFC 3339; publishes the post at this time instead
+	Subtitle      string                 `protobuf:"bytes,8,opt,name=subtitle,proto3" json:"subtitle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePostRequest) Reset() {
+	*x = CreatePostRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePostRequest) ProtoMessage() {}
+
+func (x *CreatePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePostRequest.ProtoReflect.Descriptor instead.
+func (*CreatePostRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CreatePostRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreatePostRequest) GetCoverImage() string {
+	if x != nil {
+		return x.CoverImage
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetScheduledAt() string {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetSubtitle() string {
+	if x != nil {
+		return x.Subtitle
+	}
+	return ""
+}
+
+type CreatePostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePostResponse) Reset() {
+	*x = CreatePostResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePostResponse) ProtoMessage() {}
+
+func (x *CreatePostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePostResponse.ProtoReflect.Descriptor instead.
+func (*CreatePostResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CreatePostResponse) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+type GetPostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	CurrentUserId string                 `protobuf:"bytes,2,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"` // For is_bookmarked and similar computations
+	MarkRead      bool                   `protobuf:"varint,3,opt,name=mark_read,json=markRead,proto3" json:"mark_read,omitempty"`                 // Remember that current_user_id read the post, to leave it out of their feed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPostRequest) Reset() {
+	*x = GetPostRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPostRequest) ProtoMessage() {}
+
+func (x *GetPostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPostRequest.ProtoReflect.Descriptor instead.
+func (*GetPostRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetPostRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *GetPostRequest) GetCurrentUserId() string {
+	if x != nil {
+		return x.CurrentUserId
+	}
+	return ""
+}
+
+func (x *GetPostRequest) GetMarkRead() bool {
+	if x != nil {
+		return x.MarkRead
+	}
+	return false
+}
+
+type GetPostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPostResponse) Reset() {
+	*x = GetPostResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPostResponse) ProtoMessage() {}
+
+func (x *GetPostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPostResponse.ProtoReflect.Descriptor instead.
+func (*GetPostResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetPostResponse) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+type UpdatePostRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	PostId      string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
+	Title       string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Content     string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	Tags        []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	CoverImage  string                 `protobuf:"bytes,6,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	Permissions []string               `protobuf:"bytes,7,rep,name=permissions,proto3" json:"permissions,omitempty"`                    // Caller's role permissions, for admin overrides
+	Status      string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`                              // "draft", "published" or "archived"; empty leaves it unchanged
+	ScheduledAt string                 `protobuf:"bytes,9,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"` // RFC 3339; schedules the post instead of setting status
+	Version     int64                  `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`                          // The version being edited; a stale one fails with FAILED_PRECONDITION
+	// Paths among title, subtitle, content, cover_image and tags to write;
+	// the rest are left alone. Without a mask all of them are replaced.
+	// Status and scheduled_at apply whenever they are set, mask or not.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,11,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	Subtitle      string                 `protobuf:"bytes,12,opt,name=subtitle,proto3" json:"subtitle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePostRequest) Reset() {
 	*x = UpdatePostRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[17]
+	mi := &file_pkg_proto_blog_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePostRequest) ProtoMessage() {}
+
+func (x *UpdatePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePostRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePostRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *UpdatePostRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdatePostRequest) GetCoverImage() string {
+	if x != nil {
+		return x.CoverImage
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *UpdatePostRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetScheduledAt() string {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdatePostRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdatePostRequest) GetSubtitle() string {
+	if x != nil {
+		return x.Subtitle
+	}
+	return ""
+}
+
+type UpdatePostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePostResponse) Reset() {
+	*x = UpdatePostResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePostResponse) ProtoMessage() {}
+
+func (x *UpdatePostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePostResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePostResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *UpdatePostResponse) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+type ListMyDraftsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyDraftsRequest) Reset() {
+	*x = ListMyDraftsRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyDraftsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyDraftsRequest) ProtoMessage() {}
+
+func (x *ListMyDraftsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyDraftsRequest.ProtoReflect.Descriptor instead.
+func (*ListMyDraftsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListMyDraftsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListMyDraftsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListMyDraftsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListMyDraftsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"` // Drafts and scheduled posts, most recently edited first, without content
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyDraftsResponse) Reset() {
+	*x = ListMyDraftsResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyDraftsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyDraftsResponse) ProtoMessage() {}
+
+func (x *ListMyDraftsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyDraftsResponse.ProtoReflect.Descriptor instead.
+func (*ListMyDraftsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListMyDraftsResponse) GetPosts() []*Post {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+func (x *ListMyDraftsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type PublishPostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // For authorization
+	ScheduledAt   string                 `protobuf:"bytes,3,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"` // RFC 3339; empty publishes now
+	Permissions   []string               `protobuf:"bytes,4,rep,name=permissions,proto3" json:"permissions,omitempty"`                    // Caller's role permissions, for admin overrides
+	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`                           // Version of the post being published, as in UpdatePost
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishPostRequest) Reset() {
+	*x = PublishPostRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishPostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishPostRequest) ProtoMessage() {}
+
+func (x *PublishPostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishPostRequest.ProtoReflect.Descriptor instead.
+func (*PublishPostRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PublishPostRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *PublishPostRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *PublishPostRequest) GetScheduledAt() string {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return ""
+}
+
+func (x *PublishPostRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *PublishPostRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type PublishPostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishPostResponse) Reset() {
+	*x = PublishPostResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishPostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishPostResponse) ProtoMessage() {}
+
+func (x *PublishPostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishPostResponse.ProtoReflect.Descriptor instead.
+func (*PublishPostResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PublishPostResponse) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+type UnpublishPostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
+	Permissions   []string               `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"`     // Caller's role permissions, for admin overrides
+	Version       int64                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`            // Version of the post being unpublished, as in UpdatePost
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpublishPostRequest) Reset() {
+	*x = UnpublishPostRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpublishPostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpublishPostRequest) ProtoMessage() {}
+
+func (x *UnpublishPostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpublishPostRequest.ProtoReflect.Descriptor instead.
+func (*UnpublishPostRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *UnpublishPostRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *UnpublishPostRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UnpublishPostRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *UnpublishPostRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type UnpublishPostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpublishPostResponse) Reset() {
+	*x = UnpublishPostResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpublishPostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpublishPostResponse) ProtoMessage() {}
+
+func (x *UnpublishPostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpublishPostResponse.ProtoReflect.Descriptor instead.
+func (*UnpublishPostResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *UnpublishPostResponse) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+// A saved version of a post. Revisions are numbered from 1 per post and are
+// never changed once written.
+type Revision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	PostId        string                 `protobuf:"bytes,2,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"` // Left out by ListRevisions
+	CoverImage    string                 `protobuf:"bytes,5,opt,name=cover_image,json=coverImage,proto3" json:"cover_image,omitempty"`
+	Editor        *Author                `protobuf:"bytes,6,opt,name=editor,proto3" json:"editor,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	RestoredFrom  int32                  `protobuf:"varint,8,opt,name=restored_from,json=restoredFrom,proto3" json:"restored_from,omitempty"` // Set when the revision restored an older one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Revision) Reset() {
+	*x = Revision{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Revision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revision) ProtoMessage() {}
+
+func (x *Revision) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revision.ProtoReflect.Descriptor instead.
+func (*Revision) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Revision) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Revision) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *Revision) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Revision) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Revision) GetCoverImage() string {
+	if x != nil {
+		return x.CoverImage
+	}
+	return ""
+}
+
+func (x *Revision) GetEditor() *Author {
+	if x != nil {
+		return x.Editor
+	}
+	return nil
+}
+
+func (x *Revision) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Revision) GetRestoredFrom() int32 {
+	if x != nil {
+		return x.RestoredFrom
+	}
+	return 0
+}
+
+type ListRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
+	Permissions   []string               `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"`     // Caller's role permissions, for admin overrides
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsRequest) Reset() {
+	*x = ListRevisionsRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsRequest) ProtoMessage() {}
+
+func (x *ListRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListRevisionsRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *ListRevisionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListRevisionsRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type ListRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*Revision            `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"` // Newest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsResponse) Reset() {
+	*x = ListRevisionsResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsResponse) ProtoMessage() {}
+
+func (x *ListRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ListRevisionsResponse) GetRevisions() []*Revision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+type GetRevisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Permissions   []string               `protobuf:"bytes,4,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRevisionRequest) Reset() {
+	*x = GetRevisionRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRevisionRequest) ProtoMessage() {}
+
+func (x *GetRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRevisionRequest.ProtoReflect.Descriptor instead.
+func (*GetRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *GetRevisionRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *GetRevisionRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *GetRevisionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetRevisionRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type GetRevisionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      *Revision              `protobuf:"bytes,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRevisionResponse) Reset() {
+	*x = GetRevisionResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRevisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRevisionResponse) ProtoMessage() {}
+
+func (x *GetRevisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRevisionResponse.ProtoReflect.Descriptor instead.
+func (*GetRevisionResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GetRevisionResponse) GetRevision() *Revision {
+	if x != nil {
+		return x.Revision
+	}
+	return nil
+}
+
+type DiffRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	From          int32                  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            int32                  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	Granularity   string                 `protobuf:"bytes,4,opt,name=granularity,proto3" json:"granularity,omitempty"` // "line" (the default) or "word"
+	UserId        string                 `protobuf:"bytes,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Permissions   []string               `protobuf:"bytes,6,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffRevisionsRequest) Reset() {
+	*x = DiffRevisionsRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRevisionsRequest) ProtoMessage() {}
+
+func (x *DiffRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*DiffRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DiffRevisionsRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *DiffRevisionsRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *DiffRevisionsRequest) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *DiffRevisionsRequest) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *DiffRevisionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DiffRevisionsRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type DiffChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Op            string                 `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"` // "equal", "insert" or "delete"
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffChunk) Reset() {
+	*x = DiffChunk{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffChunk) ProtoMessage() {}
+
+func (x *DiffChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffChunk.ProtoReflect.Descriptor instead.
+func (*DiffChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *DiffChunk) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *DiffChunk) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type DiffRevisionsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Title          []*DiffChunk           `protobuf:"bytes,1,rep,name=title,proto3" json:"title,omitempty"` // Always diffed by word
+	Content        []*DiffChunk           `protobuf:"bytes,2,rep,name=content,proto3" json:"content,omitempty"`
+	FromCoverImage string                 `protobuf:"bytes,3,opt,name=from_cover_image,json=fromCoverImage,proto3" json:"from_cover_image,omitempty"`
+	ToCoverImage   string                 `protobuf:"bytes,4,opt,name=to_cover_image,json=toCoverImage,proto3" json:"to_cover_image,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DiffRevisionsResponse) Reset() {
+	*x = DiffRevisionsResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRevisionsResponse) ProtoMessage() {}
+
+func (x *DiffRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*DiffRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *DiffRevisionsResponse) GetTitle() []*DiffChunk {
+	if x != nil {
+		return x.Title
+	}
+	return nil
+}
+
+func (x *DiffRevisionsResponse) GetContent() []*DiffChunk {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *DiffRevisionsResponse) GetFromCoverImage() string {
+	if x != nil {
+		return x.FromCoverImage
+	}
+	return ""
+}
+
+func (x *DiffRevisionsResponse) GetToCoverImage() string {
+	if x != nil {
+		return x.ToCoverImage
+	}
+	return ""
+}
+
+type RestoreRevisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Permissions   []string               `protobuf:"bytes,4,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"` // Version of the post the restore is made against, as in UpdatePost
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRevisionRequest) Reset() {
+	*x = RestoreRevisionRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRevisionRequest) ProtoMessage() {}
+
+func (x *RestoreRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRevisionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RestoreRevisionRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *RestoreRevisionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *RestoreRevisionRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RestoreRevisionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	Revision      *Revision              `protobuf:"bytes,2,opt,name=revision,proto3" json:"revision,omitempty"` // The new revision the restore was saved as
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRevisionResponse) Reset() {
+	*x = RestoreRevisionResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRevisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRevisionResponse) ProtoMessage() {}
+
+func (x *RestoreRevisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRevisionResponse.ProtoReflect.Descriptor instead.
+func (*RestoreRevisionResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *RestoreRevisionResponse) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+func (x *RestoreRevisionResponse) GetRevision() *Revision {
+	if x != nil {
+		return x.Revision
+	}
+	return nil
+}
+
+type DeletePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
+	Permissions   []string               `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"`     // Caller's role permissions, for moderator overrides
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePostRequest) Reset() {
+	*x = DeletePostRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePostRequest) ProtoMessage() {}
+
+func (x *DeletePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePostRequest.ProtoReflect.Descriptor instead.
+func (*DeletePostRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *DeletePostRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *DeletePostRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeletePostRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type DeletePostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePostResponse) Reset() {
+	*x = DeletePostResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePostResponse) ProtoMessage() {}
+
+func (x *DeletePostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePostResponse.ProtoReflect.Descriptor instead.
+func (*DeletePostResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *DeletePostResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ToggleClapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleClapRequest) Reset() {
+	*x = ToggleClapRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatePostRequest) String() string {
+func (x *ToggleClapRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatePostRequest) ProtoMessage() {}
+func (*ToggleClapRequest) ProtoMessage() {}
 
-func (x *UpdatePostRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[17]
+func (x *ToggleClapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1198,75 +3790,345 @@ func (x *UpdatePostRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatePostRequest.ProtoReflect.Descriptor instead.
-func (*UpdatePostRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use ToggleClapRequest.ProtoReflect.Descriptor instead.
+func (*ToggleClapRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *UpdatePostRequest) GetPostId() string {
+func (x *ToggleClapRequest) GetPostId() string {
 	if x != nil {
 		return x.PostId
 	}
 	return ""
 }
 
-func (x *UpdatePostRequest) GetUserId() string {
-	if x != nil {
-		return x.UserId
-	}
-	return ""
+func (x *ToggleClapRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ToggleClapResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Clapped       bool                   `protobuf:"varint,1,opt,name=clapped,proto3" json:"clapped,omitempty"`
+	ClapsCount    int32                  `protobuf:"varint,2,opt,name=claps_count,json=clapsCount,proto3" json:"claps_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleClapResponse) Reset() {
+	*x = ToggleClapResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleClapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleClapResponse) ProtoMessage() {}
+
+func (x *ToggleClapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleClapResponse.ProtoReflect.Descriptor instead.
+func (*ToggleClapResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *ToggleClapResponse) GetClapped() bool {
+	if x != nil {
+		return x.Clapped
+	}
+	return false
+}
+
+func (x *ToggleClapResponse) GetClapsCount() int32 {
+	if x != nil {
+		return x.ClapsCount
+	}
+	return 0
+}
+
+type ToggleFollowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    string                 `protobuf:"bytes,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FolloweeId    string                 `protobuf:"bytes,2,opt,name=followee_id,json=followeeId,proto3" json:"followee_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleFollowRequest) Reset() {
+	*x = ToggleFollowRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleFollowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleFollowRequest) ProtoMessage() {}
+
+func (x *ToggleFollowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleFollowRequest.ProtoReflect.Descriptor instead.
+func (*ToggleFollowRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ToggleFollowRequest) GetFollowerId() string {
+	if x != nil {
+		return x.FollowerId
+	}
+	return ""
+}
+
+func (x *ToggleFollowRequest) GetFolloweeId() string {
+	if x != nil {
+		return x.FolloweeId
+	}
+	return ""
+}
+
+type ToggleFollowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Following     bool                   `protobuf:"varint,1,opt,name=following,proto3" json:"following,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleFollowResponse) Reset() {
+	*x = ToggleFollowResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleFollowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleFollowResponse) ProtoMessage() {}
+
+func (x *ToggleFollowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleFollowResponse.ProtoReflect.Descriptor instead.
+func (*ToggleFollowResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *ToggleFollowResponse) GetFollowing() bool {
+	if x != nil {
+		return x.Following
+	}
+	return false
+}
+
+// Blocking hides each user from the other's searches and ends follows
+// between them
+type ToggleBlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockerId     string                 `protobuf:"bytes,1,opt,name=blocker_id,json=blockerId,proto3" json:"blocker_id,omitempty"`
+	BlockedId     string                 `protobuf:"bytes,2,opt,name=blocked_id,json=blockedId,proto3" json:"blocked_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleBlockRequest) Reset() {
+	*x = ToggleBlockRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleBlockRequest) ProtoMessage() {}
+
+func (x *ToggleBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleBlockRequest.ProtoReflect.Descriptor instead.
+func (*ToggleBlockRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *ToggleBlockRequest) GetBlockerId() string {
+	if x != nil {
+		return x.BlockerId
+	}
+	return ""
+}
+
+func (x *ToggleBlockRequest) GetBlockedId() string {
+	if x != nil {
+		return x.BlockedId
+	}
+	return ""
+}
+
+type ToggleBlockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blocked       bool                   `protobuf:"varint,1,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleBlockResponse) Reset() {
+	*x = ToggleBlockResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleBlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleBlockResponse) ProtoMessage() {}
+
+func (x *ToggleBlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleBlockResponse.ProtoReflect.Descriptor instead.
+func (*ToggleBlockResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ToggleBlockResponse) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+type ToggleTagFollowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"` // Tag name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleTagFollowRequest) Reset() {
+	*x = ToggleTagFollowRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleTagFollowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *UpdatePostRequest) GetTitle() string {
+func (*ToggleTagFollowRequest) ProtoMessage() {}
+
+func (x *ToggleTagFollowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[62]
 	if x != nil {
-		return x.Title
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *UpdatePostRequest) GetContent() string {
-	if x != nil {
-		return x.Content
-	}
-	return ""
+// Deprecated: Use ToggleTagFollowRequest.ProtoReflect.Descriptor instead.
+func (*ToggleTagFollowRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{62}
 }
 
-func (x *UpdatePostRequest) GetTags() []string {
+func (x *ToggleTagFollowRequest) GetUserId() string {
 	if x != nil {
-		return x.Tags
+		return x.UserId
 	}
-	return nil
+	return ""
 }
 
-func (x *UpdatePostRequest) GetCoverImage() string {
+func (x *ToggleTagFollowRequest) GetTag() string {
 	if x != nil {
-		return x.CoverImage
+		return x.Tag
 	}
 	return ""
 }
 
-type UpdatePostResponse struct {
+type ToggleTagFollowResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Post          *Post                  `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	Following     bool                   `protobuf:"varint,1,opt,name=following,proto3" json:"following,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdatePostResponse) Reset() {
-	*x = UpdatePostResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[18]
+func (x *ToggleTagFollowResponse) Reset() {
+	*x = ToggleTagFollowResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatePostResponse) String() string {
+func (x *ToggleTagFollowResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatePostResponse) ProtoMessage() {}
+func (*ToggleTagFollowResponse) ProtoMessage() {}
 
-func (x *UpdatePostResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[18]
+func (x *ToggleTagFollowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1277,41 +4139,41 @@ func (x *UpdatePostResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatePostResponse.ProtoReflect.Descriptor instead.
-func (*UpdatePostResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use ToggleTagFollowResponse.ProtoReflect.Descriptor instead.
+func (*ToggleTagFollowResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *UpdatePostResponse) GetPost() *Post {
+func (x *ToggleTagFollowResponse) GetFollowing() bool {
 	if x != nil {
-		return x.Post
+		return x.Following
 	}
-	return nil
+	return false
 }
 
-type DeletePostRequest struct {
+type ToggleBookmarkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeletePostRequest) Reset() {
-	*x = DeletePostRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[19]
+func (x *ToggleBookmarkRequest) Reset() {
+	*x = ToggleBookmarkRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeletePostRequest) String() string {
+func (x *ToggleBookmarkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeletePostRequest) ProtoMessage() {}
+func (*ToggleBookmarkRequest) ProtoMessage() {}
 
-func (x *DeletePostRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[19]
+func (x *ToggleBookmarkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1322,47 +4184,47 @@ func (x *DeletePostRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeletePostRequest.ProtoReflect.Descriptor instead.
-func (*DeletePostRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ToggleBookmarkRequest.ProtoReflect.Descriptor instead.
+func (*ToggleBookmarkRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *DeletePostRequest) GetPostId() string {
+func (x *ToggleBookmarkRequest) GetPostId() string {
 	if x != nil {
 		return x.PostId
 	}
 	return ""
 }
 
-func (x *DeletePostRequest) GetUserId() string {
+func (x *ToggleBookmarkRequest) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-type DeletePostResponse struct {
+type ToggleBookmarkResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Bookmarked    bool                   `protobuf:"varint,1,opt,name=bookmarked,proto3" json:"bookmarked,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeletePostResponse) Reset() {
-	*x = DeletePostResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[20]
+func (x *ToggleBookmarkResponse) Reset() {
+	*x = ToggleBookmarkResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeletePostResponse) String() string {
+func (x *ToggleBookmarkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeletePostResponse) ProtoMessage() {}
+func (*ToggleBookmarkResponse) ProtoMessage() {}
 
-func (x *DeletePostResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[20]
+func (x *ToggleBookmarkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1373,41 +4235,42 @@ func (x *DeletePostResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeletePostResponse.ProtoReflect.Descriptor instead.
-func (*DeletePostResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use ToggleBookmarkResponse.ProtoReflect.Descriptor instead.
+func (*ToggleBookmarkResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *DeletePostResponse) GetSuccess() bool {
+func (x *ToggleBookmarkResponse) GetBookmarked() bool {
 	if x != nil {
-		return x.Success
+		return x.Bookmarked
 	}
 	return false
 }
 
-type ToggleClapRequest struct {
+type ListNotificationsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToggleClapRequest) Reset() {
-	*x = ToggleClapRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[21]
+func (x *ListNotificationsRequest) Reset() {
+	*x = ListNotificationsRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToggleClapRequest) String() string {
+func (x *ListNotificationsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToggleClapRequest) ProtoMessage() {}
+func (*ListNotificationsRequest) ProtoMessage() {}
 
-func (x *ToggleClapRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[21]
+func (x *ListNotificationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1418,48 +4281,56 @@ func (x *ToggleClapRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToggleClapRequest.ProtoReflect.Descriptor instead.
-func (*ToggleClapRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use ListNotificationsRequest.ProtoReflect.Descriptor instead.
+func (*ListNotificationsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *ToggleClapRequest) GetPostId() string {
+func (x *ListNotificationsRequest) GetUserId() string {
 	if x != nil {
-		return x.PostId
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *ToggleClapRequest) GetUserId() string {
+func (x *ListNotificationsRequest) GetPage() int32 {
 	if x != nil {
-		return x.UserId
+		return x.Page
 	}
-	return ""
+	return 0
 }
 
-type ToggleClapResponse struct {
+func (x *ListNotificationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListNotificationsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Clapped       bool                   `protobuf:"varint,1,opt,name=clapped,proto3" json:"clapped,omitempty"`
-	ClapsCount    int32                  `protobuf:"varint,2,opt,name=claps_count,json=clapsCount,proto3" json:"claps_count,omitempty"`
+	Notifications []*Notification        `protobuf:"bytes,1,rep,name=notifications,proto3" json:"notifications,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	UnreadCount   int32                  `protobuf:"varint,3,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToggleClapResponse) Reset() {
-	*x = ToggleClapResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[22]
+func (x *ListNotificationsResponse) Reset() {
+	*x = ListNotificationsResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToggleClapResponse) String() string {
+func (x *ListNotificationsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToggleClapResponse) ProtoMessage() {}
+func (*ListNotificationsResponse) ProtoMessage() {}
 
-func (x *ToggleClapResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[22]
+func (x *ListNotificationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1470,48 +4341,55 @@ func (x *ToggleClapResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToggleClapResponse.ProtoReflect.Descriptor instead.
-func (*ToggleClapResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ListNotificationsResponse.ProtoReflect.Descriptor instead.
+func (*ListNotificationsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *ToggleClapResponse) GetClapped() bool {
+func (x *ListNotificationsResponse) GetNotifications() []*Notification {
 	if x != nil {
-		return x.Clapped
+		return x.Notifications
 	}
-	return false
+	return nil
 }
 
-func (x *ToggleClapResponse) GetClapsCount() int32 {
+func (x *ListNotificationsResponse) GetTotal() int32 {
 	if x != nil {
-		return x.ClapsCount
+		return x.Total
 	}
 	return 0
 }
 
-type ToggleFollowRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	FollowerId    string                 `protobuf:"bytes,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
-	FolloweeId    string                 `protobuf:"bytes,2,opt,name=followee_id,json=followeeId,proto3" json:"followee_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ListNotificationsResponse) GetUnreadCount() int32 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
 }
 
-func (x *ToggleFollowRequest) Reset() {
-	*x = ToggleFollowRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[23]
+type MarkNotificationReadRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	NotificationId string                 `protobuf:"bytes,1,opt,name=notification_id,json=notificationId,proto3" json:"notification_id,omitempty"`
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MarkNotificationReadRequest) Reset() {
+	*x = MarkNotificationReadRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToggleFollowRequest) String() string {
+func (x *MarkNotificationReadRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToggleFollowRequest) ProtoMessage() {}
+func (*MarkNotificationReadRequest) ProtoMessage() {}
 
-func (x *ToggleFollowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[23]
+func (x *MarkNotificationReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1522,47 +4400,47 @@ func (x *ToggleFollowRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToggleFollowRequest.ProtoReflect.Descriptor instead.
-func (*ToggleFollowRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use MarkNotificationReadRequest.ProtoReflect.Descriptor instead.
+func (*MarkNotificationReadRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *ToggleFollowRequest) GetFollowerId() string {
+func (x *MarkNotificationReadRequest) GetNotificationId() string {
 	if x != nil {
-		return x.FollowerId
+		return x.NotificationId
 	}
 	return ""
 }
 
-func (x *ToggleFollowRequest) GetFolloweeId() string {
+func (x *MarkNotificationReadRequest) GetUserId() string {
 	if x != nil {
-		return x.FolloweeId
+		return x.UserId
 	}
 	return ""
 }
 
-type ToggleFollowResponse struct {
+type MarkNotificationReadResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Following     bool                   `protobuf:"varint,1,opt,name=following,proto3" json:"following,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToggleFollowResponse) Reset() {
-	*x = ToggleFollowResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[24]
+func (x *MarkNotificationReadResponse) Reset() {
+	*x = MarkNotificationReadResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToggleFollowResponse) String() string {
+func (x *MarkNotificationReadResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToggleFollowResponse) ProtoMessage() {}
+func (*MarkNotificationReadResponse) ProtoMessage() {}
 
-func (x *ToggleFollowResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[24]
+func (x *MarkNotificationReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1573,41 +4451,41 @@ func (x *ToggleFollowResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToggleFollowResponse.ProtoReflect.Descriptor instead.
-func (*ToggleFollowResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use MarkNotificationReadResponse.ProtoReflect.Descriptor instead.
+func (*MarkNotificationReadResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *ToggleFollowResponse) GetFollowing() bool {
+func (x *MarkNotificationReadResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Following
+		return x.Success
 	}
 	return false
 }
 
-type ToggleBookmarkRequest struct {
+type GetUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // A user ID, or a handle with or without a leading "@"
+	CurrentUserId string                 `protobuf:"bytes,2,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToggleBookmarkRequest) Reset() {
-	*x = ToggleBookmarkRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[25]
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToggleBookmarkRequest) String() string {
+func (x *GetUserRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToggleBookmarkRequest) ProtoMessage() {}
+func (*GetUserRequest) ProtoMessage() {}
 
-func (x *ToggleBookmarkRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[25]
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1618,47 +4496,50 @@ func (x *ToggleBookmarkRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToggleBookmarkRequest.ProtoReflect.Descriptor instead.
-func (*ToggleBookmarkRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *ToggleBookmarkRequest) GetPostId() string {
+func (x *GetUserRequest) GetId() string {
 	if x != nil {
-		return x.PostId
+		return x.Id
 	}
 	return ""
 }
 
-func (x *ToggleBookmarkRequest) GetUserId() string {
+func (x *GetUserRequest) GetCurrentUserId() string {
 	if x != nil {
-		return x.UserId
+		return x.CurrentUserId
 	}
 	return ""
 }
 
-type ToggleBookmarkResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Bookmarked    bool                   `protobuf:"varint,1,opt,name=bookmarked,proto3" json:"bookmarked,omitempty"`
+type GetUserResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// True when id was a handle the user has since changed. Clients should
+	// move to user.username.
+	Moved         bool `protobuf:"varint,2,opt,name=moved,proto3" json:"moved,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToggleBookmarkResponse) Reset() {
-	*x = ToggleBookmarkResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[26]
+func (x *GetUserResponse) Reset() {
+	*x = GetUserResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToggleBookmarkResponse) String() string {
+func (x *GetUserResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToggleBookmarkResponse) ProtoMessage() {}
+func (*GetUserResponse) ProtoMessage() {}
 
-func (x *ToggleBookmarkResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[26]
+func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1669,42 +4550,58 @@ func (x *ToggleBookmarkResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToggleBookmarkResponse.ProtoReflect.Descriptor instead.
-func (*ToggleBookmarkResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use GetUserResponse.ProtoReflect.Descriptor instead.
+func (*GetUserResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *ToggleBookmarkResponse) GetBookmarked() bool {
+func (x *GetUserResponse) GetUser() *User {
 	if x != nil {
-		return x.Bookmarked
+		return x.User
+	}
+	return nil
+}
+
+func (x *GetUserResponse) GetMoved() bool {
+	if x != nil {
+		return x.Moved
 	}
 	return false
 }
 
-type ListNotificationsRequest struct {
+// A user lifecycle event published by the auth service, which owns
+// accounts. The blog keeps its users table in step with these events.
+type UserEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`    // Increasing per publisher; older or repeated events are ignored
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // "user.created", "user.updated" or "user.deleted"
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Name          string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Bio           string                 `protobuf:"bytes,6,opt,name=bio,proto3" json:"bio,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,7,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	OccurredAt    int64                  `protobuf:"varint,8,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Username      string                 `protobuf:"bytes,9,opt,name=username,proto3" json:"username,omitempty"`
+	Version       int64                  `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"` // Profile version
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListNotificationsRequest) Reset() {
-	*x = ListNotificationsRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[27]
+func (x *UserEvent) Reset() {
+	*x = UserEvent{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[72]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListNotificationsRequest) String() string {
+func (x *UserEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListNotificationsRequest) ProtoMessage() {}
+func (*UserEvent) ProtoMessage() {}
 
-func (x *ListNotificationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[27]
+func (x *UserEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[72]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1715,56 +4612,103 @@ func (x *ListNotificationsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListNotificationsRequest.ProtoReflect.Descriptor instead.
-func (*ListNotificationsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use UserEvent.ProtoReflect.Descriptor instead.
+func (*UserEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{72}
 }
 
-func (x *ListNotificationsRequest) GetUserId() string {
+func (x *UserEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UserEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UserEvent) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-func (x *ListNotificationsRequest) GetPage() int32 {
+func (x *UserEvent) GetEmail() string {
 	if x != nil {
-		return x.Page
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UserEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UserEvent) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+func (x *UserEvent) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *UserEvent) GetOccurredAt() int64 {
+	if x != nil {
+		return x.OccurredAt
 	}
 	return 0
 }
 
-func (x *ListNotificationsRequest) GetLimit() int32 {
+func (x *UserEvent) GetUsername() string {
 	if x != nil {
-		return x.Limit
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserEvent) GetVersion() int64 {
+	if x != nil {
+		return x.Version
 	}
 	return 0
 }
 
-type ListNotificationsResponse struct {
+type ApplyUserEventRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Notifications []*Notification        `protobuf:"bytes,1,rep,name=notifications,proto3" json:"notifications,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
-	UnreadCount   int32                  `protobuf:"varint,3,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
+	Event         *UserEvent             `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListNotificationsResponse) Reset() {
-	*x = ListNotificationsResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[28]
+func (x *ApplyUserEventRequest) Reset() {
+	*x = ApplyUserEventRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[73]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListNotificationsResponse) String() string {
+func (x *ApplyUserEventRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListNotificationsResponse) ProtoMessage() {}
+func (*ApplyUserEventRequest) ProtoMessage() {}
 
-func (x *ListNotificationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[28]
+func (x *ApplyUserEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[73]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1775,55 +4719,40 @@ func (x *ListNotificationsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListNotificationsResponse.ProtoReflect.Descriptor instead.
-func (*ListNotificationsResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use ApplyUserEventRequest.ProtoReflect.Descriptor instead.
+func (*ApplyUserEventRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{73}
 }
 
-func (x *ListNotificationsResponse) GetNotifications() []*Notification {
+func (x *ApplyUserEventRequest) GetEvent() *UserEvent {
 	if x != nil {
-		return x.Notifications
+		return x.Event
 	}
 	return nil
 }
 
-func (x *ListNotificationsResponse) GetTotal() int32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-func (x *ListNotificationsResponse) GetUnreadCount() int32 {
-	if x != nil {
-		return x.UnreadCount
-	}
-	return 0
-}
-
-type MarkNotificationReadRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	NotificationId string                 `protobuf:"bytes,1,opt,name=notification_id,json=notificationId,proto3" json:"notification_id,omitempty"`
-	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type ApplyUserEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Applied       bool                   `protobuf:"varint,1,opt,name=applied,proto3" json:"applied,omitempty"` // False if the event was stale or a duplicate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MarkNotificationReadRequest) Reset() {
-	*x = MarkNotificationReadRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[29]
+func (x *ApplyUserEventResponse) Reset() {
+	*x = ApplyUserEventResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[74]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkNotificationReadRequest) String() string {
+func (x *ApplyUserEventResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkNotificationReadRequest) ProtoMessage() {}
+func (*ApplyUserEventResponse) ProtoMessage() {}
 
-func (x *MarkNotificationReadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[29]
+func (x *ApplyUserEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[74]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1834,47 +4763,42 @@ func (x *MarkNotificationReadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkNotificationReadRequest.ProtoReflect.Descriptor instead.
-func (*MarkNotificationReadRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{29}
-}
-
-func (x *MarkNotificationReadRequest) GetNotificationId() string {
-	if x != nil {
-		return x.NotificationId
-	}
-	return ""
+// Deprecated: Use ApplyUserEventResponse.ProtoReflect.Descriptor instead.
+func (*ApplyUserEventResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{74}
 }
 
-func (x *MarkNotificationReadRequest) GetUserId() string {
+func (x *ApplyUserEventResponse) GetApplied() bool {
 	if x != nil {
-		return x.UserId
+		return x.Applied
 	}
-	return ""
+	return false
 }
 
-type MarkNotificationReadResponse struct {
+// A file for the user's data export archive
+type ExportFile struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Path inside the archive, e.g. "posts/hello-world.md"
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MarkNotificationReadResponse) Reset() {
-	*x = MarkNotificationReadResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[30]
+func (x *ExportFile) Reset() {
+	*x = ExportFile{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[75]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkNotificationReadResponse) String() string {
+func (x *ExportFile) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkNotificationReadResponse) ProtoMessage() {}
+func (*ExportFile) ProtoMessage() {}
 
-func (x *MarkNotificationReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[30]
+func (x *ExportFile) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[75]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1885,41 +4809,48 @@ func (x *MarkNotificationReadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkNotificationReadResponse.ProtoReflect.Descriptor instead.
-func (*MarkNotificationReadResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{30}
+// Deprecated: Use ExportFile.ProtoReflect.Descriptor instead.
+func (*ExportFile) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{75}
 }
 
-func (x *MarkNotificationReadResponse) GetSuccess() bool {
+func (x *ExportFile) GetName() string {
 	if x != nil {
-		return x.Success
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-type GetUserRequest struct {
+func (x *ExportFile) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// Returns everything the blog service holds about the user
+type ExportUserDataRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CurrentUserId string                 `protobuf:"bytes,2,opt,name=current_user_id,json=currentUserId,proto3" json:"current_user_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUserRequest) Reset() {
-	*x = GetUserRequest{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[31]
+func (x *ExportUserDataRequest) Reset() {
+	*x = ExportUserDataRequest{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[76]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUserRequest) String() string {
+func (x *ExportUserDataRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUserRequest) ProtoMessage() {}
+func (*ExportUserDataRequest) ProtoMessage() {}
 
-func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[31]
+func (x *ExportUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[76]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1930,47 +4861,40 @@ func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
-func (*GetUserRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{31}
-}
-
-func (x *GetUserRequest) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
+// Deprecated: Use ExportUserDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{76}
 }
 
-func (x *GetUserRequest) GetCurrentUserId() string {
+func (x *ExportUserDataRequest) GetUserId() string {
 	if x != nil {
-		return x.CurrentUserId
+		return x.UserId
 	}
 	return ""
 }
 
-type GetUserResponse struct {
+type ExportUserDataResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Files         []*ExportFile          `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUserResponse) Reset() {
-	*x = GetUserResponse{}
-	mi := &file_pkg_proto_blog_proto_msgTypes[32]
+func (x *ExportUserDataResponse) Reset() {
+	*x = ExportUserDataResponse{}
+	mi := &file_pkg_proto_blog_proto_msgTypes[77]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUserResponse) String() string {
+func (x *ExportUserDataResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUserResponse) ProtoMessage() {}
+func (*ExportUserDataResponse) ProtoMessage() {}
 
-func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_blog_proto_msgTypes[32]
+func (x *ExportUserDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_blog_proto_msgTypes[77]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1981,14 +4905,14 @@ func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUserResponse.ProtoReflect.Descriptor instead.
-func (*GetUserResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use ExportUserDataResponse.ProtoReflect.Descriptor instead.
+func (*ExportUserDataResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_blog_proto_rawDescGZIP(), []int{77}
 }
 
-func (x *GetUserResponse) GetUser() *User {
+func (x *ExportUserDataResponse) GetFiles() []*ExportFile {
 	if x != nil {
-		return x.User
+		return x.Files
 	}
 	return nil
 }
@@ -1997,7 +4921,7 @@ var File_pkg_proto_blog_proto protoreflect.FileDescriptor
 
 const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\n" +
-	"\x14pkg/proto/blog.proto\x12\x04blog\"\xa8\x01\n" +
+	"\x14pkg/proto/blog.proto\x12\x04blog\x1a google/protobuf/field_mask.proto\"\xa8\x01\n" +
 	"\aComment\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\apost_id\x18\x02 \x01(\tR\x06postId\x12\x17\n" +
@@ -2019,11 +4943,12 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\x05limit\x18\x03 \x01(\x05R\x05limit\"W\n" +
 	"\x14ListCommentsResponse\x12)\n" +
 	"\bcomments\x18\x01 \x03(\v2\r.blog.CommentR\bcomments\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total\"N\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"p\n" +
 	"\x14DeleteCommentRequest\x12\x1d\n" +
 	"\n" +
 	"comment_id\x18\x01 \x01(\tR\tcommentId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"1\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\"1\n" +
 	"\x15DeleteCommentResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x9a\x02\n" +
 	"\fNotification\x12\x0e\n" +
@@ -2040,7 +4965,7 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x12\n" +
 	"\x04read\x18\n" +
-	" \x01(\bR\x04read\"\xa3\x02\n" +
+	" \x01(\bR\x04read\"\xd8\x04\n" +
 	"\x04Post\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
@@ -2055,13 +4980,28 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\vclaps_count\x18\t \x01(\x05R\n" +
 	"clapsCount\x12#\n" +
 	"\ris_bookmarked\x18\n" +
-	" \x01(\bR\fisBookmarked\"n\n" +
+	" \x01(\bR\fisBookmarked\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06status\x12!\n" +
+	"\fpublished_at\x18\f \x01(\tR\vpublishedAt\x12!\n" +
+	"\fscheduled_at\x18\r \x01(\tR\vscheduledAt\x12\x18\n" +
+	"\aversion\x18\x0e \x01(\x03R\aversion\x12!\n" +
+	"\fcontent_html\x18\x0f \x01(\tR\vcontentHtml\x12\x18\n" +
+	"\aexcerpt\x18\x10 \x01(\tR\aexcerpt\x12 \n" +
+	"\x03toc\x18\x11 \x03(\v2\x0e.blog.TocEntryR\x03toc\x12!\n" +
+	"\freading_time\x18\x12 \x01(\x05R\vreadingTime\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\x13 \x01(\x05R\twordCount\x12\x1a\n" +
+	"\bsubtitle\x18\x14 \x01(\tR\bsubtitle\"D\n" +
+	"\bTocEntry\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\x05R\x05level\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"n\n" +
 	"\x06Author\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
 	"\n" +
 	"avatar_url\x18\x03 \x01(\tR\tavatarUrl\x12!\n" +
-	"\fis_following\x18\x04 \x01(\bR\visFollowing\"\xd0\x01\n" +
+	"\fis_following\x18\x04 \x01(\bR\visFollowing\"\x86\x02\n" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -2071,7 +5011,10 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"avatar_url\x18\x05 \x01(\tR\tavatarUrl\x12\x1c\n" +
 	"\tfollowers\x18\x06 \x01(\x05R\tfollowers\x12\x1c\n" +
 	"\tfollowing\x18\a \x01(\x05R\tfollowing\x12!\n" +
-	"\fis_following\x18\b \x01(\bR\visFollowing\"\x99\x01\n" +
+	"\fis_following\x18\b \x01(\bR\visFollowing\x12\x1a\n" +
+	"\busername\x18\t \x01(\tR\busername\x12\x18\n" +
+	"\aversion\x18\n" +
+	" \x01(\x03R\aversion\"\x99\x01\n" +
 	"\x10ListPostsRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x10\n" +
@@ -2081,23 +5024,113 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\x11ListPostsResponse\x12 \n" +
 	"\x05posts\x18\x01 \x03(\v2\n" +
 	".blog.PostR\x05posts\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total\"\x95\x01\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"[\n" +
+	"\x12GetHomeFeedRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\\\n" +
+	"\x13GetHomeFeedResponse\x12$\n" +
+	"\x05items\x18\x01 \x03(\v2\x0e.blog.FeedItemR\x05items\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"B\n" +
+	"\bFeedItem\x12\x1e\n" +
+	"\x04post\x18\x01 \x01(\v2\n" +
+	".blog.PostR\x04post\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"}\n" +
+	"\x13ListTrendingRequest\x12\x16\n" +
+	"\x06window\x18\x01 \x01(\tR\x06window\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12&\n" +
+	"\x0fcurrent_user_id\x18\x04 \x01(\tR\rcurrentUserId\"a\n" +
+	"\x14ListTrendingResponse\x12(\n" +
+	"\x05posts\x18\x01 \x03(\v2\x12.blog.TrendingPostR\x05posts\x12\x1f\n" +
+	"\vcomputed_at\x18\x02 \x01(\tR\n" +
+	"computedAt\"D\n" +
+	"\fTrendingPost\x12\x1e\n" +
+	"\x04post\x18\x01 \x01(\v2\n" +
+	".blog.PostR\x04post\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\"\xa6\x01\n" +
+	"\x12SearchPostsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12&\n" +
+	"\x0fcurrent_user_id\x18\x06 \x01(\tR\rcurrentUserId\"\x98\x01\n" +
+	"\x13SearchPostsResponse\x12#\n" +
+	"\x04hits\x18\x01 \x03(\v2\x0f.blog.SearchHitR\x04hits\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x1f\n" +
+	"\x04tags\x18\x03 \x03(\v2\v.blog.FacetR\x04tags\x12%\n" +
+	"\aauthors\x18\x04 \x03(\v2\v.blog.FacetR\aauthors\"\x82\x01\n" +
+	"\tSearchHit\x12\x1e\n" +
+	"\x04post\x18\x01 \x01(\v2\n" +
+	".blog.PostR\x04post\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x01R\x04rank\x12'\n" +
+	"\x0ftitle_highlight\x18\x03 \x01(\tR\x0etitleHighlight\x12\x18\n" +
+	"\asnippet\x18\x04 \x01(\tR\asnippet\"I\n" +
+	"\x05Facet\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\"y\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12&\n" +
+	"\x0fcurrent_user_id\x18\x04 \x01(\tR\rcurrentUserId\">\n" +
+	"\x0eSearchResponse\x12,\n" +
+	"\aresults\x18\x01 \x03(\v2\x12.blog.SearchResultR\aresults\"\xa8\x01\n" +
+	"\fSearchResult\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12%\n" +
+	"\x04user\x18\x03 \x01(\v2\x11.blog.UserSummaryR\x04user\x12\"\n" +
+	"\x03tag\x18\x04 \x01(\v2\x10.blog.TagSummaryR\x03tag\x12#\n" +
+	"\x04post\x18\x05 \x01(\v2\x0f.blog.SearchHitR\x04post\"~\n" +
+	"\vUserSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x04 \x01(\tR\tavatarUrl\x12\x10\n" +
+	"\x03bio\x18\x05 \x01(\tR\x03bio\"?\n" +
+	"\n" +
+	"TagSummary\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"post_count\x18\x02 \x01(\x05R\tpostCount\"l\n" +
+	"\x14SuggestSearchRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12&\n" +
+	"\x0fcurrent_user_id\x18\x03 \x01(\tR\rcurrentUserId\"K\n" +
+	"\x15SuggestSearchResponse\x122\n" +
+	"\vsuggestions\x18\x01 \x03(\v2\x10.blog.SuggestionR\vsuggestions\"\x87\x01\n" +
+	"\n" +
+	"Suggestion\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x12\x1a\n" +
+	"\busername\x18\x04 \x01(\tR\busername\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x05 \x01(\tR\tavatarUrl\"\xec\x01\n" +
 	"\x11CreatePostRequest\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\x12\x1b\n" +
 	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x12\n" +
 	"\x04tags\x18\x04 \x03(\tR\x04tags\x12\x1f\n" +
 	"\vcover_image\x18\x05 \x01(\tR\n" +
-	"coverImage\"4\n" +
+	"coverImage\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12!\n" +
+	"\fscheduled_at\x18\a \x01(\tR\vscheduledAt\x12\x1a\n" +
+	"\bsubtitle\x18\b \x01(\tR\bsubtitle\"4\n" +
 	"\x12CreatePostResponse\x12\x1e\n" +
 	"\x04post\x18\x01 \x01(\v2\n" +
-	".blog.PostR\x04post\"Q\n" +
+	".blog.PostR\x04post\"n\n" +
 	"\x0eGetPostRequest\x12\x17\n" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12&\n" +
-	"\x0fcurrent_user_id\x18\x02 \x01(\tR\rcurrentUserId\"1\n" +
+	"\x0fcurrent_user_id\x18\x02 \x01(\tR\rcurrentUserId\x12\x1b\n" +
+	"\tmark_read\x18\x03 \x01(\bR\bmarkRead\"1\n" +
 	"\x0fGetPostResponse\x12\x1e\n" +
 	"\x04post\x18\x01 \x01(\v2\n" +
-	".blog.PostR\x04post\"\xaa\x01\n" +
+	".blog.PostR\x04post\"\xfa\x02\n" +
 	"\x11UpdatePostRequest\x12\x17\n" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
@@ -2105,13 +5138,96 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\acontent\x18\x04 \x01(\tR\acontent\x12\x12\n" +
 	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x1f\n" +
 	"\vcover_image\x18\x06 \x01(\tR\n" +
-	"coverImage\"4\n" +
+	"coverImage\x12 \n" +
+	"\vpermissions\x18\a \x03(\tR\vpermissions\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12!\n" +
+	"\fscheduled_at\x18\t \x01(\tR\vscheduledAt\x12\x18\n" +
+	"\aversion\x18\n" +
+	" \x01(\x03R\aversion\x12;\n" +
+	"\vupdate_mask\x18\v \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x1a\n" +
+	"\bsubtitle\x18\f \x01(\tR\bsubtitle\"4\n" +
 	"\x12UpdatePostResponse\x12\x1e\n" +
 	"\x04post\x18\x01 \x01(\v2\n" +
-	".blog.PostR\x04post\"E\n" +
+	".blog.PostR\x04post\"X\n" +
+	"\x13ListMyDraftsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"N\n" +
+	"\x14ListMyDraftsResponse\x12 \n" +
+	"\x05posts\x18\x01 \x03(\v2\n" +
+	".blog.PostR\x05posts\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xa5\x01\n" +
+	"\x12PublishPostRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\fscheduled_at\x18\x03 \x01(\tR\vscheduledAt\x12 \n" +
+	"\vpermissions\x18\x04 \x03(\tR\vpermissions\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\"5\n" +
+	"\x13PublishPostResponse\x12\x1e\n" +
+	"\x04post\x18\x01 \x01(\v2\n" +
+	".blog.PostR\x04post\"\x84\x01\n" +
+	"\x14UnpublishPostRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\"7\n" +
+	"\x15UnpublishPostResponse\x12\x1e\n" +
+	"\x04post\x18\x01 \x01(\v2\n" +
+	".blog.PostR\x04post\"\xf6\x01\n" +
+	"\bRevision\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x17\n" +
+	"\apost_id\x18\x02 \x01(\tR\x06postId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1f\n" +
+	"\vcover_image\x18\x05 \x01(\tR\n" +
+	"coverImage\x12$\n" +
+	"\x06editor\x18\x06 \x01(\v2\f.blog.AuthorR\x06editor\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\x12#\n" +
+	"\rrestored_from\x18\b \x01(\x05R\frestoredFrom\"j\n" +
+	"\x14ListRevisionsRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\"E\n" +
+	"\x15ListRevisionsResponse\x12,\n" +
+	"\trevisions\x18\x01 \x03(\v2\x0e.blog.RevisionR\trevisions\"\x80\x01\n" +
+	"\x12GetRevisionRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x04 \x03(\tR\vpermissions\"A\n" +
+	"\x13GetRevisionResponse\x12*\n" +
+	"\brevision\x18\x01 \x01(\v2\x0e.blog.RevisionR\brevision\"\xb0\x01\n" +
+	"\x14DiffRevisionsRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x05R\x02to\x12 \n" +
+	"\vgranularity\x18\x04 \x01(\tR\vgranularity\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x06 \x03(\tR\vpermissions\"/\n" +
+	"\tDiffChunk\x12\x0e\n" +
+	"\x02op\x18\x01 \x01(\tR\x02op\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"\xb9\x01\n" +
+	"\x15DiffRevisionsResponse\x12%\n" +
+	"\x05title\x18\x01 \x03(\v2\x0f.blog.DiffChunkR\x05title\x12)\n" +
+	"\acontent\x18\x02 \x03(\v2\x0f.blog.DiffChunkR\acontent\x12(\n" +
+	"\x10from_cover_image\x18\x03 \x01(\tR\x0efromCoverImage\x12$\n" +
+	"\x0eto_cover_image\x18\x04 \x01(\tR\ftoCoverImage\"\x9e\x01\n" +
+	"\x16RestoreRevisionRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x04 \x03(\tR\vpermissions\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\"e\n" +
+	"\x17RestoreRevisionResponse\x12\x1e\n" +
+	"\x04post\x18\x01 \x01(\v2\n" +
+	".blog.PostR\x04post\x12*\n" +
+	"\brevision\x18\x02 \x01(\v2\x0e.blog.RevisionR\brevision\"g\n" +
 	"\x11DeletePostRequest\x12\x17\n" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\".\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\".\n" +
 	"\x12DeletePostResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"E\n" +
 	"\x11ToggleClapRequest\x12\x17\n" +
@@ -2127,6 +5243,18 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\vfollowee_id\x18\x02 \x01(\tR\n" +
 	"followeeId\"4\n" +
 	"\x14ToggleFollowResponse\x12\x1c\n" +
+	"\tfollowing\x18\x01 \x01(\bR\tfollowing\"R\n" +
+	"\x12ToggleBlockRequest\x12\x1d\n" +
+	"\n" +
+	"blocker_id\x18\x01 \x01(\tR\tblockerId\x12\x1d\n" +
+	"\n" +
+	"blocked_id\x18\x02 \x01(\tR\tblockedId\"/\n" +
+	"\x13ToggleBlockResponse\x12\x18\n" +
+	"\ablocked\x18\x01 \x01(\bR\ablocked\"C\n" +
+	"\x16ToggleTagFollowRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\"7\n" +
+	"\x17ToggleTagFollowResponse\x12\x1c\n" +
 	"\tfollowing\x18\x01 \x01(\bR\tfollowing\"I\n" +
 	"\x15ToggleBookmarkRequest\x12\x17\n" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
@@ -2150,29 +5278,72 @@ const file_pkg_proto_blog_proto_rawDesc = "" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"H\n" +
 	"\x0eGetUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
-	"\x0fcurrent_user_id\x18\x02 \x01(\tR\rcurrentUserId\"1\n" +
+	"\x0fcurrent_user_id\x18\x02 \x01(\tR\rcurrentUserId\"G\n" +
 	"\x0fGetUserResponse\x12\x1e\n" +
 	"\x04user\x18\x01 \x01(\v2\n" +
-	".blog.UserR\x04user2\xff\a\n" +
+	".blog.UserR\x04user\x12\x14\n" +
+	"\x05moved\x18\x02 \x01(\bR\x05moved\"\xfa\x01\n" +
+	"\tUserEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x10\n" +
+	"\x03bio\x18\x06 \x01(\tR\x03bio\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\a \x01(\tR\tavatarUrl\x12\x1f\n" +
+	"\voccurred_at\x18\b \x01(\x03R\n" +
+	"occurredAt\x12\x1a\n" +
+	"\busername\x18\t \x01(\tR\busername\x12\x18\n" +
+	"\aversion\x18\n" +
+	" \x01(\x03R\aversion\">\n" +
+	"\x15ApplyUserEventRequest\x12%\n" +
+	"\x05event\x18\x01 \x01(\v2\x0f.blog.UserEventR\x05event\"2\n" +
+	"\x16ApplyUserEventResponse\x12\x18\n" +
+	"\aapplied\x18\x01 \x01(\bR\aapplied\":\n" +
+	"\n" +
+	"ExportFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"0\n" +
+	"\x15ExportUserDataRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"@\n" +
+	"\x16ExportUserDataResponse\x12&\n" +
+	"\x05files\x18\x01 \x03(\v2\x10.blog.ExportFileR\x05files2\x98\x11\n" +
 	"\vBlogService\x12>\n" +
-	"\tListPosts\x12\x16.blog.ListPostsRequest\x1a\x17.blog.ListPostsResponse\"\x00\x128\n" +
-	"\aGetPost\x12\x14.blog.GetPostRequest\x1a\x15.blog.GetPostResponse\"\x00\x12A\n" +
+	"\tListPosts\x12\x16.blog.ListPostsRequest\x1a\x17.blog.ListPostsResponse\"\x00\x12D\n" +
+	"\vGetHomeFeed\x12\x18.blog.GetHomeFeedRequest\x1a\x19.blog.GetHomeFeedResponse\"\x00\x12G\n" +
+	"\fListTrending\x12\x19.blog.ListTrendingRequest\x1a\x1a.blog.ListTrendingResponse\"\x00\x128\n" +
+	"\aGetPost\x12\x14.blog.GetPostRequest\x1a\x15.blog.GetPostResponse\"\x00\x12D\n" +
+	"\vSearchPosts\x12\x18.blog.SearchPostsRequest\x1a\x19.blog.SearchPostsResponse\"\x00\x125\n" +
+	"\x06Search\x12\x13.blog.SearchRequest\x1a\x14.blog.SearchResponse\"\x00\x12J\n" +
+	"\rSuggestSearch\x12\x1a.blog.SuggestSearchRequest\x1a\x1b.blog.SuggestSearchResponse\"\x00\x12A\n" +
 	"\n" +
 	"CreatePost\x12\x17.blog.CreatePostRequest\x1a\x18.blog.CreatePostResponse\"\x00\x12A\n" +
 	"\n" +
 	"UpdatePost\x12\x17.blog.UpdatePostRequest\x1a\x18.blog.UpdatePostResponse\"\x00\x12A\n" +
 	"\n" +
-	"DeletePost\x12\x17.blog.DeletePostRequest\x1a\x18.blog.DeletePostResponse\"\x00\x128\n" +
+	"DeletePost\x12\x17.blog.DeletePostRequest\x1a\x18.blog.DeletePostResponse\"\x00\x12G\n" +
+	"\fListMyDrafts\x12\x19.blog.ListMyDraftsRequest\x1a\x1a.blog.ListMyDraftsResponse\"\x00\x12D\n" +
+	"\vPublishPost\x12\x18.blog.PublishPostRequest\x1a\x19.blog.PublishPostResponse\"\x00\x12J\n" +
+	"\rUnpublishPost\x12\x1a.blog.UnpublishPostRequest\x1a\x1b.blog.UnpublishPostResponse\"\x00\x12J\n" +
+	"\rListRevisions\x12\x1a.blog.ListRevisionsRequest\x1a\x1b.blog.ListRevisionsResponse\"\x00\x12D\n" +
+	"\vGetRevision\x12\x18.blog.GetRevisionRequest\x1a\x19.blog.GetRevisionResponse\"\x00\x12J\n" +
+	"\rDiffRevisions\x12\x1a.blog.DiffRevisionsRequest\x1a\x1b.blog.DiffRevisionsResponse\"\x00\x12P\n" +
+	"\x0fRestoreRevision\x12\x1c.blog.RestoreRevisionRequest\x1a\x1d.blog.RestoreRevisionResponse\"\x00\x128\n" +
 	"\aGetUser\x12\x14.blog.GetUserRequest\x1a\x15.blog.GetUserResponse\"\x00\x12A\n" +
 	"\n" +
 	"ToggleClap\x12\x17.blog.ToggleClapRequest\x1a\x18.blog.ToggleClapResponse\"\x00\x12G\n" +
-	"\fToggleFollow\x12\x19.blog.ToggleFollowRequest\x1a\x1a.blog.ToggleFollowResponse\"\x00\x12M\n" +
+	"\fToggleFollow\x12\x19.blog.ToggleFollowRequest\x1a\x1a.blog.ToggleFollowResponse\"\x00\x12D\n" +
+	"\vToggleBlock\x12\x18.blog.ToggleBlockRequest\x1a\x19.blog.ToggleBlockResponse\"\x00\x12P\n" +
+	"\x0fToggleTagFollow\x12\x1c.blog.ToggleTagFollowRequest\x1a\x1d.blog.ToggleTagFollowResponse\"\x00\x12M\n" +
 	"\x0eToggleBookmark\x12\x1b.blog.ToggleBookmarkRequest\x1a\x1c.blog.ToggleBookmarkResponse\"\x00\x12V\n" +
 	"\x11ListNotifications\x12\x1e.blog.ListNotificationsRequest\x1a\x1f.blog.ListNotificationsResponse\"\x00\x12_\n" +
 	"\x14MarkNotificationRead\x12!.blog.MarkNotificationReadRequest\x1a\".blog.MarkNotificationReadResponse\"\x00\x12J\n" +
 	"\rCreateComment\x12\x1a.blog.CreateCommentRequest\x1a\x1b.blog.CreateCommentResponse\"\x00\x12G\n" +
 	"\fListComments\x12\x19.blog.ListCommentsRequest\x1a\x1a.blog.ListCommentsResponse\"\x00\x12J\n" +
-	"\rDeleteComment\x12\x1a.blog.DeleteCommentRequest\x1a\x1b.blog.DeleteCommentResponse\"\x00B\x18Z\x16project/pkg/proto/blogb\x06proto3"
+	"\rDeleteComment\x12\x1a.blog.DeleteCommentRequest\x1a\x1b.blog.DeleteCommentResponse\"\x00\x12M\n" +
+	"\x0eApplyUserEvent\x12\x1b.blog.ApplyUserEventRequest\x1a\x1c.blog.ApplyUserEventResponse\"\x00\x12M\n" +
+	"\x0eExportUserData\x12\x1b.blog.ExportUserDataRequest\x1a\x1c.blog.ExportUserDataResponse\"\x00B\x18Z\x16project/pkg/proto/blogb\x06proto3"
 
 var (
 	file_pkg_proto_blog_proto_rawDescOnce sync.Once
@@ -2186,7 +5357,7 @@ func file_pkg_proto_blog_proto_rawDescGZIP() []byte {
 	return file_pkg_proto_blog_proto_rawDescData
 }
 
-var file_pkg_proto_blog_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_pkg_proto_blog_proto_msgTypes = make([]protoimpl.MessageInfo, 78)
 var file_pkg_proto_blog_proto_goTypes = []any{
 	(*Comment)(nil),                      // 0: blog.Comment
 	(*CreateCommentRequest)(nil),         // 1: blog.CreateCommentRequest
@@ -2197,75 +5368,180 @@ var file_pkg_proto_blog_proto_goTypes = []any{
 	(*DeleteCommentResponse)(nil),        // 6: blog.DeleteCommentResponse
 	(*Notification)(nil),                 // 7: blog.Notification
 	(*Post)(nil),                         // 8: blog.Post
-	(*Author)(nil),                       // 9: blog.Author
-	(*User)(nil),                         // 10: blog.User
-	(*ListPostsRequest)(nil),             // 11: blog.ListPostsRequest
-	(*ListPostsResponse)(nil),            // 12: blog.ListPostsResponse
-	(*CreatePostRequest)(nil),            // 13: blog.CreatePostRequest
-	(*CreatePostResponse)(nil),           // 14: blog.CreatePostResponse
-	(*GetPostRequest)(nil),               // 15: blog.GetPostRequest
-	(*GetPostResponse)(nil),              // 16: blog.GetPostResponse
-	(*UpdatePostRequest)(nil),            // 17: blog.UpdatePostRequest
-	(*UpdatePostResponse)(nil),           // 18: blog.UpdatePostResponse
-	(*DeletePostRequest)(nil),            // 19: blog.DeletePostRequest
-	(*DeletePostResponse)(nil),           // 20: blog.DeletePostResponse
-	(*ToggleClapRequest)(nil),            // 21: blog.ToggleClapRequest
-	(*ToggleClapResponse)(nil),           // 22: blog.ToggleClapResponse
-	(*ToggleFollowRequest)(nil),          // 23: blog.ToggleFollowRequest
-	(*ToggleFollowResponse)(nil),         // 24: blog.ToggleFollowResponse
-	(*ToggleBookmarkRequest)(nil),        // 25: blog.ToggleBookmarkRequest
-	(*ToggleBookmarkResponse)(nil),       // 26: blog.ToggleBookmarkResponse
-	(*ListNotificationsRequest)(nil),     // 27: blog.ListNotificationsRequest
-	(*ListNotificationsResponse)(nil),    // 28: blog.ListNotificationsResponse
-	(*MarkNotificationReadRequest)(nil),  // 29: blog.MarkNotificationReadRequest
-	(*MarkNotificationReadResponse)(nil), // 30: blog.MarkNotificationReadResponse
-	(*GetUserRequest)(nil),               // 31: blog.GetUserRequest
-	(*GetUserResponse)(nil),              // 32: blog.GetUserResponse
+	(*TocEntry)(nil),                     // 9: blog.TocEntry
+	(*Author)(nil),                       // 10: blog.Author
+	(*User)(nil),                         // 11: blog.User
+	(*ListPostsRequest)(nil),             // 12: blog.ListPostsRequest
+	(*ListPostsResponse)(nil),            // 13: blog.ListPostsResponse
+	(*GetHomeFeedRequest)(nil),           // 14: blog.GetHomeFeedRequest
+	(*GetHomeFeedResponse)(nil),          // 15: blog.GetHomeFeedResponse
+	(*FeedItem)(nil),                     // 16: blog.FeedItem
+	(*ListTrendingRequest)(nil),          // 17: blog.ListTrendingRequest
+	(*ListTrendingResponse)(nil),         // 18: blog.ListTrendingResponse
+	(*TrendingPost)(nil),                 // 19: blog.TrendingPost
+	(*SearchPostsRequest)(nil),           // 20: blog.SearchPostsRequest
+	(*SearchPostsResponse)(nil),          // 21: blog.SearchPostsResponse
+	(*SearchHit)(nil),                    // 22: blog.SearchHit
+	(*Facet)(nil),                        // 23: blog.Facet
+	(*SearchRequest)(nil),                // 24: blog.SearchRequest
+	(*SearchResponse)(nil),               // 25: blog.SearchResponse
+	(*SearchResult)(nil),                 // 26: blog.SearchResult
+	(*UserSummary)(nil),                  // 27: blog.UserSummary
+	(*TagSummary)(nil),                   // 28: blog.TagSummary
+	(*SuggestSearchRequest)(nil),         // 29: blog.SuggestSearchRequest
+	(*SuggestSearchResponse)(nil),        // 30: blog.SuggestSearchResponse
+	(*Suggestion)(nil),                   // 31: blog.Suggestion
+	(*CreatePostRequest)(nil),            // 32: blog.CreatePostRequest
+	(*CreatePostResponse)(nil),           // 33: blog.CreatePostResponse
+	(*GetPostRequest)(nil),               // 34: blog.GetPostRequest
+	(*GetPostResponse)(nil),              // 35: blog.GetPostResponse
+	(*UpdatePostRequest)(nil),            // 36: blog.UpdatePostRequest
+	(*UpdatePostResponse)(nil),           // 37: blog.UpdatePostResponse
+	(*ListMyDraftsRequest)(nil),          // 38: blog.ListMyDraftsRequest
+	(*ListMyDraftsResponse)(nil),         // 39: blog.ListMyDraftsResponse
+	(*PublishPostRequest)(nil),           // 40: blog.PublishPostRequest
+	(*PublishPostResponse)(nil),          // 41: blog.PublishPostResponse
+	(*UnpublishPostRequest)(nil),         // 42: blog.UnpublishPostRequest
+	(*UnpublishPostResponse)(nil),        // 43: blog.UnpublishPostResponse
+	(*Revision)(nil),                     // 44: blog.Revision
+	(*ListRevisionsRequest)(nil),         // 45: blog.ListRevisionsRequest
+	(*ListRevisionsResponse)(nil),        // 46: blog.ListRevisionsResponse
+	(*GetRevisionRequest)(nil),           // 47: blog.GetRevisionRequest
+	(*GetRevisionResponse)(nil),          // 48: blog.GetRevisionResponse
+	(*DiffRevisionsRequest)(nil),         // 49: blog.DiffRevisionsRequest
+	(*DiffChunk)(nil),                    // 50: blog.DiffChunk
+	(*DiffRevisionsResponse)(nil),        // 51: blog.DiffRevisionsResponse
+	(*RestoreRevisionRequest)(nil),       // 52: blog.RestoreRevisionRequest
+	(*RestoreRevisionResponse)(nil),      // 53: blog.RestoreRevisionResponse
+	(*DeletePostRequest)(nil),            // 54: blog.DeletePostRequest
+	(*DeletePostResponse)(nil),           // 55: blog.DeletePostResponse
+	(*ToggleClapRequest)(nil),            // 56: blog.ToggleClapRequest
+	(*ToggleClapResponse)(nil),           // 57: blog.ToggleClapResponse
+	(*ToggleFollowRequest)(nil),          // 58: blog.ToggleFollowRequest
+	(*ToggleFollowResponse)(nil),         // 59: blog.ToggleFollowResponse
+	(*ToggleBlockRequest)(nil),           // 60: blog.ToggleBlockRequest
+	(*ToggleBlockResponse)(nil),          // 61: blog.ToggleBlockResponse
+	(*ToggleTagFollowRequest)(nil),       // 62: blog.ToggleTagFollowRequest
+	(*ToggleTagFollowResponse)(nil),      // 63: blog.ToggleTagFollowResponse
+	(*ToggleBookmarkRequest)(nil),        // 64: blog.ToggleBookmarkRequest
+	(*ToggleBookmarkResponse)(nil),       // 65: blog.ToggleBookmarkResponse
+	(*ListNotificationsRequest)(nil),     // 66: blog.ListNotificationsRequest
+	(*ListNotificationsResponse)(nil),    // 67: blog.ListNotificationsResponse
+	(*MarkNotificationReadRequest)(nil),  // 68: blog.MarkNotificationReadRequest
+	(*MarkNotificationReadResponse)(nil), // 69: blog.MarkNotificationReadResponse
+	(*GetUserRequest)(nil),               // 70: blog.GetUserRequest
+	(*GetUserResponse)(nil),              // 71: blog.GetUserResponse
+	(*UserEvent)(nil),                    // 72: blog.UserEvent
+	(*ApplyUserEventRequest)(nil),        // 73: blog.ApplyUserEventRequest
+	(*ApplyUserEventResponse)(nil),       // 74: blog.ApplyUserEventResponse
+	(*ExportFile)(nil),                   // 75: blog.ExportFile
+	(*ExportUserDataRequest)(nil),        // 76: blog.ExportUserDataRequest
+	(*ExportUserDataResponse)(nil),       // 77: blog.ExportUserDataResponse
+	(*fieldmaskpb.FieldMask)(nil),        // 78: google.protobuf.FieldMask
 }
 var file_pkg_proto_blog_proto_depIdxs = []int32{
-	10, // 0: blog.Comment.author:type_name -> blog.User
+	11, // 0: blog.Comment.author:type_name -> blog.User
 	0,  // 1: blog.CreateCommentResponse.comment:type_name -> blog.Comment
 	0,  // 2: blog.ListCommentsResponse.comments:type_name -> blog.Comment
-	9,  // 3: blog.Post.author:type_name -> blog.Author
-	8,  // 4: blog.ListPostsResponse.posts:type_name -> blog.Post
-	8,  // 5: blog.CreatePostResponse.post:type_name -> blog.Post
-	8,  // 6: blog.GetPostResponse.post:type_name -> blog.Post
-	8,  // 7: blog.UpdatePostResponse.post:type_name -> blog.Post
-	7,  // 8: blog.ListNotificationsResponse.notifications:type_name -> blog.Notification
-	10, // 9: blog.GetUserResponse.user:type_name -> blog.User
-	11, // 10: blog.BlogService.ListPosts:input_type -> blog.ListPostsRequest
-	15, // 11: blog.BlogService.GetPost:input_type -> blog.GetPostRequest
-	13, // 12: blog.BlogService.CreatePost:input_type -> blog.CreatePostRequest
-	17, // 13: blog.BlogService.UpdatePost:input_type -> blog.UpdatePostRequest
-	19, // 14: blog.BlogService.DeletePost:input_type -> blog.DeletePostRequest
-	31, // 15: blog.BlogService.GetUser:input_type -> blog.GetUserRequest
-	21, // 16: blog.BlogService.ToggleClap:input_type -> blog.ToggleClapRequest
-	23, // 17: blog.BlogService.ToggleFollow:input_type -> blog.ToggleFollowRequest
-	25, // 18: blog.BlogService.ToggleBookmark:input_type -> blog.ToggleBookmarkRequest
-	27, // 19: blog.BlogService.ListNotifications:input_type -> blog.ListNotificationsRequest
-	29, // 20: blog.BlogService.MarkNotificationRead:input_type -> blog.MarkNotificationReadRequest
-	1,  // 21: blog.BlogService.CreateComment:input_type -> blog.CreateCommentRequest
-	3,  // 22: blog.BlogService.ListComments:input_type -> blog.ListCommentsRequest
-	5,  // 23: blog.BlogService.DeleteComment:input_type -> blog.DeleteCommentRequest
-	12, // 24: blog.BlogService.ListPosts:output_type -> blog.ListPostsResponse
-	16, // 25: blog.BlogService.GetPost:output_type -> blog.GetPostResponse
-	14, // 26: blog.BlogService.CreatePost:output_type -> blog.CreatePostResponse
-	18, // 27: blog.BlogService.UpdatePost:output_type -> blog.UpdatePostResponse
-	20, // 28: blog.BlogService.DeletePost:output_type -> blog.DeletePostResponse
-	32, // 29: blog.BlogService.GetUser:output_type -> blog.GetUserResponse
-	22, // 30: blog.BlogService.ToggleClap:output_type -> blog.ToggleClapResponse
-	24, // 31: blog.BlogService.ToggleFollow:output_type -> blog.ToggleFollowResponse
-	26, // 32: blog.BlogService.ToggleBookmark:output_type -> blog.ToggleBookmarkResponse
-	28, // 33: blog.BlogService.ListNotifications:output_type -> blog.ListNotificationsResponse
-	30, // 34: blog.BlogService.MarkNotificationRead:output_type -> blog.MarkNotificationReadResponse
-	2,  // 35: blog.BlogService.CreateComment:output_type -> blog.CreateCommentResponse
-	4,  // 36: blog.BlogService.ListComments:output_type -> blog.ListCommentsResponse
-	6,  // 37: blog.BlogService.DeleteComment:output_type -> blog.DeleteCommentResponse
-	24, // [24:38] is the sub-list for method output_type
-	10, // [10:24] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	10, // 3: blog.Post.author:type_name -> blog.Author
+	9,  // 4: blog.Post.toc:type_name -> blog.TocEntry
+	8,  // 5: blog.ListPostsResponse.posts:type_name -> blog.Post
+	16, // 6: blog.GetHomeFeedResponse.items:type_name -> blog.FeedItem
+	8,  // 7: blog.FeedItem.post:type_name -> blog.Post
+	19, // 8: blog.ListTrendingResponse.posts:type_name -> blog.TrendingPost
+	8,  // 9: blog.TrendingPost.post:type_name -> blog.Post
+	22, // 10: blog.SearchPostsResponse.hits:type_name -> blog.SearchHit
+	23, // 11: blog.SearchPostsResponse.tags:type_name -> blog.Facet
+	23, // 12: blog.SearchPostsResponse.authors:type_name -> blog.Facet
+	8,  // 13: blog.SearchHit.post:type_name -> blog.Post
+	26, // 14: blog.SearchResponse.results:type_name -> blog.SearchResult
+	27, // 15: blog.SearchResult.user:type_name -> blog.UserSummary
+	28, // 16: blog.SearchResult.tag:type_name -> blog.TagSummary
+	22, // 17: blog.SearchResult.post:type_name -> blog.SearchHit
+	31, // 18: blog.SuggestSearchResponse.suggestions:type_name -> blog.Suggestion
+	8,  // 19: blog.CreatePostResponse.post:type_name -> blog.Post
+	8,  // 20: blog.GetPostResponse.post:type_name -> blog.Post
+	78, // 21: blog.UpdatePostRequest.update_mask:type_name -> google.protobuf.FieldMask
+	8,  // 22: blog.UpdatePostResponse.post:type_name -> blog.Post
+	8,  // 23: blog.ListMyDraftsResponse.posts:type_name -> blog.Post
+	8,  // 24: blog.PublishPostResponse.post:type_name -> blog.Post
+	8,  // 25: blog.UnpublishPostResponse.post:type_name -> blog.Post
+	10, // 26: blog.Revision.editor:type_name -> blog.Author
+	44, // 27: blog.ListRevisionsResponse.revisions:type_name -> blog.Revision
+	44, // 28: blog.GetRevisionResponse.revision:type_name -> blog.Revision
+	50, // 29: blog.DiffRevisionsResponse.title:type_name -> blog.DiffChunk
+	50, // 30: blog.DiffRevisionsResponse.content:type_name -> blog.DiffChunk
+	8,  // 31: blog.RestoreRevisionResponse.post:type_name -> blog.Post
+	44, // 32: blog.RestoreRevisionResponse.revision:type_name -> blog.Revision
+	7,  // 33: blog.ListNotificationsResponse.notifications:type_name -> blog.Notification
+	11, // 34: blog.GetUserResponse.user:type_name -> blog.User
+	72, // 35: blog.ApplyUserEventRequest.event:type_name -> blog.UserEvent
+	75, // 36: blog.ExportUserDataResponse.files:type_name -> blog.ExportFile
+	12, // 37: blog.BlogService.ListPosts:input_type -> blog.ListPostsRequest
+	14, // 38: blog.BlogService.GetHomeFeed:input_type -> blog.GetHomeFeedRequest
+	17, // 39: blog.BlogService.ListTrending:input_type -> blog.ListTrendingRequest
+	34, // 40: blog.BlogService.GetPost:input_type -> blog.GetPostRequest
+	20, // 41: blog.BlogService.SearchPosts:input_type -> blog.SearchPostsRequest
+	24, // 42: blog.BlogService.Search:input_type -> blog.SearchRequest
+	29, // 43: blog.BlogService.SuggestSearch:input_type -> blog.SuggestSearchRequest
+	32, // 44: blog.BlogService.CreatePost:input_type -> blog.CreatePostRequest
+	36, // 45: blog.BlogService.UpdatePost:input_type -> blog.UpdatePostRequest
+	54, // 46: blog.BlogService.DeletePost:input_type -> blog.DeletePostRequest
+	38, // 47: blog.BlogService.ListMyDrafts:input_type -> blog.ListMyDraftsRequest
+	40, // 48: blog.BlogService.PublishPost:input_type -> blog.PublishPostRequest
+	42, // 49: blog.BlogService.UnpublishPost:input_type -> blog.UnpublishPostRequest
+	45, // 50: blog.BlogService.ListRevisions:input_type -> blog.ListRevisionsRequest
+	47, // 51: blog.BlogService.GetRevision:input_type -> blog.GetRevisionRequest
+	49, // 52: blog.BlogService.DiffRevisions:input_type -> blog.DiffRevisionsRequest
+	52, // 53: blog.BlogService.RestoreRevision:input_type -> blog.RestoreRevisionRequest
+	70, // 54: blog.BlogService.GetUser:input_type -> blog.GetUserRequest
+	56, // 55: blog.BlogService.ToggleClap:input_type -> blog.ToggleClapRequest
+	58, // 56: blog.BlogService.ToggleFollow:input_type -> blog.ToggleFollowRequest
+	60, // 57: blog.BlogService.ToggleBlock:input_type -> blog.ToggleBlockRequest
+	62, // 58: blog.BlogService.ToggleTagFollow:input_type -> blog.ToggleTagFollowRequest
+	64, // 59: blog.BlogService.ToggleBookmark:input_type -> blog.ToggleBookmarkRequest
+	66, // 60: blog.BlogService.ListNotifications:input_type -> blog.ListNotificationsRequest
+	68, // 61: blog.BlogService.MarkNotificationRead:input_type -> blog.MarkNotificationReadRequest
+	1,  // 62: blog.BlogService.CreateComment:input_type -> blog.CreateCommentRequest
+	3,  // 63: blog.BlogService.ListComments:input_type -> blog.ListCommentsRequest
+	5,  // 64: blog.BlogService.DeleteComment:input_type -> blog.DeleteCommentRequest
+	73, // 65: blog.BlogService.ApplyUserEvent:input_type -> blog.ApplyUserEventRequest
+	76, // 66: blog.BlogService.ExportUserData:input_type -> blog.ExportUserDataRequest
+	13, // 67: blog.BlogService.ListPosts:output_type -> blog.ListPostsResponse
+	15, // 68: blog.BlogService.GetHomeFeed:output_type -> blog.GetHomeFeedResponse
+	18, // 69: blog.BlogService.ListTrending:output_type -> blog.ListTrendingResponse
+	35, // 70: blog.BlogService.GetPost:output_type -> blog.GetPostResponse
+	21, // 71: blog.BlogService.SearchPosts:output_type -> blog.SearchPostsResponse
+	25, // 72: blog.BlogService.Search:output_type -> blog.SearchResponse
+	30, // 73: blog.BlogService.SuggestSearch:output_type -> blog.SuggestSearchResponse
+	33, // 74: blog.BlogService.CreatePost:output_type -> blog.CreatePostResponse
+	37, // 75: blog.BlogService.UpdatePost:output_type -> blog.UpdatePostResponse
+	55, // 76: blog.BlogService.DeletePost:output_type -> blog.DeletePostResponse
+	39, // 77: blog.BlogService.ListMyDrafts:output_type -> blog.ListMyDraftsResponse
+	41, // 78: blog.BlogService.PublishPost:output_type -> blog.PublishPostResponse
+	43, // 79: blog.BlogService.UnpublishPost:output_type -> blog.UnpublishPostResponse
+	46, // 80: blog.BlogService.ListRevisions:output_type -> blog.ListRevisionsResponse
+	48, // 81: blog.BlogService.GetRevision:output_type -> blog.GetRevisionResponse
+	51, // 82: blog.BlogService.DiffRevisions:output_type -> blog.DiffRevisionsResponse
+	53, // 83: blog.BlogService.RestoreRevision:output_type -> blog.RestoreRevisionResponse
+	71, // 84: blog.BlogService.GetUser:output_type -> blog.GetUserResponse
+	57, // 85: blog.BlogService.ToggleClap:output_type -> blog.ToggleClapResponse
+	59, // 86: blog.BlogService.ToggleFollow:output_type -> blog.ToggleFollowResponse
+	61, // 87: blog.BlogService.ToggleBlock:output_type -> blog.ToggleBlockResponse
+	63, // 88: blog.BlogService.ToggleTagFollow:output_type -> blog.ToggleTagFollowResponse
+	65, // 89: blog.BlogService.ToggleBookmark:output_type -> blog.ToggleBookmarkResponse
+	67, // 90: blog.BlogService.ListNotifications:output_type -> blog.ListNotificationsResponse
+	69, // 91: blog.BlogService.MarkNotificationRead:output_type -> blog.MarkNotificationReadResponse
+	2,  // 92: blog.BlogService.CreateComment:output_type -> blog.CreateCommentResponse
+	4,  // 93: blog.BlogService.ListComments:output_type -> blog.ListCommentsResponse
+	6,  // 94: blog.BlogService.DeleteComment:output_type -> blog.DeleteCommentResponse
+	74, // 95: blog.BlogService.ApplyUserEvent:output_type -> blog.ApplyUserEventResponse
+	77, // 96: blog.BlogService.ExportUserData:output_type -> blog.ExportUserDataResponse
+	67, // [67:97] is the sub-list for method output_type
+	37, // [37:67] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_blog_proto_init() }
@@ -2279,7 +5555,7 @@ func file_pkg_proto_blog_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_proto_blog_proto_rawDesc), len(file_pkg_proto_blog_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   33,
+			NumMessages:   78,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/auth"
+	"project/pkg/tokens"
+)
+
+const (
+	maxAccessTokensPerUser = 50
+	maxAccessTokenDays     = 366
+
+	// lastUsedGranularity limits how often last_used_at is written, so busy
+	// scripts don't turn every request into a row update
+	lastUsedGranularity = time.Minute
+)
+
+func (s *Service) CreateAccessToken(ctx context.Context, req *pb.CreateAccessTokenRequest) (*pb.CreateAccessTokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, status.Error(codes.InvalidArgument, "name is required and must be at most 100 characters")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !tokens.ValidScope(scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown scope %q", scope)
+		}
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAccessTokenDays {
+		return nil, status.Errorf(codes.InvalidArgument, "expires_in_days must be between 0 and %d", maxAccessTokenDays)
+	}
+
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM auth_access_tokens WHERE user_id = $1 AND revoked_at IS NULL",
+		req.UserId,
+	).Scan(&count)
+	if err != nil {
+		s.logger.Error("failed to count access tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if count >= maxAccessTokensPerUser {
+		return nil, status.Errorf(codes.ResourceExhausted, "at most %d active tokens are allowed", maxAccessTokensPerUser)
+	}
+
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		s.logger.Error("failed to generate access token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	token := tokens.PersonalAccessTokenPrefix + secret
+	prefix := token[:len(tokens.PersonalAccessTokenPrefix)+4]
+
+	var expiresAt sql.NullTime
+	if req.ExpiresInDays > 0 {
+		expiresAt = sql.NullTime{Time: time.Now().AddDate(0, 0, int(req.ExpiresInDays)), Valid: true}
+	}
+
+	var id string
+	var createdAt time.Time
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO auth_access_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, req.UserId, name, hashToken(token), prefix, pq.Array(req.Scopes), expiresAt).Scan(&id, &createdAt)
+	if err != nil {
+		s.logger.Error("failed to create access token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("access token created",
+		zap.String("user_id", req.UserId), zap.String("token_id", id), zap.Strings("scopes", req.Scopes))
+
+	return &pb.CreateAccessTokenResponse{
+		Token: token,
+		AccessToken: &pb.AccessToken{
+			Id:        id,
+			Name:      name,
+			Scopes:    req.Scopes,
+			Prefix:    prefix,
+			CreatedAt: createdAt.Unix(),
+			ExpiresAt: unixOrZero(expiresAt),
+		},
+	}, nil
+}
+
+func (s *Service) ListAccessTokens(ctx context.Context, req *pb.ListAccessTokensRequest) (*pb.ListAccessTokensResponse, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, scopes, prefix, created_at, expires_at, last_used_at
+		FROM auth_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, req.UserId)
+	if err != nil {
+		s.logger.Error("failed to list access tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	var list []*pb.AccessToken
+	for rows.Next() {
+		var t pb.AccessToken
+		var createdAt time.Time
+		var expiresAt, lastUsedAt sql.NullTime
+		if err := rows.Scan(&t.Id, &t.Name, pq.Array(&t.Scopes), &t.Prefix, &createdAt, &expiresAt, &lastUsedAt); err != nil {
+			s.logger.Error("failed to scan access token", zap.Error(err))
+			continue
+		}
+		t.CreatedAt = createdAt.Unix()
+		t.ExpiresAt = unixOrZero(expiresAt)
+		t.LastUsedAt = unixOrZero(lastUsedAt)
+		list = append(list, &t)
+	}
+
+	return &pb.ListAccessTokensResponse{Tokens: list}, nil
+}
+
+func (s *Service) RevokeAccessToken(ctx context.Context, req *pb.RevokeAccessTokenRequest) (*pb.RevokeAccessTokenResponse, error) {
+	// Comparing as text keeps malformed IDs a plain "not found"
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE auth_access_tokens SET revoked_at = NOW()
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, req.TokenId, req.UserId)
+	if err != nil {
+		s.logger.Error("failed to revoke access token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, status.Error(codes.NotFound, "access token not found")
+	}
+
+	s.logger.Info("access token revoked", zap.String("user_id", req.UserId), zap.String("token_id", req.TokenId))
+
+	return &pb.RevokeAccessTokenResponse{Success: true}, nil
+}
+
+// validatePersonalAccessToken checks a personal access token. They are
+// always checked against the database, so revocation is immediate.
+func (s *Service) validatePersonalAccessToken(ctx context.Context, token string) (*pb.ValidateResponse, error) {
+	var id, userID string
+	var scopes, roles []string
+	var lastUsedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT t.id, t.user_id, t.scopes, t.last_used_at, u.roles
+		FROM auth_access_tokens t
+		JOIN auth_users u ON u.id = t.user_id
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL
+		  AND (t.expires_at IS NULL OR t.expires_at > NOW())
+	`, hashToken(token)).Scan(&id, &userID, pq.Array(&scopes), &lastUsedAt, pq.Array(&roles))
+	if err == sql.ErrNoRows {
+		return &pb.ValidateResponse{Valid: false}, nil
+	}
+	if err != nil {
+		s.logger.Error("failed to look up access token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if !lastUsedAt.Valid || time.Since(lastUsedAt.Time) > lastUsedGranularity {
+		if _, err := s.db.ExecContext(ctx, "UPDATE auth_access_tokens SET last_used_at = NOW() WHERE id = $1", id); err != nil {
+			s.logger.Warn("failed to record access token use", zap.Error(err))
+		}
+	}
+
+	return &pb.ValidateResponse{
+		Valid:         true,
+		UserId:        userID,
+		Scopes:        scopes,
+		AccessTokenId: id,
+		Roles:         roles,
+		Permissions:   tokens.PermissionsFor(roles),
+	}, nil
+}
+
+func unixOrZero(t sql.NullTime) int64 {
+	if !t.Valid {
+		return 0
+	}
+	return t.Time.Unix()
+}
@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	"project/pkg/mailer"
+	pb "project/pkg/proto/auth"
+)
+
+// Purposes for single-use tokens in auth_user_tokens
+const (
+	purposePasswordReset = "password_reset"
+	purposeVerifyEmail   = "verify_email"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	verifyEmailTTL   = 48 * time.Hour
+)
+
+var errInvalidUserToken = status.Error(codes.InvalidArgument, "token is invalid or has expired")
+
+// newMailer builds the configured mailer. Production must send real mail.
+func newMailer(config *common.Config, logger *zap.Logger) (mailer.Mailer, error) {
+	if config.Environment == "production" && config.Mail.Driver != "smtp" {
+		return nil, fmt.Errorf("MAIL_DRIVER must be smtp in production, got %q", config.Mail.Driver)
+	}
+
+	m, err := mailer.New(mailer.Config{
+		Driver:   config.Mail.Driver,
+		From:     config.Mail.From,
+		Host:     config.Mail.Host,
+		Port:     config.Mail.Port,
+		Username: config.Mail.Username,
+		Password: config.Mail.Password,
+		Dir:      config.Mail.Dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if config.Mail.Driver == "file" {
+		logger.Info("writing outgoing mail to disk", zap.String("dir", config.Mail.Dir))
+	}
+	return m, nil
+}
+
+// createUserToken issues a single-use token for purpose, invalidating any
+// earlier unused token for the same purpose so only the newest link works
+func (s *Service) createUserToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL",
+		userID, purpose,
+	)
+	if err != nil {
+		return "", fmt.Errorf("invalidate previous tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO auth_user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)",
+		userID, purpose, hashToken(token), time.Now().Add(ttl),
+	)
+	if err != nil {
+		return "", fmt.Errorf("store user token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken marks a token used and returns its user. The single
+// UPDATE makes redemption atomic, so a token can never be used twice.
+func (s *Service) consumeUserToken(ctx context.Context, db dbExecutor, token, purpose string) (string, error) {
+	var userID string
+	err := db.QueryRowContext(ctx, `
+		UPDATE auth_user_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, hashToken(token), purpose).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", errInvalidUserToken
+	}
+	if err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// userForVerifiedEmail returns the account for an email address whose owner
+// has just been verified by a third party (a social login provider, or a
+// link sent to the address), creating one if there is none. via names that
+// party in logs. The second result reports whether the account has a
+// second factor.
+func (s *Service) userForVerifiedEmail(ctx context.Context, tx *sql.Tx, email, name, picture, via string) (*pb.User, bool, error) {
+	user := &pb.User{}
+	var localVerified, totpEnabled bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, email, COALESCE(name, ''), email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, roles
+		FROM auth_users WHERE LOWER(email) = LOWER($1)
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE
+	`, email).Scan(&user.Id, &user.Email, &user.Name, &localVerified, &totpEnabled, pq.Array(&user.Roles))
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO auth_users (email, name, avatar_url, email_verified_at)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NOW())
+			RETURNING id, email, COALESCE(name, '')
+		`, email, name, picture).Scan(&user.Id, &user.Email, &user.Name)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := s.enqueueUserEvent(ctx, tx, common.EventUserCreated, user.Id); err != nil {
+			return nil, false, err
+		}
+		s.logger.Info("user registered", zap.String("id", user.Id), zap.String("via", via))
+
+	case err != nil:
+		return nil, false, err
+
+	case !localVerified:
+		// Nobody proved they own this address when the account was made,
+		// so it may have been registered by someone else in anticipation.
+		// The address's owner takes it over: drop the password, second
+		// factor and any sessions or tokens created with them.
+		if err := s.claimUnverifiedAccount(ctx, tx, user.Id); err != nil {
+			return nil, false, err
+		}
+		totpEnabled = false
+		s.logger.Warn("unverified account claimed by the email's owner",
+			zap.String("user_id", user.Id), zap.String("via", via))
+
+	default:
+		s.logger.Info("verified email matched existing account",
+			zap.String("user_id", user.Id), zap.String("via", via))
+	}
+
+	user.EmailVerified = true
+	return user, totpEnabled, nil
+}
+
+func (s *Service) claimUnverifiedAccount(ctx context.Context, tx *sql.Tx, userID string) error {
+	statements := []string{
+		`UPDATE auth_users
+		 SET password_hash = NULL, email_verified_at = NOW(),
+		     totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+		 WHERE id = $1`,
+		"DELETE FROM auth_recovery_codes WHERE user_id = $1",
+		"UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+		"UPDATE auth_access_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt, userID); err != nil {
+			return fmt.Errorf("claim unverified account: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) sendVerificationEmail(ctx context.Context, userID, email string) error {
+	token, err := s.createUserToken(ctx, userID, purposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Confirm your email address",
+		Text: fmt.Sprintf("Welcome to Minimum!\n\n"+
+			"Confirm your email address by opening the link below:\n\n%s/verify-email?token=%s\n\n"+
+			"The link expires in %d hours.\n",
+			s.config.AppURL, token, int(verifyEmailTTL.Hours())),
+	})
+}
+
+func (s *Service) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	// The response is the same whether or not the account exists, so this
+	// endpoint cannot be used to discover registered emails
+	var userID, email string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT id, email FROM auth_users WHERE email = $1",
+		req.Email,
+	).Scan(&userID, &email)
+	if err == sql.ErrNoRows {
+		s.logger.Info("password reset requested for unknown email")
+		return &pb.RequestPasswordResetResponse{Success: true}, nil
+	}
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	token, err := s.createUserToken(ctx, userID, purposePasswordReset, passwordResetTTL)
+	if err != nil {
+		s.logger.Error("failed to create reset token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Text: fmt.Sprintf("Someone asked to reset the password for your Minimum account.\n\n"+
+			"Choose a new password here:\n\n%s/reset-password?token=%s\n\n"+
+			"The link expires in %d minutes. If this wasn't you, you can ignore this email.\n",
+			s.config.AppURL, token, int(passwordResetTTL.Minutes())),
+	})
+	if err != nil {
+		s.logger.Error("failed to send password reset email", zap.String("user_id", userID), zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to send email")
+	}
+
+	s.logger.Info("password reset requested", zap.String("user_id", userID))
+
+	return &pb.RequestPasswordResetResponse{Success: true}, nil
+}
+
+func (s *Service) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if req.Token == "" || req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "token and new password are required")
+	}
+
+	hash, err := s.passwords.Hash(req.NewPassword)
+	if err != nil {
+		s.logger.Error("failed to hash password", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consumeUserToken(ctx, tx, req.Token, purposePasswordReset)
+	if err != nil {
+		if err != errInvalidUserToken {
+			s.logger.Error("failed to redeem reset token", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, err
+	}
+
+	// Receiving the reset email also proves the address is theirs
+	var email string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE auth_users
+		SET password_hash = $1, email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $2
+		RETURNING email
+	`, hash, userID).Scan(&email)
+	if err != nil {
+		s.logger.Error("failed to update password", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// Sign out everywhere in case the old password was compromised
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+		userID,
+	)
+	if err != nil {
+		s.logger.Error("failed to revoke sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// The owner has proven themselves, so lift any lockout
+	if err := s.clearThrottle(ctx, tx, emailThrottleKey(email)); err != nil {
+		s.logger.Error("failed to clear login throttle", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit password reset", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("password reset", zap.String("user_id", userID))
+
+	return &pb.ResetPasswordResponse{Success: true}, nil
+}
+
+func (s *Service) SendVerificationEmail(ctx context.Context, req *pb.SendVerificationEmailRequest) (*pb.SendVerificationEmailResponse, error) {
+	var email string
+	var verified bool
+	err := s.db.QueryRowContext(ctx,
+		"SELECT email, email_verified_at IS NOT NULL FROM auth_users WHERE id = $1",
+		req.UserId,
+	).Scan(&email, &verified)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if verified {
+		return &pb.SendVerificationEmailResponse{Success: true, AlreadyVerified: true}, nil
+	}
+
+	if err := s.sendVerificationEmail(ctx, req.UserId, email); err != nil {
+		s.logger.Error("failed to send verification email", zap.String("user_id", req.UserId), zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to send email")
+	}
+
+	return &pb.SendVerificationEmailResponse{Success: true}, nil
+}
+
+func (s *Service) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consumeUserToken(ctx, tx, req.Token, purposeVerifyEmail)
+	if err != nil {
+		if err != errInvalidUserToken {
+			s.logger.Error("failed to redeem verification token", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1",
+		userID,
+	)
+	if err != nil {
+		s.logger.Error("failed to mark email verified", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit email verification", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("email verified", zap.String("user_id", userID))
+
+	return &pb.VerifyEmailResponse{Success: true, UserId: userID}, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"project/pkg/common"
+	pb "project/pkg/proto/auth"
+	"project/pkg/tokens"
+)
+
+// keyReloadInterval is how often the key directory is re-read so that
+// every replica picks up keys rotated by its peers
+const keyReloadInterval = 5 * time.Minute
+
+// loadKeyring loads signing keys from JWT_KEYS_DIR. Development falls back
+// to a generated in-memory key; production refuses to start without keys.
+func loadKeyring(config *common.Config, logger *zap.Logger) (*tokens.Keyring, error) {
+	keyring, err := tokens.NewKeyring(config.Auth.KeysDir, config.Auth.KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring.Len() > 0 {
+		logger.Info("loaded signing keys",
+			zap.Int("count", keyring.Len()),
+			zap.String("current_kid", keyring.Current().ID))
+		return keyring, nil
+	}
+
+	if config.Environment == "production" {
+		return nil, fmt.Errorf("no signing keys found: set JWT_KEYS_DIR to a directory of PEM keys")
+	}
+
+	key, err := keyring.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if keyring.Persistent() {
+		logger.Warn("no signing keys found, generated a new one", zap.String("kid", key.ID))
+	} else {
+		logger.Warn("JWT_KEYS_DIR not set, using an ephemeral signing key (development only)", zap.String("kid", key.ID))
+	}
+	return keyring, nil
+}
+
+// rotateKeys periodically reloads the key directory and rotates the
+// current signing key once it reaches the configured age
+func (s *Service) rotateKeys() {
+	ticker := time.NewTicker(keyReloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.keyring.Reload(); err != nil {
+			s.logger.Error("failed to reload signing keys", zap.Error(err))
+			continue
+		}
+
+		rotated, err := s.keyring.Rotate(s.config.Auth.KeyRotationInterval, s.config.Auth.KeyRetention)
+		if err != nil {
+			s.logger.Error("failed to rotate signing keys", zap.Error(err))
+			continue
+		}
+		if rotated {
+			s.logger.Info("rotated signing key", zap.String("kid", s.keyring.Current().ID))
+		}
+	}
+}
+
+func (s *Service) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	jwks := s.keyring.JWKS()
+	keys := make([]*pb.JWK, 0, len(jwks))
+	for _, k := range jwks {
+		keys = append(keys, &pb.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Alg: k.Alg,
+			Use: k.Use,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+		})
+	}
+	return &pb.GetJWKSResponse{Keys: keys}, nil
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"project/pkg/mailer"
+	pb "project/pkg/proto/auth"
+)
+
+const (
+	magicLinkTTL = 15 * time.Minute
+
+	// Links are sent to any address, so cap how many one inbox can be sent
+	magicLinksPerWindow = 5
+	magicLinkWindow     = time.Hour
+)
+
+func (s *Service) RequestMagicLink(ctx context.Context, req *pb.RequestMagicLinkRequest) (*pb.RequestMagicLinkResponse, error) {
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		s.logger.Error("failed to generate magic link", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var sent int
+	var oldest sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(created_at) FROM auth_magic_links
+		WHERE LOWER(email) = LOWER($1) AND created_at > $2
+	`, email, time.Now().Add(-magicLinkWindow)).Scan(&sent, &oldest)
+	if err != nil {
+		s.logger.Error("failed to count magic links", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if sent >= magicLinksPerWindow {
+		s.logger.Info("magic link rate limited", zap.String("email", email), zap.String("ip", req.IpAddress))
+		return nil, magicLinkLimitError(time.Until(oldest.Time.Add(magicLinkWindow)))
+	}
+
+	// Only the newest link works, like other emailed tokens
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_magic_links SET used_at = NOW() WHERE LOWER(email) = LOWER($1) AND used_at IS NULL",
+		email,
+	)
+	if err != nil {
+		s.logger.Error("failed to invalidate magic links", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO auth_magic_links (token_hash, email, expires_at) VALUES ($1, $2, $3)",
+		hashToken(token), email, time.Now().Add(magicLinkTTL),
+	)
+	if err != nil {
+		s.logger.Error("failed to store magic link", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit magic link", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Your sign-in link",
+		Text: fmt.Sprintf("Sign in to Minimum by opening the link below:\n\n%s/magic-link?token=%s\n\n"+
+			"The link works once and expires in %d minutes. If you didn't ask for it, you can ignore this email.\n",
+			s.config.AppURL, token, int(magicLinkTTL.Minutes())),
+	})
+	if err != nil {
+		s.logger.Error("failed to send magic link", zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to send email")
+	}
+
+	s.logger.Info("magic link sent", zap.String("ip", req.IpAddress))
+
+	return &pb.RequestMagicLinkResponse{Success: true}, nil
+}
+
+func (s *Service) ConsumeMagicLink(ctx context.Context, req *pb.ConsumeMagicLinkRequest) (*pb.LoginResponse, error) {
+	if req.Token == "" {
+		return nil, errInvalidUserToken
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE auth_magic_links SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING email
+	`, hashToken(req.Token)).Scan(&email)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidUserToken
+	}
+	if err != nil {
+		s.logger.Error("failed to redeem magic link", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// Opening the link proves the reader owns the address
+	user, totpEnabled, err := s.userForVerifiedEmail(ctx, tx, email, "", "", "magic_link")
+	if err != nil {
+		s.logger.Error("failed to resolve magic link user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit magic link login", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// The link stands in for the password, not the second factor
+	if totpEnabled {
+		mfaToken, err := s.signMFAToken(user.Id, user.Email, user.Name)
+		if err != nil {
+			s.logger.Error("failed to sign mfa token", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		s.logger.Info("login awaiting second factor", zap.String("user_id", user.Id), zap.String("via", "magic_link"))
+		return &pb.LoginResponse{MfaRequired: true, MfaToken: mfaToken}, nil
+	}
+
+	pair, err := s.issueTokenPair(ctx, user.Id, user.Email, user.Name, user.Roles, clientInfo{req.IpAddress, req.UserAgent})
+	if err != nil {
+		s.logger.Error("failed to issue tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("login successful", zap.String("user_id", user.Id), zap.String("via", "magic_link"))
+
+	return &pb.LoginResponse{
+		Token:        pair.accessToken,
+		RefreshToken: pair.refreshToken,
+		ExpiresIn:    pair.expiresIn,
+		User:         user,
+	}, nil
+}
+
+// purgeMagicLinks periodically drops links that can no longer be used or
+// count towards the rate limit
+func (s *Service) purgeMagicLinks() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.db.Exec(
+			"DELETE FROM auth_magic_links WHERE expires_at < $1",
+			time.Now().Add(-magicLinkWindow),
+		)
+		if err != nil {
+			s.logger.Error("failed to purge magic links", zap.Error(err))
+		}
+	}
+}
+
+// magicLinkLimitError carries the wait in RetryInfo so the gateway can
+// answer with Retry-After
+func magicLinkLimitError(retryAfter time.Duration) error {
+	retryAfter = max(retryAfter.Round(time.Second), time.Second)
+	st := status.New(codes.ResourceExhausted, "too many sign-in links requested")
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
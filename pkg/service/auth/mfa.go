@@ -0,0 +1,341 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/auth"
+	"project/pkg/tokens"
+	"project/pkg/totp"
+)
+
+const (
+	totpIssuer = "Minimum"
+	totpSkew   = 1 // Accept codes one step either side for clock drift
+
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var errInvalidCode = status.Error(codes.InvalidArgument, "invalid code")
+
+// signMFAToken issues the challenge token returned by Login for accounts
+// with 2FA. It carries no session, so it is useless as an access token.
+func (s *Service) signMFAToken(userID, email, name string) (string, error) {
+	now := time.Now()
+	return s.keyring.Sign(&tokens.Claims{
+		UserID: userID,
+		Email:  email,
+		Name:   name,
+		Type:   tokens.TypeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokens.Issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaTokenTTL)),
+		},
+	})
+}
+
+// checkSecondFactor accepts either a TOTP code or an unused recovery code
+// for a user with 2FA enabled, consuming it so it cannot be replayed. It
+// locks the user row, so db should be a transaction.
+func (s *Service) checkSecondFactor(ctx context.Context, db dbExecutor, userID, code string) (bool, error) {
+	var secret string
+	var lastStep int64
+	err := db.QueryRowContext(ctx, `
+		SELECT totp_secret, COALESCE(totp_last_step, 0) FROM auth_users
+		WHERE id = $1 AND totp_enabled_at IS NOT NULL
+		FOR UPDATE
+	`, userID).Scan(&secret, &lastStep)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+		if !ok || step <= lastStep {
+			return false, nil
+		}
+		_, err := db.ExecContext(ctx, "UPDATE auth_users SET totp_last_step = $1 WHERE id = $2", step, userID)
+		return err == nil, err
+	}
+
+	res, err := db.ExecContext(ctx, `
+		UPDATE auth_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		s.logger.Info("recovery code used", zap.String("user_id", userID))
+	}
+	return n > 0, nil
+}
+
+// replaceRecoveryCodes discards a user's recovery codes and issues a new set
+func (s *Service) replaceRecoveryCodes(ctx context.Context, db dbExecutor, userID string) ([]string, error) {
+	if _, err := db.ExecContext(ctx, "DELETE FROM auth_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		_, err = db.ExecContext(ctx,
+			"INSERT INTO auth_recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userID, hashToken(normalizeRecoveryCode(code)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodes[i] = code
+	}
+	return recoveryCodes, nil
+}
+
+// generateRecoveryCode returns 50 random bits formatted as "xxxxx-xxxxx"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+	return s[:5] + "-" + s[5:], nil
+}
+
+// normalizeRecoveryCode tolerates case and separator differences in user input
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func (s *Service) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	var email string
+	var enabled bool
+	err := s.db.QueryRowContext(ctx,
+		"SELECT email, totp_enabled_at IS NOT NULL FROM auth_users WHERE id = $1",
+		req.UserId,
+	).Scan(&email, &enabled)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if enabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Error("failed to generate totp secret", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// Re-enrolling before confirming simply replaces the pending secret
+	_, err = s.db.ExecContext(ctx,
+		"UPDATE auth_users SET totp_secret = $1, totp_last_step = NULL WHERE id = $2 AND totp_enabled_at IS NULL",
+		secret, req.UserId,
+	)
+	if err != nil {
+		s.logger.Error("failed to store totp secret", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &pb.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUrl: totp.URI(totpIssuer, email, secret),
+	}, nil
+}
+
+func (s *Service) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var secret sql.NullString
+	var enabled bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT totp_secret, totp_enabled_at IS NOT NULL FROM auth_users WHERE id = $1 FOR UPDATE",
+		req.UserId,
+	).Scan(&secret, &enabled)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if enabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if !secret.Valid {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor enrollment has not been started")
+	}
+
+	step, ok := totp.Validate(secret.String, strings.TrimSpace(req.Code), time.Now(), totpSkew)
+	if !ok {
+		return nil, errInvalidCode
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_users SET totp_enabled_at = NOW(), totp_last_step = $1 WHERE id = $2",
+		step, req.UserId,
+	)
+	if err != nil {
+		s.logger.Error("failed to enable totp", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, tx, req.UserId)
+	if err != nil {
+		s.logger.Error("failed to create recovery codes", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit totp enrollment", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("two-factor authentication enabled", zap.String("user_id", req.UserId))
+
+	return &pb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *Service) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	ok, err := s.checkSecondFactor(ctx, tx, req.UserId, req.Code)
+	if err != nil {
+		s.logger.Error("failed to check second factor", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		return nil, errInvalidCode
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE auth_users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = $1",
+		req.UserId,
+	)
+	if err != nil {
+		s.logger.Error("failed to disable totp", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM auth_recovery_codes WHERE user_id = $1", req.UserId); err != nil {
+		s.logger.Error("failed to delete recovery codes", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit totp removal", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("two-factor authentication disabled", zap.String("user_id", req.UserId))
+
+	return &pb.DisableTOTPResponse{Success: true}, nil
+}
+
+func (s *Service) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.LoginResponse, error) {
+	claims, err := tokens.Parse(req.MfaToken, s.keyring.Keyfunc)
+	if err != nil || claims.Type != tokens.TypeMFA {
+		return nil, status.Error(codes.Unauthenticated, "mfa token is invalid or expired")
+	}
+
+	// Second-factor guesses count against the same budget as passwords
+	locked, err := s.startLogin(ctx, claims.Email, req.IpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	ok, err := s.checkSecondFactor(ctx, tx, claims.UserID, req.Code)
+	if err != nil {
+		s.logger.Error("failed to check second factor", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		s.logger.Info("invalid second factor", zap.String("user_id", claims.UserID))
+		tx.Rollback()
+		s.loginFailed(ctx, claims.Email, req.IpAddress, locked)
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+
+	var emailVerified bool
+	var roles []string
+	err = tx.QueryRowContext(ctx,
+		"SELECT email_verified_at IS NOT NULL, roles FROM auth_users WHERE id = $1",
+		claims.UserID,
+	).Scan(&emailVerified, pq.Array(&roles))
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit second factor", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	pair, err := s.issueTokenPair(ctx, claims.UserID, claims.Email, claims.Name, roles, clientInfo{req.IpAddress, req.UserAgent})
+	if err != nil {
+		s.logger.Error("failed to issue tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.loginSucceeded(ctx, claims.Email, req.IpAddress)
+
+	s.logger.Info("login successful", zap.String("user_id", claims.UserID), zap.Bool("mfa", true))
+
+	return &pb.LoginResponse{
+		Token:        pair.accessToken,
+		RefreshToken: pair.refreshToken,
+		ExpiresIn:    pair.expiresIn,
+		User: &pb.User{
+			Id:            claims.UserID,
+			Email:         claims.Email,
+			EmailVerified: emailVerified,
+			Roles:         roles,
+		},
+	}, nil
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	"project/pkg/oidc"
+	pb "project/pkg/proto/auth"
+)
+
+// How long a user has to finish signing in at the provider
+const oauthStateTTL = 10 * time.Minute
+
+var errInvalidOAuthState = status.Error(codes.InvalidArgument, "login attempt is invalid or has expired")
+
+func newOAuthProviders(config *common.Config, logger *zap.Logger) map[string]*oidc.Provider {
+	client := &http.Client{Timeout: 10 * time.Second}
+	providers := make(map[string]*oidc.Provider, len(config.OAuthProviders))
+	for _, p := range config.OAuthProviders {
+		providers[p.Name] = oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, client)
+		logger.Info("social login enabled", zap.String("provider", p.Name), zap.String("issuer", p.Issuer))
+	}
+	return providers
+}
+
+func (s *Service) StartOAuth(ctx context.Context, req *pb.StartOAuthRequest) (*pb.StartOAuthResponse, error) {
+	provider, ok := s.providers[req.Provider]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown provider")
+	}
+
+	var values [3]string
+	for i := range values {
+		v, err := oidc.GenerateVerifier()
+		if err != nil {
+			s.logger.Error("failed to generate oauth state", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		s.logger.Error("failed to build authorization url", zap.String("provider", req.Provider), zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "provider is unavailable")
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO auth_oauth_states (state_hash, provider, nonce, code_verifier, expires_at) VALUES ($1, $2, $3, $4, $5)",
+		hashToken(state), req.Provider, nonce, verifier, time.Now().Add(oauthStateTTL),
+	)
+	if err != nil {
+		s.logger.Error("failed to store oauth state", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &pb.StartOAuthResponse{AuthorizationUrl: authURL, State: state}, nil
+}
+
+func (s *Service) CompleteOAuth(ctx context.Context, req *pb.CompleteOAuthRequest) (*pb.LoginResponse, error) {
+	provider, ok := s.providers[req.Provider]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown provider")
+	}
+	if req.Code == "" || req.State == "" {
+		return nil, status.Error(codes.InvalidArgument, "code and state are required")
+	}
+
+	// Each state is good for one callback
+	var nonce, verifier string
+	err := s.db.QueryRowContext(ctx, `
+		DELETE FROM auth_oauth_states
+		WHERE state_hash = $1 AND provider = $2 AND expires_at > NOW()
+		RETURNING nonce, code_verifier
+	`, hashToken(req.State), req.Provider).Scan(&nonce, &verifier)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidOAuthState
+	}
+	if err != nil {
+		s.logger.Error("failed to redeem oauth state", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, req.Code, verifier)
+	if err != nil {
+		s.logger.Warn("oauth code exchange failed", zap.String("provider", req.Provider), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "provider rejected the login")
+	}
+	idToken, err := provider.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		s.logger.Warn("id token rejected", zap.String("provider", req.Provider), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "provider rejected the login")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	user, totpEnabled, err := s.resolveOAuthUser(ctx, tx, req.Provider, idToken)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			s.logger.Error("failed to resolve oauth user", zap.String("provider", req.Provider), zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit oauth login", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// The provider stands in for the password, not the second factor
+	if totpEnabled {
+		mfaToken, err := s.signMFAToken(user.Id, user.Email, user.Name)
+		if err != nil {
+			s.logger.Error("failed to sign mfa token", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		s.logger.Info("login awaiting second factor", zap.String("user_id", user.Id), zap.String("provider", req.Provider))
+		return &pb.LoginResponse{MfaRequired: true, MfaToken: mfaToken}, nil
+	}
+
+	pair, err := s.issueTokenPair(ctx, user.Id, user.Email, user.Name, user.Roles, clientInfo{req.IpAddress, req.UserAgent})
+	if err != nil {
+		s.logger.Error("failed to issue tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("login successful", zap.String("user_id", user.Id), zap.String("provider", req.Provider))
+
+	return &pb.LoginResponse{
+		Token:        pair.accessToken,
+		RefreshToken: pair.refreshToken,
+		ExpiresIn:    pair.expiresIn,
+		User:         user,
+	}, nil
+}
+
+// resolveOAuthUser finds the account for a provider identity, linking it to
+// an existing account with the same verified email or creating a new one.
+// It returns status errors for logins that must be refused.
+func (s *Service) resolveOAuthUser(ctx context.Context, tx *sql.Tx, provider string, idToken *oidc.IDToken) (*pb.User, bool, error) {
+	user := &pb.User{}
+	var totpEnabled bool
+	err := tx.QueryRowContext(ctx, `
+		UPDATE auth_identities i SET last_login_at = NOW()
+		FROM auth_users u
+		WHERE i.user_id = u.id AND i.provider = $1 AND i.subject = $2
+		RETURNING u.id, u.email, COALESCE(u.name, ''), u.email_verified_at IS NOT NULL, u.totp_enabled_at IS NOT NULL, u.roles
+	`, provider, idToken.Subject).Scan(&user.Id, &user.Email, &user.Name, &user.EmailVerified, &totpEnabled, pq.Array(&user.Roles))
+	if err == nil {
+		return user, totpEnabled, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	// Linking and sign-up both go by email, so the provider must vouch for it
+	email := strings.TrimSpace(idToken.Email)
+	if email == "" || !idToken.EmailVerified {
+		return nil, false, status.Error(codes.FailedPrecondition, "provider has not verified your email address")
+	}
+
+	user, totpEnabled, err = s.userForVerifiedEmail(ctx, tx, email, idToken.Name, idToken.Picture, provider)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO auth_identities (user_id, provider, subject, email, last_login_at) VALUES ($1, $2, $3, $4, NOW())",
+		user.Id, provider, idToken.Subject, email,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return user, totpEnabled, nil
+}
+
+// purgeOAuthStates periodically drops logins that were never completed
+func (s *Service) purgeOAuthStates() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.db.Exec("DELETE FROM auth_oauth_states WHERE expires_at < NOW()"); err != nil {
+			s.logger.Error("failed to purge oauth states", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	blogpb "project/pkg/proto/blog"
+)
+
+const (
+	outboxPollInterval = 2 * time.Second
+	outboxBatchSize    = 100
+	outboxSendTimeout  = 5 * time.Second
+	outboxRetention    = 7 * 24 * time.Hour
+
+	// Long enough to send a whole batch even when every send times out
+	outboxClaimTTL = outboxBatchSize*outboxSendTimeout + time.Minute
+
+	migrationBackfillUserEvents = "backfill_user_events"
+)
+
+// userEventPayloadSQL snapshots the profile of auth_users row u as event JSON
+const userEventPayloadSQL = `json_build_object(
+	'email', u.email,
+	'name', COALESCE(u.name, ''),
+	'bio', COALESCE(u.bio, ''),
+	'avatar_url', COALESCE(u.avatar_url, ''),
+	'username', COALESCE(u.username, ''),
+	'version', u.version
+)`
+
+// userEventPublisher is the part of the blog API that user events go to
+type userEventPublisher interface {
+	ApplyUserEvent(ctx context.Context, in *blogpb.ApplyUserEventRequest, opts ...grpc.CallOption) (*blogpb.ApplyUserEventResponse, error)
+}
+
+type userEventPayload struct {
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	Bio       string `json:"bio"`
+	AvatarURL string `json:"avatar_url"`
+	Username  string `json:"username"`
+	Version   int64  `json:"version"`
+}
+
+// enqueueUserEvent records an event with the user's current profile. Call
+// it in the transaction that changes the user, so the event is stored if
+// and only if the change commits.
+func (s *Service) enqueueUserEvent(ctx context.Context, db dbExecutor, eventType, userID string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO auth_outbox (event_type, user_id, payload)
+		SELECT $1, u.id, `+userEventPayloadSQL+`
+		FROM auth_users u WHERE u.id = $2
+	`, eventType, userID)
+	return err
+}
+
+// backfillUserEvents publishes accounts created before the outbox existed.
+// It runs once: the migration is marked done in the same transaction, so
+// restarts don't publish every account again after delivered events have
+// been pruned.
+func (s *Service) backfillUserEvents(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO auth_migrations (name) VALUES ($1) ON CONFLICT (name) DO NOTHING",
+		migrationBackfillUserEvents,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO auth_outbox (event_type, user_id, payload)
+		SELECT $1, u.id, `+userEventPayloadSQL+`
+		FROM auth_users u
+		WHERE NOT EXISTS (SELECT 1 FROM auth_outbox o WHERE o.user_id = u.id)
+		ORDER BY u.created_at
+	`, common.EventUserCreated)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// dispatchOutbox delivers pending events until the process exits
+func (s *Service) dispatchOutbox() {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	lastPrune := time.Now()
+
+	for range ticker.C {
+		if _, err := s.deliverOutbox(context.Background()); err != nil {
+			s.logger.Warn("user event delivery stalled, will retry", zap.Error(err))
+		}
+
+		if time.Since(lastPrune) > time.Hour {
+			lastPrune = time.Now()
+			_, err := s.db.Exec(
+				"DELETE FROM auth_outbox WHERE delivered_at < $1",
+				time.Now().Add(-outboxRetention),
+			)
+			if err != nil {
+				s.logger.Error("failed to prune outbox", zap.Error(err))
+			}
+		}
+	}
+}
+
+type outboxEvent struct {
+	id         int64
+	eventType  string
+	userID     string
+	payload    []byte
+	occurredAt time.Time
+}
+
+// deliverOutbox sends one batch of pending events in order and returns how
+// many were delivered. It stops at the first retryable failure so a user's
+// events are never applied out of order. The batch is claimed in a short
+// transaction and sent after it commits, so no row locks are held across
+// calls to the blog service. A batch is only claimed while no other claim
+// is live, so concurrent replicas take turns instead of racing.
+func (s *Service) deliverOutbox(ctx context.Context) (int, error) {
+	events, err := s.claimOutbox(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	delivered := 0
+	var sendErr error
+	for _, ev := range events {
+		err := s.publishUserEvent(ctx, ev)
+		if err == nil {
+			if _, err := s.db.ExecContext(ctx,
+				"UPDATE auth_outbox SET delivered_at = NOW(), attempts = attempts + 1 WHERE id = $1",
+				ev.id,
+			); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		if permanentDeliveryError(err) {
+			// Retrying cannot succeed; park the event and keep going
+			s.logger.Error("user event rejected",
+				zap.Int64("event_id", ev.id), zap.String("user_id", ev.userID), zap.Error(err))
+			if _, err := s.db.ExecContext(ctx,
+				"UPDATE auth_outbox SET failed_at = NOW(), attempts = attempts + 1, last_error = $1 WHERE id = $2",
+				err.Error(), ev.id,
+			); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if _, err := s.db.ExecContext(ctx,
+			"UPDATE auth_outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2",
+			err.Error(), ev.id,
+		); err != nil {
+			return delivered, err
+		}
+		sendErr = fmt.Errorf("deliver event %d: %w", ev.id, err)
+		break
+	}
+
+	// Hand back whatever wasn't sent, so the next round starts with it
+	ids := make([]int64, len(events))
+	for i, ev := range events {
+		ids[i] = ev.id
+	}
+	if _, err := s.db.ExecContext(ctx,
+		"UPDATE auth_outbox SET claimed_until = NULL WHERE id = ANY($1)",
+		pq.Array(ids),
+	); err != nil {
+		return delivered, err
+	}
+	return delivered, sendErr
+}
+
+// claimOutbox claims the oldest pending events for outboxClaimTTL
+func (s *Service) claimOutbox(ctx context.Context) ([]outboxEvent, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE auth_outbox SET claimed_until = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM auth_outbox
+			WHERE delivered_at IS NULL AND failed_at IS NULL
+			  AND (claimed_until IS NULL OR claimed_until < NOW())
+			  AND NOT EXISTS (
+				SELECT 1 FROM auth_outbox c
+				WHERE c.claimed_until >= NOW() AND c.delivered_at IS NULL AND c.failed_at IS NULL
+			  )
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE
+		)
+		RETURNING id, event_type, user_id, payload, created_at
+	`, outboxBatchSize, int(outboxClaimTTL.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	var events []outboxEvent
+	for rows.Next() {
+		var ev outboxEvent
+		if err := rows.Scan(&ev.id, &ev.eventType, &ev.userID, &ev.payload, &ev.occurredAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING doesn't keep the subquery's order
+	slices.SortFunc(events, func(a, b outboxEvent) int { return cmp.Compare(a.id, b.id) })
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *Service) publishUserEvent(ctx context.Context, ev outboxEvent) error {
+	var payload userEventPayload
+	if err := json.Unmarshal(ev.payload, &payload); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed payload: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+	defer cancel()
+
+	_, err := s.publisher.ApplyUserEvent(ctx, &blogpb.ApplyUserEventRequest{
+		Event: &blogpb.UserEvent{
+			Id:         ev.id,
+			Type:       ev.eventType,
+			UserId:     ev.userID,
+			Email:      payload.Email,
+			Name:       payload.Name,
+			Bio:        payload.Bio,
+			AvatarUrl:  payload.AvatarURL,
+			OccurredAt: ev.occurredAt.Unix(),
+			Username:   payload.Username,
+			Version:    payload.Version,
+		},
+	})
+	return err
+}
+
+// permanentDeliveryError reports whether the blog service rejected an
+// event outright, as opposed to being unavailable
+func permanentDeliveryError(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.AlreadyExists, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
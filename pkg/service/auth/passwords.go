@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"project/pkg/common"
+	"project/pkg/password"
+)
+
+func newPasswordHasher(config *common.Config) password.Hasher {
+	return password.NewArgon2id(password.Argon2Params{
+		Memory:      uint32(config.Auth.PasswordMemory),
+		Iterations:  uint32(config.Auth.PasswordIterations),
+		Parallelism: uint8(config.Auth.PasswordParallelism),
+	})
+}
+
+// rehashPassword replaces an outdated hash after a successful login, while
+// the plain password is at hand. Failing here only delays the upgrade to the
+// next login, so errors are logged rather than returned.
+func (s *Service) rehashPassword(ctx context.Context, userID, plain, oldHash string) {
+	hash, err := s.passwords.Hash(plain)
+	if err != nil {
+		s.logger.Warn("failed to rehash password", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+
+	// Matching the old hash keeps a concurrent password reset from being undone
+	_, err = s.db.ExecContext(ctx,
+		"UPDATE auth_users SET password_hash = $1 WHERE id = $2 AND password_hash = $3",
+		hash, userID, oldHash,
+	)
+	if err != nil {
+		s.logger.Warn("failed to store rehashed password", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+
+	s.logger.Info("password hash upgraded", zap.String("user_id", userID))
+}
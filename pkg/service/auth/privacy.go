@@ -0,0 +1,388 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	pb "project/pkg/proto/auth"
+)
+
+const (
+	accountPurgeBatchSize = 100
+
+	// How recently an account with neither a password nor 2FA must have
+	// signed in to delete itself
+	recentSignInWindow = 10 * time.Minute
+)
+
+func (s *Service) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var passwordHash string
+	var totpEnabled bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(password_hash, ''), totp_enabled_at IS NOT NULL FROM auth_users WHERE id = $1 FOR UPDATE",
+		req.UserId,
+	).Scan(&passwordHash, &totpEnabled)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// A stolen access token alone should not be enough to wipe an account
+	if err := s.confirmAccountOwner(ctx, tx, req, passwordHash, totpEnabled); err != nil {
+		return nil, err
+	}
+
+	deleteAfter := time.Now().Add(s.config.Auth.AccountDeletionGrace)
+	if _, err := tx.ExecContext(ctx, "UPDATE auth_users SET delete_after = $1 WHERE id = $2", deleteAfter, req.UserId); err != nil {
+		s.logger.Error("failed to schedule account deletion", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+		req.UserId,
+	); err != nil {
+		s.logger.Error("failed to revoke sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE auth_access_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+		req.UserId,
+	); err != nil {
+		s.logger.Error("failed to revoke access tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit account deletion", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("account deletion scheduled", zap.String("user_id", req.UserId), zap.Time("delete_after", deleteAfter))
+
+	return &pb.DeleteAccountResponse{DeleteAfter: deleteAfter.Unix()}, nil
+}
+
+// confirmAccountOwner checks that whoever asks to delete the account is its
+// owner and not just someone holding a session. Accounts without a password
+// (social and magic-link sign-in) confirm with their second factor, or by
+// having signed in again within recentSignInWindow.
+func (s *Service) confirmAccountOwner(ctx context.Context, tx *sql.Tx, req *pb.DeleteAccountRequest, passwordHash string, totpEnabled bool) error {
+	switch {
+	case passwordHash != "":
+		if _, err := s.passwords.Verify(req.Password, passwordHash); err != nil {
+			return status.Error(codes.PermissionDenied, "password is incorrect")
+		}
+		return nil
+
+	case totpEnabled:
+		ok, err := s.checkSecondFactor(ctx, tx, req.UserId, req.Code)
+		if err != nil {
+			s.logger.Error("failed to check second factor", zap.Error(err))
+			return status.Error(codes.Internal, "internal error")
+		}
+		if !ok {
+			return status.Error(codes.PermissionDenied, "code is incorrect")
+		}
+		return nil
+	}
+
+	reauthenticate := status.Error(codes.FailedPrecondition, "sign in again to delete your account")
+	if req.SessionId == "" {
+		return reauthenticate
+	}
+	var signedInAt time.Time
+	err := tx.QueryRowContext(ctx,
+		"SELECT created_at FROM auth_sessions WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL",
+		req.SessionId, req.UserId,
+	).Scan(&signedInAt)
+	if err == sql.ErrNoRows {
+		return reauthenticate
+	}
+	if err != nil {
+		s.logger.Error("failed to look up session", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+	if time.Since(signedInAt) > recentSignInWindow {
+		return reauthenticate
+	}
+	return nil
+}
+
+// purgeDeletedAccounts periodically erases accounts whose grace period is over
+func (s *Service) purgeDeletedAccounts() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			n, err := s.eraseDeletedAccounts(context.Background())
+			if err != nil {
+				s.logger.Error("failed to erase deleted accounts", zap.Error(err))
+				break
+			}
+			if n < accountPurgeBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// eraseDeletedAccounts deletes one batch of accounts past their grace period
+// and returns how many it deleted. Each deletion is published to the blog
+// service, which removes or anonymizes the user's content there.
+func (s *Service) eraseDeletedAccounts(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM auth_users
+		WHERE delete_after <= NOW()
+		ORDER BY delete_after
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, accountPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		// The event reads the row, so it has to be queued first
+		if err := s.enqueueUserEvent(ctx, tx, common.EventUserDeleted, id); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM auth_users WHERE id = $1", id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.logger.Info("account erased", zap.String("user_id", id))
+	}
+	return len(ids), nil
+}
+
+// accountExport is account.json in a user's data export. Secrets such as
+// password hashes and token hashes are left out.
+type accountExport struct {
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	Username        string     `json:"username"`
+	Name            string     `json:"name"`
+	Bio             string     `json:"bio"`
+	AvatarURL       string     `json:"avatar_url"`
+	Roles           []string   `json:"roles"`
+	CreatedAt       time.Time  `json:"created_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPEnabledAt   *time.Time `json:"two_factor_enabled_at"`
+	DeleteAfter     *time.Time `json:"delete_after,omitempty"`
+
+	PastUsernames []pastUsernameExport `json:"past_usernames"`
+	Identities    []identityExport     `json:"linked_accounts"`
+	Sessions      []sessionExport      `json:"sessions"`
+	AccessTokens  []accessTokenExport  `json:"access_tokens"`
+}
+
+type pastUsernameExport struct {
+	Username  string    `json:"username"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+type identityExport struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+type sessionExport struct {
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type accessTokenExport struct {
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+func (s *Service) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	account, err := s.exportAccount(ctx, req.UserId)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to export account", zap.String("user_id", req.UserId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	content, err := json.MarshalIndent(account, "", "  ")
+	if err != nil {
+		s.logger.Error("failed to encode account export", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("account data exported", zap.String("user_id", req.UserId))
+
+	return &pb.ExportUserDataResponse{
+		Files: []*pb.ExportFile{{Name: "account.json", Content: content}},
+	}, nil
+}
+
+func (s *Service) exportAccount(ctx context.Context, userID string) (*accountExport, error) {
+	a := accountExport{
+		PastUsernames: []pastUsernameExport{},
+		Identities:    []identityExport{},
+		Sessions:      []sessionExport{},
+		AccessTokens:  []accessTokenExport{},
+	}
+	var emailVerifiedAt, totpEnabledAt, deleteAfter sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, email, COALESCE(username, ''), COALESCE(name, ''), COALESCE(bio, ''), COALESCE(avatar_url, ''), roles,
+		       created_at, email_verified_at, totp_enabled_at, delete_after
+		FROM auth_users WHERE id = $1
+	`, userID).Scan(&a.ID, &a.Email, &a.Username, &a.Name, &a.Bio, &a.AvatarURL, pq.Array(&a.Roles),
+		&a.CreatedAt, &emailVerifiedAt, &totpEnabledAt, &deleteAfter)
+	if err != nil {
+		return nil, err
+	}
+	a.EmailVerifiedAt = timeOrNil(emailVerifiedAt)
+	a.TOTPEnabledAt = timeOrNil(totpEnabledAt)
+	a.DeleteAfter = timeOrNil(deleteAfter)
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT username, retired_at FROM auth_username_history WHERE user_id = $1 ORDER BY retired_at",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var u pastUsernameExport
+		if err := rows.Scan(&u.Username, &u.RetiredAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		a.PastUsernames = append(a.PastUsernames, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT provider, COALESCE(email, ''), created_at, last_login_at
+		FROM auth_identities WHERE user_id = $1 ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var i identityExport
+		var lastLoginAt sql.NullTime
+		if err := rows.Scan(&i.Provider, &i.Email, &i.CreatedAt, &lastLoginAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		i.LastLoginAt = timeOrNil(lastLoginAt)
+		a.Identities = append(a.Identities, i)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at, last_seen_at, revoked_at
+		FROM auth_sessions WHERE user_id = $1 ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var se sessionExport
+		var lastSeenAt, revokedAt sql.NullTime
+		if err := rows.Scan(&se.UserAgent, &se.IPAddress, &se.CreatedAt, &lastSeenAt, &revokedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		se.LastSeenAt = timeOrNil(lastSeenAt)
+		se.RevokedAt = timeOrNil(revokedAt)
+		a.Sessions = append(a.Sessions, se)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT name, prefix, scopes, created_at, expires_at, last_used_at, revoked_at
+		FROM auth_access_tokens WHERE user_id = $1 ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t accessTokenExport
+		var expiresAt, lastUsedAt, revokedAt sql.NullTime
+		if err := rows.Scan(&t.Name, &t.Prefix, pq.Array(&t.Scopes), &t.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		t.ExpiresAt = timeOrNil(expiresAt)
+		t.LastUsedAt = timeOrNil(lastUsedAt)
+		t.RevokedAt = timeOrNil(revokedAt)
+		a.AccessTokens = append(a.AccessTokens, t)
+	}
+	return &a, rows.Err()
+}
+
+func timeOrNil(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
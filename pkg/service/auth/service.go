@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -10,33 +11,34 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	"project/pkg/common"
+	"project/pkg/mailer"
+	"project/pkg/oidc"
+	"project/pkg/password"
 	pb "project/pkg/proto/auth"
+	blogpb "project/pkg/proto/blog"
+	"project/pkg/tokens"
 )
 
-var jwtSecret []byte
-
-func init() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		// Development fallback - production should always set JWT_SECRET
-		secret = "dev-secret-minimum-blog-change-in-production"
-	}
-	jwtSecret = []byte(secret)
-}
-
 type Service struct {
 	pb.UnimplementedAuthServiceServer
-	config *common.Config
-	logger *zap.Logger
-	db     *sql.DB
+	config  *common.Config
+	logger  *zap.Logger
+	db      *sql.DB
+	keyring *tokens.Keyring
+	mailer  mailer.Mailer
+
+	passwords password.Hasher
+	publisher userEventPublisher
+	providers map[string]*oidc.Provider
 }
 
 func Run() {
@@ -47,6 +49,17 @@ func Run() {
 	}
 	logger := common.NewLogger(config.Environment)
 
+	// Load signing keys before accepting any traffic
+	keyring, err := loadKeyring(config, logger)
+	if err != nil {
+		logger.Fatal("failed to load signing keys", zap.Error(err))
+	}
+
+	mail, err := newMailer(config, logger)
+	if err != nil {
+		logger.Fatal("failed to configure mailer", zap.Error(err))
+	}
+
 	// Connect to PostgreSQL
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
@@ -73,22 +86,49 @@ func Run() {
 	}
 	logger.Info("connected to auth database")
 
+	// User events are delivered to the blog service
+	blogServiceURL := os.Getenv("BLOG_SERVICE_URL")
+	if blogServiceURL == "" {
+		blogServiceURL = "localhost:8082"
+	}
+	blogConn, err := grpc.NewClient(blogServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		logger.Fatal("failed to create blog client", zap.Error(err))
+	}
+	defer blogConn.Close()
+
 	// Create service
 	svc := &Service{
-		config: config,
-		logger: logger,
-		db:     db,
+		config:    config,
+		logger:    logger,
+		db:        db,
+		keyring:   keyring,
+		mailer:    mail,
+		passwords: newPasswordHasher(config),
+		publisher: blogpb.NewBlogServiceClient(blogConn),
+		providers: newOAuthProviders(config, logger),
 	}
 
-	// Ensure users table and seed demo user
+	// Ensure tables exist and seed demo user
+	svc.ensureSchema()
 	svc.seedDemoUser()
 
+	// Rotate signing keys, prune login throttling, abandoned social logins
+	// and stale sign-in links, erase deleted accounts, and publish user
+	// events in the background
+	go svc.rotateKeys()
+	go svc.purgeThrottle()
+	go svc.purgeOAuthStates()
+	go svc.purgeMagicLinks()
+	go svc.purgeDeletedAccounts()
+	go svc.dispatchOutbox()
+
 	// Start gRPC server
 	svc.startGRPCServer()
 }
 
-func (s *Service) seedDemoUser() {
-	s.logger.Info("Ensuring auth users table exists...")
+func (s *Service) ensureSchema() {
+	s.logger.Info("Ensuring auth tables exist...")
 
 	// Enable UUID extension FIRST
 	_, err := s.db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`)
@@ -110,9 +150,252 @@ func (s *Service) seedDemoUser() {
 		s.logger.Error("failed to create auth_users table", zap.Error(err))
 	}
 
+	// Sessions group a chain of rotated refresh tokens (a token family).
+	// Revoking a session invalidates every token issued for it.
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS auth_sessions (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			revoked_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_sessions_user ON auth_sessions(user_id);
+
+		CREATE TABLE IF NOT EXISTS auth_refresh_tokens (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			session_id UUID NOT NULL REFERENCES auth_sessions(id) ON DELETE CASCADE,
+			token_hash CHAR(64) UNIQUE NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_session ON auth_refresh_tokens(session_id);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create session tables", zap.Error(err))
+	}
+
+	// Single-use tokens mailed to users (password reset, email verification).
+	// Only the hash is stored, as with refresh tokens.
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+		CREATE TABLE IF NOT EXISTS auth_user_tokens (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+			purpose VARCHAR(32) NOT NULL,
+			token_hash CHAR(64) UNIQUE NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_user_tokens_user ON auth_user_tokens(user_id, purpose);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create user token tables", zap.Error(err))
+	}
+
+	// TOTP two-factor authentication. totp_secret is set on enrollment and
+	// only enforced once totp_enabled_at is set by a confirmed first code.
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+		CREATE TABLE IF NOT EXISTS auth_recovery_codes (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+			code_hash CHAR(64) NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_recovery_codes_user ON auth_recovery_codes(user_id);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create two-factor tables", zap.Error(err))
+	}
+
+	// Personal access tokens for scripts and API clients
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS auth_access_tokens (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			token_hash CHAR(64) UNIQUE NOT NULL,
+			prefix VARCHAR(16) NOT NULL,
+			scopes TEXT[] NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE,
+			last_used_at TIMESTAMP WITH TIME ZONE,
+			revoked_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_access_tokens_user ON auth_access_tokens(user_id);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create access token table", zap.Error(err))
+	}
+
+	// Failed login attempts, keyed by "email:<address>" or "ip:<address>"
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS auth_throttle (
+			key VARCHAR(320) PRIMARY KEY,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			blocked_until TIMESTAMP WITH TIME ZONE,
+			locked BOOLEAN NOT NULL DEFAULT FALSE
+		);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create throttle table", zap.Error(err))
+	}
+
+	// auth_users is the source of truth for accounts. Changes are recorded
+	// in the outbox in the same transaction and delivered to the blog
+	// service's users projection afterwards.
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS bio TEXT;
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(500);
+
+		CREATE TABLE IF NOT EXISTS auth_outbox (
+			id BIGSERIAL PRIMARY KEY,
+			event_type VARCHAR(50) NOT NULL,
+			user_id UUID NOT NULL,
+			payload JSONB NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			delivered_at TIMESTAMP WITH TIME ZONE,
+			failed_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_outbox_pending ON auth_outbox(id)
+			WHERE delivered_at IS NULL AND failed_at IS NULL;
+
+		-- Set while a dispatcher is sending the event; a crashed dispatcher's
+		-- claim runs out and another picks the event up
+		ALTER TABLE auth_outbox ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE;
+
+		-- One-time data migrations that have run
+		CREATE TABLE IF NOT EXISTS auth_migrations (
+			name VARCHAR(100) PRIMARY KEY,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create outbox table", zap.Error(err))
+	}
+
+	if err := s.backfillUserEvents(context.Background()); err != nil {
+		s.logger.Error("failed to backfill user events", zap.Error(err))
+	}
+
+	// Social login. Accounts created through a provider have no password
+	// until the user sets one with a password reset. auth_oauth_states
+	// holds each pending login's PKCE verifier and nonce between the
+	// redirect to the provider and the callback.
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ALTER COLUMN password_hash DROP NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS auth_identities (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+			provider VARCHAR(50) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			email VARCHAR(255),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			last_login_at TIMESTAMP WITH TIME ZONE,
+			UNIQUE (provider, subject)
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_identities_user ON auth_identities(user_id);
+
+		CREATE TABLE IF NOT EXISTS auth_oauth_states (
+			state_hash CHAR(64) PRIMARY KEY,
+			provider VARCHAR(50) NOT NULL,
+			nonce VARCHAR(64) NOT NULL,
+			code_verifier VARCHAR(128) NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create social login tables", zap.Error(err))
+	}
+
+	// Roles grant permissions beyond a user's own content, such as
+	// moderating comments. Access tokens carry them as a claim.
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
+	`)
+	if err != nil {
+		s.logger.Error("failed to add roles column", zap.Error(err))
+	}
+
+	// Where each session was started, so users can tell their devices apart
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45);
+		ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS user_agent TEXT;
+		ALTER TABLE auth_sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add session device columns", zap.Error(err))
+	}
+
+	// Accounts the user asked to delete are kept until delete_after, then
+	// erased by purgeDeletedAccounts
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS delete_after TIMESTAMP WITH TIME ZONE;
+		CREATE INDEX IF NOT EXISTS idx_auth_users_delete_after ON auth_users(delete_after) WHERE delete_after IS NOT NULL;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add account deletion column", zap.Error(err))
+	}
+
+	// Passwordless sign-in links. They are keyed by email rather than user
+	// because following one is also how a new reader signs up.
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS auth_magic_links (
+			token_hash CHAR(64) PRIMARY KEY,
+			email VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_magic_links_email ON auth_magic_links(LOWER(email), created_at);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create auth_magic_links table", zap.Error(err))
+	}
+
+	// Handles are unique regardless of case. auth_username_history keeps
+	// retired handles so they redirect, and stay reserved, for a while.
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS username VARCHAR(30);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_users_username ON auth_users(LOWER(username));
+
+		CREATE TABLE IF NOT EXISTS auth_username_history (
+			id BIGSERIAL PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+			username VARCHAR(30) NOT NULL,
+			retired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_auth_username_history_username
+			ON auth_username_history(LOWER(username), retired_at);
+	`)
+	if err != nil {
+		s.logger.Error("failed to add usernames", zap.Error(err))
+	}
+
+	// Profile version for optimistic concurrency in UpdateUser
+	_, err = s.db.Exec(`
+		ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add auth_users version column", zap.Error(err))
+	}
+}
+
+func (s *Service) seedDemoUser() {
 	// Hash demo password
 	demoPassword := "demo123"
-	hash, err := bcrypt.GenerateFromPassword([]byte(demoPassword), bcrypt.DefaultCost)
+	hash, err := s.passwords.Hash(demoPassword)
 	if err != nil {
 		s.logger.Error("failed to hash demo password", zap.Error(err))
 		return
@@ -121,10 +404,10 @@ func (s *Service) seedDemoUser() {
 	// Insert demo user (Alice Chen)
 	aliceID := "00000000-0000-0000-0000-000000000001"
 	_, err = s.db.Exec(`
-		INSERT INTO auth_users (id, email, password_hash, name) 
-		VALUES ($1, 'alice@example.com', $2, 'Alice Chen')
+		INSERT INTO auth_users (id, email, password_hash, name, email_verified_at, roles, username)
+		VALUES ($1, 'alice@example.com', $2, 'Alice Chen', NOW(), ARRAY['admin'], 'alice')
 		ON CONFLICT (id) DO UPDATE SET password_hash = $2
-	`, aliceID, string(hash))
+	`, aliceID, hash)
 	if err != nil {
 		s.logger.Error("failed to seed demo user", zap.Error(err))
 	} else {
@@ -163,15 +446,32 @@ func (s *Service) startGRPCServer() {
 func (s *Service) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
 	s.logger.Info("Login request", zap.String("email", req.Email))
 
+	// The address is normalized once, so the account lookup and the
+	// throttle agree on which email is being tried
+	address := normalizeEmail(req.Email)
+
+	// Refuse outright while the email or address is backing off or locked
+	locked, err := s.startLogin(ctx, address, req.IpAddress)
+	if err != nil {
+		s.logger.Info("login throttled", zap.String("email", address), zap.String("ip", req.IpAddress))
+		return nil, err
+	}
+
 	// Query user from database
 	var id, email, passwordHash, name string
-	err := s.db.QueryRowContext(ctx,
-		"SELECT id, email, password_hash, COALESCE(name, '') FROM auth_users WHERE email = $1",
-		req.Email,
-	).Scan(&id, &email, &passwordHash, &name)
+	var emailVerified, totpEnabled bool
+	var roles []string
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id, email, COALESCE(password_hash, ''), COALESCE(name, ''),
+		       email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, roles
+		FROM auth_users WHERE LOWER(email) = $1
+		ORDER BY created_at
+		LIMIT 1
+	`, address).Scan(&id, &email, &passwordHash, &name, &emailVerified, &totpEnabled, pq.Array(&roles))
 
 	if err == sql.ErrNoRows {
-		s.logger.Info("user not found", zap.String("email", req.Email))
+		s.logger.Info("user not found", zap.String("email", address))
+		s.loginFailed(ctx, address, req.IpAddress, locked)
 		return nil, fmt.Errorf("invalid credentials")
 	}
 	if err != nil {
@@ -179,33 +479,56 @@ func (s *Service) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginRes
 		return nil, fmt.Errorf("internal error")
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
-		s.logger.Info("invalid password", zap.String("email", req.Email))
+	// Verify password. Accounts created through social login have none.
+	needsRehash, err := s.passwords.Verify(req.Password, passwordHash)
+	if err != nil {
+		if passwordHash != "" && !errors.Is(err, password.ErrMismatch) {
+			s.logger.Error("failed to verify password hash", zap.String("user_id", id), zap.Error(err))
+		}
+		s.logger.Info("invalid password", zap.String("email", address))
+		s.loginFailed(ctx, address, req.IpAddress, locked)
 		return nil, fmt.Errorf("invalid credentials")
 	}
+	if needsRehash {
+		s.rehashPassword(ctx, id, req.Password, passwordHash)
+	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": id,
-		"email":   email,
-		"name":    name,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-	})
+	// The password alone is not enough: hand back a challenge that
+	// VerifyMFA exchanges for tokens once the second factor checks out
+	if totpEnabled {
+		mfaToken, err := s.signMFAToken(id, email, name)
+		if err != nil {
+			s.logger.Error("failed to sign mfa token", zap.Error(err))
+			return nil, fmt.Errorf("internal error")
+		}
+		// The password was right, so only a bad code counts against the
+		// email from here
+		s.giveBackAttempt(ctx, emailThrottleKey(address))
+		s.giveBackAttempt(ctx, ipThrottleKey(req.IpAddress))
+		s.logger.Info("login awaiting second factor", zap.String("user_id", id))
+		return &pb.LoginResponse{MfaRequired: true, MfaToken: mfaToken}, nil
+	}
 
-	tokenString, err := token.SignedString(jwtSecret)
+	// Start a new session and issue the access/refresh token pair
+	pair, err := s.issueTokenPair(ctx, id, email, name, roles, clientInfo{req.IpAddress, req.UserAgent})
 	if err != nil {
-		s.logger.Error("failed to sign token", zap.Error(err))
+		s.logger.Error("failed to issue tokens", zap.Error(err))
 		return nil, fmt.Errorf("internal error")
 	}
 
+	s.loginSucceeded(ctx, address, req.IpAddress)
+
 	s.logger.Info("login successful", zap.String("user_id", id))
 
 	return &pb.LoginResponse{
-		Token: tokenString,
+		Token:        pair.accessToken,
+		RefreshToken: pair.refreshToken,
+		ExpiresIn:    pair.expiresIn,
 		User: &pb.User{
-			Id:    id,
-			Email: email,
+			Id:            id,
+			Email:         email,
+			EmailVerified: emailVerified,
+			Roles:         roles,
 		},
 	}, nil
 }
@@ -214,17 +537,24 @@ func (s *Service) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.Re
 	s.logger.Info("Register request", zap.String("email", req.Email))
 
 	// Hash password
-	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hash, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		s.logger.Error("failed to hash password", zap.Error(err))
 		return nil, fmt.Errorf("internal error")
 	}
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("internal error")
+	}
+	defer tx.Rollback()
+
 	// Insert user
 	var id string
-	err = s.db.QueryRowContext(ctx,
+	err = tx.QueryRowContext(ctx,
 		"INSERT INTO auth_users (email, password_hash) VALUES ($1, $2) RETURNING id",
-		req.Email, string(hash),
+		req.Email, hash,
 	).Scan(&id)
 
 	if err != nil {
@@ -232,8 +562,34 @@ func (s *Service) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.Re
 		return nil, fmt.Errorf("email already exists or internal error")
 	}
 
+	if req.Username != "" {
+		if err := s.claimUsername(ctx, tx, id, req.Username); err != nil {
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			s.logger.Error("failed to claim username", zap.Error(err))
+			return nil, fmt.Errorf("internal error")
+		}
+	}
+
+	// The blog service learns about the account through the outbox
+	if err := s.enqueueUserEvent(ctx, tx, common.EventUserCreated, id); err != nil {
+		s.logger.Error("failed to enqueue user event", zap.Error(err))
+		return nil, fmt.Errorf("internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit registration", zap.Error(err))
+		return nil, fmt.Errorf("internal error")
+	}
+
 	s.logger.Info("user registered", zap.String("id", id))
 
+	// A failed send is not fatal: the user can ask for another link
+	if err := s.sendVerificationEmail(ctx, id, req.Email); err != nil {
+		s.logger.Error("failed to send verification email", zap.String("user_id", id), zap.Error(err))
+	}
+
 	return &pb.RegisterResponse{
 		Id:    id,
 		Email: req.Email,
@@ -241,15 +597,12 @@ func (s *Service) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.Re
 }
 
 func (s *Service) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.ValidateResponse, error) {
-	// Parse and validate JWT token
-	token, err := jwt.Parse(req.Token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return jwtSecret, nil
-	})
+	if tokens.IsPersonalAccessToken(req.Token) {
+		return s.validatePersonalAccessToken(ctx, req.Token)
+	}
 
-	if err != nil || !token.Valid {
+	claims, err := s.parseAccessToken(req.Token)
+	if err != nil {
 		s.logger.Warn("token validation failed", zap.Error(err))
 		return &pb.ValidateResponse{
 			Valid:  false,
@@ -257,56 +610,154 @@ func (s *Service) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.Va
 		}, nil
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
+	userId := claims.UserID
+	sessionId := claims.SessionID
+
+	// Tokens are only as good as the session they were issued for
+	active, err := s.sessionActive(ctx, sessionId)
+	if err != nil {
+		s.logger.Error("failed to check session", zap.Error(err))
+		return nil, fmt.Errorf("internal error")
+	}
+	if !active {
 		return &pb.ValidateResponse{
 			Valid:  false,
 			UserId: "",
 		}, nil
 	}
 
-	userId, _ := claims["user_id"].(string)
-
 	return &pb.ValidateResponse{
-		Valid:  true,
-		UserId: userId,
+		Valid:       true,
+		UserId:      userId,
+		SessionId:   sessionId,
+		Roles:       claims.Roles,
+		Permissions: tokens.PermissionsFor(claims.Roles),
 	}, nil
 }
 
 func (s *Service) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
 	s.logger.Info("UpdateUser request", zap.String("user_id", req.UserId))
 
-	// Update user in auth_users table (auth service's table)
-	_, err := s.db.ExecContext(ctx, `
-		UPDATE auth_users 
-		SET name = COALESCE(NULLIF($1, ''), name)
-		WHERE id = $4
-	`, req.Name, req.Bio, req.AvatarUrl, req.UserId)
+	// Without a mask, empty fields are left unchanged. Masked fields are
+	// written as given, so they can be cleared, and the rest are ignored.
+	masked := map[string]bool{}
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		switch path {
+		case "name", "bio", "avatar_url", "username":
+			masked[path] = true
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown update_mask path %q", path)
+		}
+	}
+	name, bio, avatarURL, username := req.Name, req.Bio, req.AvatarUrl, req.Username
+	if len(masked) > 0 {
+		if masked["username"] && username == "" {
+			return nil, status.Error(codes.InvalidArgument, "username can't be removed")
+		}
+		if !masked["name"] {
+			name = ""
+		}
+		if !masked["bio"] {
+			bio = ""
+		}
+		if !masked["avatar_url"] {
+			avatarURL = ""
+		}
+		if !masked["username"] {
+			username = ""
+		}
+	}
 
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		s.logger.Error("failed to update user", zap.Error(err))
+		s.logger.Error("failed to begin transaction", zap.Error(err))
 		return nil, fmt.Errorf("failed to update user")
 	}
+	defer tx.Rollback()
+
+	// Lock the profile so it can't change between the check and the update
+	if req.Version != 0 {
+		var current int64
+		err := tx.QueryRowContext(ctx, "SELECT version FROM auth_users WHERE id = $1 FOR UPDATE", req.UserId).Scan(&current)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		if err != nil {
+			s.logger.Error("failed to look up user", zap.Error(err))
+			return nil, fmt.Errorf("failed to update user")
+		}
+		if current != req.Version {
+			return nil, common.VersionConflictError("profile", current)
+		}
+	}
 
-	// Fetch updated user from auth_users
-	var user pb.User
-	var name sql.NullString
-	err = s.db.QueryRowContext(ctx, `
-		SELECT id, email, name FROM auth_users WHERE id = $1
-	`, req.UserId).Scan(&user.Id, &user.Email, &name)
+	if username != "" {
+		err := s.claimUsername(ctx, tx, req.UserId, username)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		if err != nil {
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			s.logger.Error("failed to claim username", zap.Error(err))
+			return nil, fmt.Errorf("failed to update user")
+		}
+	}
 
-	if err != nil {
-		s.logger.Error("failed to fetch updated user", zap.Error(err))
+	var user pb.User
+	var newName, newBio, newAvatarURL sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE auth_users
+		SET name = CASE WHEN $5 THEN NULLIF($1, '') ELSE COALESCE(NULLIF($1, ''), name) END,
+		    bio = CASE WHEN $6 THEN NULLIF($2, '') ELSE COALESCE(NULLIF($2, ''), bio) END,
+		    avatar_url = CASE WHEN $7 THEN NULLIF($3, '') ELSE COALESCE(NULLIF($3, ''), avatar_url) END,
+		    version = version + 1
+		WHERE id = $4
+		RETURNING id, email, name, bio, avatar_url, email_verified_at IS NOT NULL, COALESCE(username, ''), version
+	`, name, bio, avatarURL, req.UserId, masked["name"], masked["bio"], masked["avatar_url"]).Scan(
+		&user.Id, &user.Email, &newName, &newBio, &newAvatarURL, &user.EmailVerified, &user.Username, &user.Version,
+	)
+	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
 	}
+	if err != nil {
+		s.logger.Error("failed to update user", zap.Error(err))
+		return nil, fmt.Errorf("failed to update user")
+	}
+
+	// Propagate the new profile to the blog service
+	if err := s.enqueueUserEvent(ctx, tx, common.EventUserUpdated, req.UserId); err != nil {
+		s.logger.Error("failed to enqueue user event", zap.Error(err))
+		return nil, fmt.Errorf("failed to update user")
+	}
 
-	if name.Valid {
-		user.Name = name.String
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit user update", zap.Error(err))
+		return nil, fmt.Errorf("failed to update user")
 	}
 
-	// Note: bio and avatar_url are stored in blog service's users table
-	// For a complete profile update, the gateway should call both services
-	// This is a known limitation of the current architecture
+	user.Name = newName.String
+	user.Bio = newBio.String
+	user.AvatarUrl = newAvatarURL.String
 
 	return &pb.UpdateUserResponse{User: &user}, nil
 }
+
+func (s *Service) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	user := pb.User{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, email, COALESCE(name, ''), COALESCE(bio, ''), COALESCE(avatar_url, ''),
+		       email_verified_at IS NOT NULL, roles, COALESCE(username, ''), version
+		FROM auth_users WHERE id = $1
+	`, req.UserId).Scan(&user.Id, &user.Email, &user.Name, &user.Bio, &user.AvatarUrl,
+		&user.EmailVerified, pq.Array(&user.Roles), &user.Username, &user.Version)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return &pb.GetUserResponse{User: &user}, nil
+}
@@ -0,0 +1,1040 @@
+package auth
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"project/pkg/common"
+	"project/pkg/mailer"
+	"project/pkg/oidc"
+	"project/pkg/oidc/oidctest"
+	"project/pkg/password"
+	pb "project/pkg/proto/auth"
+	blogpb "project/pkg/proto/blog"
+	"project/pkg/tokens"
+	"project/pkg/totp"
+)
+
+// testHasher keeps Argon2 cheap so tests stay fast
+var testHasher = password.NewArgon2id(password.Argon2Params{Memory: 64, Iterations: 1})
+
+var loginColumns = []string{"id", "email", "password_hash", "name", "email_verified", "totp_enabled", "roles"}
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock, *mailer.MemoryMailer) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	config := &common.Config{
+		Auth: common.AuthConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 24 * time.Hour,
+		},
+		AppURL: "http://app.test",
+	}
+	keyring, err := tokens.NewKeyring("", tokens.AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	mail := &mailer.MemoryMailer{}
+	return &Service{config: config, logger: zap.NewNop(), db: db, keyring: keyring, mailer: mail, passwords: testHasher}, mock, mail
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := testHasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "Valid credentials",
+			email:    "test@example.com",
+			password: "password",
+			wantErr:  false,
+		},
+		{
+			name:     "Email in another case",
+			email:    " Test@Example.COM",
+			password: "password",
+			wantErr:  false,
+		},
+		{
+			name:     "Invalid credentials",
+			email:    "wrong@example.com",
+			password: "password",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock, _ := newTestService(t)
+
+			// The account and the throttle are both looked up by the normalized address
+			address := "test@example.com"
+			if tt.wantErr {
+				address = "wrong@example.com"
+			}
+			expectAttempt(mock, emailThrottleKey(address), false)
+			userQuery := mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, COALESCE(password_hash, '')")).WithArgs(address)
+			if tt.wantErr {
+				userQuery.WillReturnRows(sqlmock.NewRows(loginColumns))
+			} else {
+				userQuery.WillReturnRows(sqlmock.NewRows(loginColumns).
+					AddRow("user-1", address, hash, "Test User", true, false, "{}"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO auth_sessions")).
+					WithArgs("user-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("session-1"))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_refresh_tokens")).
+					WithArgs("session-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_users SET delete_after = NULL")).
+					WithArgs("user-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectExec(regexp.QuoteMeta("DELETE FROM auth_throttle")).
+					WithArgs(emailThrottleKey(address)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+
+			req := &pb.LoginRequest{
+				Email:    tt.email,
+				Password: tt.password,
+			}
+			resp, err := svc.Login(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Login() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && resp.Token == "" {
+				t.Errorf("Login() token is empty")
+			}
+			if !tt.wantErr && resp.RefreshToken == "" {
+				t.Errorf("Login() refresh token is empty")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	svc, mock, mail := newTestService(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO auth_users")).
+		WithArgs("new@example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-2"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_outbox")).
+		WithArgs(common.EventUserCreated, "user-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUserToken(mock, "user-2", purposeVerifyEmail)
+
+	req := &pb.RegisterRequest{
+		Email:    "new@example.com",
+		Password: "password",
+	}
+
+	resp, err := svc.Register(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if resp.Email != req.Email {
+		t.Errorf("Register() email = %v, want %v", resp.Email, req.Email)
+	}
+	if msgs := mail.Messages(); len(msgs) != 1 || msgs[0].To != req.Email {
+		t.Errorf("Register() sent %+v, want one verification email", msgs)
+	}
+}
+
+// expectAttempt expects an attempt to be counted against key, which isn't
+// blocked. locked reports whether the attempt locks the key if it fails.
+func expectAttempt(mock sqlmock.Sqlmock, key string, locked bool) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_throttle (key)")).
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_throttle t")).
+		WithArgs(key, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(locked))
+}
+
+// expectGiveBack expects the attempt counted against key to be uncounted
+func expectGiveBack(mock sqlmock.Sqlmock, key string) {
+	mock.ExpectExec(regexp.QuoteMeta("SET failures = GREATEST(failures - 1, 0)")).
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectUserToken(mock sqlmock.Sqlmock, userID, purpose string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_user_tokens SET used_at")).
+		WithArgs(userID, purpose).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_user_tokens")).
+		WithArgs(userID, purpose, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestPasswordReset(t *testing.T) {
+	svc, mock, mail := newTestService(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email FROM auth_users")).
+		WithArgs("alice@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow("user-1", "alice@example.com"))
+	expectUserToken(mock, "user-1", purposePasswordReset)
+
+	if _, err := svc.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Email: "alice@example.com"}); err != nil {
+		t.Fatalf("RequestPasswordReset() error = %v", err)
+	}
+
+	msgs := mail.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(msgs))
+	}
+	_, token, ok := strings.Cut(msgs[0].Text, "http://app.test/reset-password?token=")
+	if !ok {
+		t.Fatalf("reset email has no link:\n%s", msgs[0].Text)
+	}
+	token = strings.Fields(token)[0]
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_user_tokens SET used_at")).
+		WithArgs(hashToken(token), purposePasswordReset).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_users")).
+		WithArgs(sqlmock.AnyArg(), "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("alice@example.com"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_sessions SET revoked_at")).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM auth_throttle")).
+		WithArgs(emailThrottleKey("alice@example.com")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if _, err := svc.ResetPassword(ctx, &pb.ResetPasswordRequest{Token: token, NewPassword: "n3w-Password"}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	// A spent token matches no rows
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_user_tokens SET used_at")).
+		WithArgs(hashToken(token), purposePasswordReset).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectRollback()
+
+	_, err := svc.ResetPassword(ctx, &pb.ResetPasswordRequest{Token: token, NewPassword: "another-Pass1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ResetPassword() with used token error = %v, want InvalidArgument", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM auth_refresh_tokens rt")).
+		WithArgs(hashToken("stolen")).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "session_id", "expires_at", "used_at", "revoked_at", "user_id", "email", "name", "roles",
+		}).AddRow("rt-1", "session-1", time.Now().Add(time.Hour), time.Now().Add(-time.Minute), nil,
+			"user-1", "test@example.com", "Test User", "{}"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_sessions SET revoked_at")).
+		WithArgs("session-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := svc.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "stolen"})
+	if err == nil {
+		t.Fatal("RefreshToken() with a reused token should fail")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_sessions SET revoked_at")).
+		WithArgs("session-2", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err := svc.RevokeSession(context.Background(), &pb.RevokeSessionRequest{UserId: "user-1", SessionId: "session-2"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("RevokeSession() error = %v, want NotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+func TestClaimUsernameHeldByPreviousOwner(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT username FROM auth_users WHERE id = $1 FOR UPDATE")).
+		WithArgs("user-2").
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("bob"))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM auth_username_history")).
+		WithArgs("Alice", "user-2", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	tx, err := svc.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// alice was given up recently, so only its old owner may take it back
+	err = svc.claimUsername(context.Background(), tx, "user-2", "Alice")
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("claimUsername() error = %v, want AlreadyExists", err)
+	}
+	if err := svc.claimUsername(context.Background(), tx, "user-2", "admin"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("claimUsername(reserved) error = %v, want InvalidArgument", err)
+	}
+	tx.Rollback()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateUserStaleVersion(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM auth_users WHERE id = $1 FOR UPDATE")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectRollback()
+
+	_, err := svc.UpdateUser(context.Background(), &pb.UpdateUserRequest{UserId: "user-1", Name: "Alice", Version: 3})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("UpdateUser() error = %v, want FailedPrecondition", err)
+	}
+	if current, ok := common.ConflictVersion(err); !ok || current != 4 {
+		t.Errorf("ConflictVersion() = %d, %v, want 4, true", current, ok)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	columns := []string{"id", "email", "name", "bio", "avatar_url", "email_verified", "roles", "username", "version"}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM auth_users WHERE id = $1")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("user-1", "alice@example.com", "Alice", "", "", true, "{user}", "alice", 6))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM auth_users WHERE id = $1")).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	resp, err := svc.GetUser(context.Background(), &pb.GetUserRequest{UserId: "user-1"})
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if resp.User.Version != 6 || resp.User.Username != "alice" {
+		t.Errorf("GetUser() = %+v, want version 6 and username alice", resp.User)
+	}
+
+	_, err = svc.GetUser(context.Background(), &pb.GetUserRequest{UserId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetUser() error = %v, want NotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdateUserMaskClearsOnlyMaskedFields(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_users")).
+		WithArgs("", "", "", "user-1", false, true, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "bio", "avatar_url", "verified", "username", "version"}).
+			AddRow("user-1", "alice@example.com", "Alice", nil, nil, true, "alice", 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_outbox")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	resp, err := svc.UpdateUser(context.Background(), &pb.UpdateUserRequest{
+		UserId:     "user-1",
+		Name:       "Not Written",
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"bio"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if resp.User.Name != "Alice" || resp.User.Bio != "" {
+		t.Errorf("UpdateUser() user = %v, want name kept and bio cleared", resp.User)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+
+	_, err = svc.UpdateUser(context.Background(), &pb.UpdateUserRequest{
+		UserId:     "user-1",
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateUser() with unknown path error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestLoginRehashesLegacyPassword(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectAttempt(mock, emailThrottleKey("old@example.com"), false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, COALESCE(password_hash, '')")).
+		WithArgs("old@example.com").
+		WillReturnRows(sqlmock.NewRows(loginColumns).
+			AddRow("user-1", "old@example.com", string(legacy), "Old User", true, true, "{}"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_users SET password_hash")).
+		WithArgs(argon2idHash{}, "user-1", string(legacy)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectGiveBack(mock, emailThrottleKey("old@example.com"))
+
+	if _, err := svc.Login(context.Background(), &pb.LoginRequest{Email: "old@example.com", Password: "password"}); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// argon2idHash matches an Argon2id hash of "password"
+type argon2idHash struct{}
+
+func (argon2idHash) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	if !ok || !strings.HasPrefix(hash, "$argon2id$") {
+		return false
+	}
+	needsRehash, err := testHasher.Verify("password", hash)
+	return err == nil && !needsRehash
+}
+
+func TestLoginWithTOTP(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+	ctx := context.Background()
+	hash, _ := testHasher.Hash("password")
+	secret, _ := totp.GenerateSecret()
+
+	expectAttempt(mock, emailThrottleKey("mfa@example.com"), false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, COALESCE(password_hash, '')")).
+		WithArgs("mfa@example.com").
+		WillReturnRows(sqlmock.NewRows(loginColumns).
+			AddRow("user-1", "mfa@example.com", hash, "MFA User", true, true, "{}"))
+	// A right password gives its attempt back while the code is pending
+	expectGiveBack(mock, emailThrottleKey("mfa@example.com"))
+
+	resp, err := svc.Login(ctx, &pb.LoginRequest{Email: "mfa@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !resp.MfaRequired || resp.MfaToken == "" || resp.Token != "" {
+		t.Fatalf("Login() = %+v, want an mfa challenge and no access token", resp)
+	}
+
+	// The challenge must not work as an access token
+	if _, err := svc.parseAccessToken(resp.MfaToken); err == nil {
+		t.Error("mfa token was accepted as an access token")
+	}
+
+	code, _ := totp.Code(secret, time.Now())
+	expectAttempt(mock, emailThrottleKey("mfa@example.com"), false)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT totp_secret")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "totp_last_step"}).AddRow(secret, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_users SET totp_last_step")).
+		WithArgs(totp.Step(time.Now()), "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT email_verified_at")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"verified", "roles"}).AddRow(true, "{moderator}"))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO auth_sessions")).
+		WithArgs("user-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("session-1"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_refresh_tokens")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_users SET delete_after = NULL")).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM auth_throttle")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	login, err := svc.VerifyMFA(ctx, &pb.VerifyMFARequest{MfaToken: resp.MfaToken, Code: code})
+	if err != nil {
+		t.Fatalf("VerifyMFA() error = %v", err)
+	}
+	if login.Token == "" || login.RefreshToken == "" {
+		t.Errorf("VerifyMFA() = %+v, want tokens", login)
+	}
+	// Roles reach the access token, where the gateway reads them
+	if claims, err := svc.parseAccessToken(login.Token); err != nil || !slices.Equal(claims.Roles, []string{tokens.RoleModerator}) {
+		t.Errorf("access token roles = %v (err %v), want [moderator]", claims, err)
+	}
+
+	// Replaying the same code within its window is refused, and counts as a failure
+	expectAttempt(mock, emailThrottleKey("mfa@example.com"), false)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT totp_secret")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "totp_last_step"}).AddRow(secret, totp.Step(time.Now())))
+	mock.ExpectRollback()
+
+	_, err = svc.VerifyMFA(ctx, &pb.VerifyMFARequest{MfaToken: resp.MfaToken, Code: code})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("VerifyMFA() replay error = %v, want Unauthenticated", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestThrottlePolicy(t *testing.T) {
+	tests := []struct {
+		failures   int
+		wantDelay  time.Duration
+		wantLocked bool
+	}{
+		{3, 0, false},
+		{4, time.Second, false},
+		{5, 2 * time.Second, false},
+		{9, 32 * time.Second, false},
+		{10, time.Hour, true},
+	}
+	for _, tt := range tests {
+		delay, locked := emailThrottle.penalty(tt.failures)
+		if delay != tt.wantDelay || locked != tt.wantLocked {
+			t.Errorf("penalty(%d) = %v, %v; want %v, %v", tt.failures, delay, locked, tt.wantDelay, tt.wantLocked)
+		}
+	}
+
+	if delay, _ := ipThrottle.penalty(40); delay != ipThrottle.maxDelay {
+		t.Errorf("ip penalty(40) = %v, want capped at %v", delay, ipThrottle.maxDelay)
+	}
+	if _, locked := ipThrottle.penalty(1000); locked {
+		t.Error("ip throttle locked; addresses should never lock")
+	}
+
+	// The schedule handed to the database agrees with penalty
+	for _, policy := range []throttlePolicy{emailThrottle, ipThrottle} {
+		schedule := policy.schedule()
+		for failures := 1; failures <= len(schedule)+5; failures++ {
+			delay, _ := policy.penalty(failures)
+			got := schedule[min(failures, len(schedule))-1]
+			if got != int64(delay.Seconds()) {
+				t.Errorf("schedule()[%d] = %d, want %v", failures, got, delay)
+			}
+		}
+	}
+}
+
+func TestLoginLocked(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	// A blocked key isn't counted, and the address isn't tried at all
+	key := emailThrottleKey("alice@example.com")
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_throttle (key)")).
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_throttle t")).
+		WithArgs(key, sqlmock.AnyArg(), emailThrottle.lockAfter, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT blocked_until, locked FROM auth_throttle")).
+		WithArgs(key).
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_until", "locked"}).AddRow(time.Now().Add(30*time.Minute), true))
+
+	_, err := svc.Login(context.Background(), &pb.LoginRequest{Email: "alice@example.com", Password: "password", IpAddress: "10.0.0.1"})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("Login() error = %v, want FailedPrecondition", err)
+	}
+
+	var reason string
+	var retry time.Duration
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			reason = d.Reason
+		case *errdetails.RetryInfo:
+			retry = d.RetryDelay.AsDuration()
+		}
+	}
+	if reason != "ACCOUNT_LOCKED" || retry < 29*time.Minute {
+		t.Errorf("Login() details reason = %q, retry = %v", reason, retry)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestLoginFailureLocksAccount(t *testing.T) {
+	svc, mock, mail := newTestService(t)
+	hash, _ := testHasher.Hash("password")
+
+	// The attempt that reaches the limit locks the email and, when it
+	// fails, mails the owner an unlock link. The address keeps counting.
+	expectAttempt(mock, emailThrottleKey("alice@example.com"), true)
+	expectAttempt(mock, ipThrottleKey("10.0.0.1"), false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email, COALESCE(password_hash, '')")).
+		WithArgs("alice@example.com").
+		WillReturnRows(sqlmock.NewRows(loginColumns).
+			AddRow("user-1", "Alice@example.com", hash, "Alice", true, false, "{}"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, email FROM auth_users WHERE LOWER(email) = LOWER($1)")).
+		WithArgs("alice@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow("user-1", "Alice@example.com"))
+	expectUserToken(mock, "user-1", purposeUnlockAccount)
+
+	_, err := svc.Login(context.Background(), &pb.LoginRequest{Email: "Alice@example.com", Password: "wrong", IpAddress: "10.0.0.1"})
+	if err == nil {
+		t.Fatal("Login() with a wrong password succeeded")
+	}
+	if msgs := mail.Messages(); len(msgs) != 1 || msgs[0].To != "Alice@example.com" {
+		t.Errorf("Login() sent %+v, want one unlock email", msgs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+type fakePublisher struct {
+	errs     map[int64]error
+	received []int64
+}
+
+func (f *fakePublisher) ApplyUserEvent(ctx context.Context, in *blogpb.ApplyUserEventRequest, opts ...grpc.CallOption) (*blogpb.ApplyUserEventResponse, error) {
+	f.received = append(f.received, in.Event.Id)
+	if err := f.errs[in.Event.Id]; err != nil {
+		return nil, err
+	}
+	return &blogpb.ApplyUserEventResponse{Applied: true}, nil
+}
+
+func TestDeliverOutbox(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+	publisher := &fakePublisher{errs: map[int64]error{
+		2: status.Error(codes.AlreadyExists, "email taken"),
+		3: status.Error(codes.Unavailable, "connection refused"),
+	}}
+	svc.publisher = publisher
+
+	payload := []byte(`{"email":"a@example.com","name":"A","bio":"","avatar_url":""}`)
+	now := time.Now()
+	// The batch is claimed and committed before anything is sent
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_outbox SET claimed_until = NOW()")).
+		WithArgs(outboxBatchSize, int(outboxClaimTTL.Seconds())).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_type", "user_id", "payload", "created_at"}).
+			AddRow(3, common.EventUserUpdated, "user-1", payload, now).
+			AddRow(1, common.EventUserCreated, "user-1", payload, now).
+			AddRow(4, common.EventUserUpdated, "user-1", payload, now).
+			AddRow(2, common.EventUserCreated, "user-2", payload, now))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_outbox SET delivered_at")).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_outbox SET failed_at")).
+		WithArgs(sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_outbox SET attempts")).
+		WithArgs(sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_outbox SET claimed_until = NULL WHERE id = ANY($1)")).
+		WithArgs("{1,2,3,4}").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	delivered, err := svc.deliverOutbox(context.Background())
+	if status.Code(errors.Unwrap(err)) != codes.Unavailable {
+		t.Fatalf("deliverOutbox() error = %v, want Unavailable", err)
+	}
+	if delivered != 1 {
+		t.Errorf("deliverOutbox() delivered = %d, want 1", delivered)
+	}
+	// Event 4 must wait for event 3 so the blog never sees them out of order
+	if want := []int64{1, 2, 3}; !slices.Equal(publisher.received, want) {
+		t.Errorf("published %v, want %v", publisher.received, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBackfillUserEventsRunsOnce(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_migrations")).
+		WithArgs(migrationBackfillUserEvents).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_outbox")).
+		WithArgs(common.EventUserCreated).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+	if err := svc.backfillUserEvents(ctx); err != nil {
+		t.Fatalf("backfillUserEvents() error = %v", err)
+	}
+
+	// Once marked done, a restart publishes nothing, even though the
+	// delivered events it would have checked against may be pruned
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_migrations")).
+		WithArgs(migrationBackfillUserEvents).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if err := svc.backfillUserEvents(ctx); err != nil {
+		t.Fatalf("backfillUserEvents() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// captureArg matches any argument and remembers it
+type captureArg struct{ value string }
+
+func (a *captureArg) Match(v driver.Value) bool {
+	a.value, _ = v.(string)
+	return true
+}
+
+func TestOAuthLoginCreatesAccount(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+	provider := oidctest.NewServer("client", "secret")
+	defer provider.Close()
+	provider.SetUser(oidctest.User{Subject: "gh-7", Email: "grace@example.com", EmailVerified: true, Name: "Grace"})
+	svc.providers = map[string]*oidc.Provider{
+		"mock": oidc.NewProvider(oidc.Config{
+			Name:         "mock",
+			Issuer:       provider.Issuer(),
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RedirectURL:  "http://api.test/api/v1/auth/oauth/mock/callback",
+		}, nil),
+	}
+	ctx := context.Background()
+
+	nonce, verifier := &captureArg{}, &captureArg{}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_oauth_states")).
+		WithArgs(sqlmock.AnyArg(), "mock", nonce, verifier, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	start, err := svc.StartOAuth(ctx, &pb.StartOAuthRequest{Provider: "mock"})
+	if err != nil {
+		t.Fatalf("StartOAuth() error = %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(start.AuthorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callback.Query().Get("state"); got != start.State {
+		t.Fatalf("provider returned state %q, want %q", got, start.State)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM auth_oauth_states")).
+		WithArgs(hashToken(start.State), "mock").
+		WillReturnRows(sqlmock.NewRows([]string{"nonce", "code_verifier"}).AddRow(nonce.value, verifier.value))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_identities")).
+		WithArgs("mock", "gh-7").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "email_verified", "totp_enabled"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM auth_users WHERE LOWER(email) = LOWER($1)")).
+		WithArgs("grace@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "email_verified", "totp_enabled"}))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO auth_users")).
+		WithArgs("grace@example.com", "Grace", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow("user-3", "grace@example.com", "Grace"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_outbox")).
+		WithArgs(common.EventUserCreated, "user-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_identities")).
+		WithArgs("user-3", "mock", "gh-7", "grace@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO auth_sessions")).
+		WithArgs("user-3", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("session-3"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_refresh_tokens")).
+		WithArgs("session-3", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_users SET delete_after = NULL")).
+		WithArgs("user-3").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	login, err := svc.CompleteOAuth(ctx, &pb.CompleteOAuthRequest{
+		Provider: "mock",
+		Code:     callback.Query().Get("code"),
+		State:    start.State,
+	})
+	if err != nil {
+		t.Fatalf("CompleteOAuth() error = %v", err)
+	}
+	if login.Token == "" || login.User.Id != "user-3" || !login.User.EmailVerified {
+		t.Errorf("CompleteOAuth() = %+v", login)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// Replaying the callback finds no pending login
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM auth_oauth_states")).
+		WillReturnRows(sqlmock.NewRows([]string{"nonce", "code_verifier"}))
+	_, err = svc.CompleteOAuth(ctx, &pb.CompleteOAuthRequest{Provider: "mock", Code: "x", State: start.State})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("replayed CompleteOAuth() error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestDeleteAccountConfirmsOwner(t *testing.T) {
+	hash, _ := testHasher.Hash("password")
+	expectUser := func(mock sqlmock.Sqlmock, passwordHash string, totpEnabled bool) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(password_hash, ''), totp_enabled_at IS NOT NULL FROM auth_users")).
+			WithArgs("user-1").
+			WillReturnRows(sqlmock.NewRows([]string{"password_hash", "totp_enabled"}).AddRow(passwordHash, totpEnabled))
+	}
+	expectSession := func(mock sqlmock.Sqlmock, signedInAt time.Time) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT created_at FROM auth_sessions")).
+			WithArgs("session-1", "user-1").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(signedInAt))
+	}
+
+	tests := []struct {
+		name   string
+		req    *pb.DeleteAccountRequest
+		expect func(sqlmock.Sqlmock)
+		want   codes.Code
+	}{
+		{
+			name: "wrong password",
+			req:  &pb.DeleteAccountRequest{UserId: "user-1", Password: "wrong"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectUser(mock, hash, false)
+				mock.ExpectRollback()
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			// Passwordless accounts with 2FA confirm with a code
+			name: "wrong recovery code",
+			req:  &pb.DeleteAccountRequest{UserId: "user-1", Code: "aaaaa-bbbbb", SessionId: "session-1"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectUser(mock, "", true)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT totp_secret")).
+					WithArgs("user-1").
+					WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "totp_last_step"}).AddRow("JBSWY3DPEHPK3PXP", 0))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_recovery_codes SET used_at")).
+					WithArgs("user-1", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			// Without either, only a session that just signed in will do
+			name: "stale session",
+			req:  &pb.DeleteAccountRequest{UserId: "user-1", SessionId: "session-1"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectUser(mock, "", false)
+				expectSession(mock, time.Now().Add(-time.Hour))
+				mock.ExpectRollback()
+			},
+			want: codes.FailedPrecondition,
+		},
+		{
+			name: "no session",
+			req:  &pb.DeleteAccountRequest{UserId: "user-1"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectUser(mock, "", false)
+				mock.ExpectRollback()
+			},
+			want: codes.FailedPrecondition,
+		},
+		{
+			name: "fresh session",
+			req:  &pb.DeleteAccountRequest{UserId: "user-1", SessionId: "session-1"},
+			expect: func(mock sqlmock.Sqlmock) {
+				expectUser(mock, "", false)
+				expectSession(mock, time.Now().Add(-time.Minute))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_users SET delete_after")).
+					WithArgs(sqlmock.AnyArg(), "user-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_sessions SET revoked_at")).
+					WithArgs("user-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_access_tokens SET revoked_at")).
+					WithArgs("user-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			want: codes.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock, _ := newTestService(t)
+			tt.expect(mock)
+
+			_, err := svc.DeleteAccount(context.Background(), tt.req)
+			if status.Code(err) != tt.want {
+				t.Errorf("DeleteAccount() error = %v, want %v", err, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestEraseDeletedAccountsPublishesFirst(t *testing.T) {
+	svc, mock, _ := newTestService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM auth_users")).
+		WithArgs(accountPurgeBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	// The event snapshots the row, so it must be queued before the delete
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_outbox")).
+		WithArgs(common.EventUserDeleted, "user-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM auth_users")).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := svc.eraseDeletedAccounts(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("eraseDeletedAccounts() = %d, %v, want 1, nil", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestMagicLink(t *testing.T) {
+	svc, mock, mail := newTestService(t)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), MIN(created_at) FROM auth_magic_links")).
+		WithArgs("reader@example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(0, nil))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE auth_magic_links SET used_at")).
+		WithArgs("reader@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO auth_magic_links")).
+		WithArgs(sqlmock.AnyArg(), "reader@example.com", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if _, err := svc.RequestMagicLink(ctx, &pb.RequestMagicLinkRequest{Email: "reader@example.com"}); err != nil {
+		t.Fatalf("RequestMagicLink() error = %v", err)
+	}
+	msgs := mail.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(msgs))
+	}
+	_, token, ok := strings.Cut(msgs[0].Text, "http://app.test/magic-link?token=")
+	if !ok {
+		t.Fatalf("magic link email has no link:\n%s", msgs[0].Text)
+	}
+	token = strings.Fields(token)[0]
+
+	// The account has a second factor, so the link only gets as far as the challenge
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE auth_magic_links SET used_at")).
+		WithArgs(hashToken(token)).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("reader@example.com"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM auth_users WHERE LOWER(email) = LOWER($1)")).
+		WithArgs("reader@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "email_verified", "totp_enabled", "roles"}).
+			AddRow("user-1", "reader@example.com", "Reader", true, true, "{}"))
+	mock.ExpectCommit()
+
+	resp, err := svc.ConsumeMagicLink(ctx, &pb.ConsumeMagicLinkRequest{Token: token})
+	if err != nil {
+		t.Fatalf("ConsumeMagicLink() error = %v", err)
+	}
+	if !resp.MfaRequired || resp.Token != "" {
+		t.Errorf("ConsumeMagicLink() = %+v, want an mfa challenge", resp)
+	}
+
+	// Too many links for one inbox
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), MIN(created_at) FROM auth_magic_links")).
+		WithArgs("reader@example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(magicLinksPerWindow, time.Now().Add(-time.Minute)))
+	mock.ExpectRollback()
+
+	_, err = svc.RequestMagicLink(ctx, &pb.RequestMagicLinkRequest{Email: "reader@example.com"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("RequestMagicLink() past the limit error = %v, want ResourceExhausted", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/auth"
+)
+
+func (s *Service) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	// A session whose refresh tokens have all expired can no longer be
+	// used, so it is not worth showing
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, COALESCE(s.user_agent, ''), COALESCE(s.ip_address, ''),
+		       s.created_at, COALESCE(s.last_seen_at, s.created_at)
+		FROM auth_sessions s
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM auth_refresh_tokens rt
+			WHERE rt.session_id = s.id AND rt.used_at IS NULL AND rt.expires_at > NOW()
+		  )
+		ORDER BY COALESCE(s.last_seen_at, s.created_at) DESC
+	`, req.UserId)
+	if err != nil {
+		s.logger.Error("failed to list sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	var sessions []*pb.Session
+	for rows.Next() {
+		var session pb.Session
+		var createdAt, lastSeenAt time.Time
+		if err := rows.Scan(&session.Id, &session.UserAgent, &session.IpAddress, &createdAt, &lastSeenAt); err != nil {
+			s.logger.Error("failed to scan session", zap.Error(err))
+			continue
+		}
+		session.CreatedAt = createdAt.Unix()
+		session.LastSeenAt = lastSeenAt.Unix()
+		session.Current = session.Id == req.CurrentSessionId
+		sessions = append(sessions, &session)
+	}
+
+	return &pb.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+func (s *Service) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	// Comparing as text keeps malformed IDs a plain "not found"
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE auth_sessions SET revoked_at = NOW()
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, req.SessionId, req.UserId)
+	if err != nil {
+		s.logger.Error("failed to revoke session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+
+	s.logger.Info("session revoked", zap.String("user_id", req.UserId), zap.String("session_id", req.SessionId))
+
+	return &pb.RevokeSessionResponse{Success: true}, nil
+}
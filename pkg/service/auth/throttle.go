@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"project/pkg/mailer"
+	pb "project/pkg/proto/auth"
+)
+
+const (
+	purposeUnlockAccount = "unlock_account"
+	unlockTokenTTL       = time.Hour
+
+	// Failures older than this no longer count
+	throttleWindow = time.Hour
+)
+
+// throttlePolicy turns a run of failures into a delay before the next
+// attempt is allowed, and optionally a lockout
+type throttlePolicy struct {
+	freeAttempts int           // Failures allowed before any delay
+	baseDelay    time.Duration // Delay after the first failure past freeAttempts, doubled each time
+	maxDelay     time.Duration
+	lockAfter    int // Failures that lock the key outright; 0 never locks
+	lockDuration time.Duration
+}
+
+var (
+	// Accounts lock after a sustained attempt on one email
+	emailThrottle = throttlePolicy{
+		freeAttempts: 3,
+		baseDelay:    time.Second,
+		maxDelay:     5 * time.Minute,
+		lockAfter:    10,
+		lockDuration: time.Hour,
+	}
+	// Addresses are shared behind NATs, so they only ever slow down
+	ipThrottle = throttlePolicy{
+		freeAttempts: 10,
+		baseDelay:    time.Second,
+		maxDelay:     5 * time.Minute,
+	}
+)
+
+// penalty returns how long to block after the given number of failures
+func (p throttlePolicy) penalty(failures int) (time.Duration, bool) {
+	if p.lockAfter > 0 && failures >= p.lockAfter {
+		return p.lockDuration, true
+	}
+	if failures <= p.freeAttempts {
+		return 0, false
+	}
+	delay := p.baseDelay
+	for i := p.freeAttempts + 1; i < failures && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxDelay), false
+}
+
+// schedule lists the delay in seconds that follows each failure count,
+// starting from one. Counts past the end get the last delay.
+func (p throttlePolicy) schedule() []int64 {
+	var delays []int64
+	for failures := 1; ; failures++ {
+		delay, locked := p.penalty(failures)
+		delays = append(delays, int64(delay.Seconds()))
+		if locked || delay >= p.maxDelay {
+			return delays
+		}
+	}
+}
+
+// normalizeEmail is the form of an address used to find its account and
+// throttle it
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func emailThrottleKey(email string) string {
+	return "email:" + normalizeEmail(email)
+}
+
+func ipThrottleKey(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	return "ip:" + ip
+}
+
+// takeAttempt counts an attempt against key unless the key is blocked, in
+// which case it returns a status error. The check and the count are one
+// statement, so parallel attempts can't all slip past the same check. The
+// attempt is counted as a failure up front and given back if it succeeds.
+// It returns true when this attempt locks the key should it fail.
+func (s *Service) takeAttempt(ctx context.Context, key string, policy throttlePolicy) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO auth_throttle (key) VALUES ($1) ON CONFLICT (key) DO NOTHING", key)
+	if err != nil {
+		s.logger.Error("failed to check login throttle", zap.Error(err))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+
+	// Failures older than the window start the count over. The block set
+	// here is the one the next attempt faces.
+	var locked bool
+	err = s.db.QueryRowContext(ctx, `
+		UPDATE auth_throttle t
+		SET (failures, last_failure_at, blocked_until, locked) = (
+			SELECT n, NOW(),
+			       NOW() + ($2::bigint[])[LEAST(n, cardinality($2::bigint[]))] * INTERVAL '1 second',
+			       $3 > 0 AND n >= $3
+			FROM (SELECT CASE
+				WHEN t.last_failure_at < NOW() - $4 * INTERVAL '1 second' THEN 1
+				ELSE t.failures + 1
+			END) AS f(n)
+		)
+		WHERE key = $1 AND (blocked_until IS NULL OR blocked_until <= NOW())
+		RETURNING locked
+	`, key, pq.Array(policy.schedule()), policy.lockAfter, int(throttleWindow.Seconds())).Scan(&locked)
+	if err == nil {
+		return locked, nil
+	}
+	if err != sql.ErrNoRows {
+		s.logger.Error("failed to check login throttle", zap.Error(err))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+
+	var blockedUntil time.Time
+	err = s.db.QueryRowContext(ctx,
+		"SELECT blocked_until, locked FROM auth_throttle WHERE key = $1", key,
+	).Scan(&blockedUntil, &locked)
+	if err != nil {
+		s.logger.Error("failed to check login throttle", zap.Error(err))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+	return false, throttledError(time.Until(blockedUntil), locked)
+}
+
+// giveBackAttempt uncounts an attempt taken by takeAttempt that didn't fail
+func (s *Service) giveBackAttempt(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE auth_throttle
+		SET failures = GREATEST(failures - 1, 0), blocked_until = NULL, locked = FALSE
+		WHERE key = $1
+	`, key)
+	if err != nil {
+		s.logger.Warn("failed to update login throttle", zap.Error(err))
+	}
+}
+
+// startLogin takes an attempt for the email and the IP a login comes from.
+// It returns true when a failure would lock the email.
+func (s *Service) startLogin(ctx context.Context, email, ip string) (bool, error) {
+	locked, err := s.takeAttempt(ctx, emailThrottleKey(email), emailThrottle)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.takeAttempt(ctx, ipThrottleKey(ip), ipThrottle); err != nil {
+		s.giveBackAttempt(ctx, emailThrottleKey(email))
+		return false, err
+	}
+	return locked, nil
+}
+
+// loginFailed handles a login whose attempt, already counted by
+// startLogin, failed. If it locked the email and the email belongs to an
+// account, its owner is sent an unlock link.
+func (s *Service) loginFailed(ctx context.Context, email, ip string, locked bool) {
+	if locked {
+		s.logger.Warn("account locked after repeated login failures", zap.String("email", email), zap.String("ip", ip))
+		if err := s.sendUnlockEmail(ctx, email); err != nil {
+			s.logger.Error("failed to send unlock email", zap.Error(err))
+		}
+	}
+}
+
+// loginSucceeded forgets the email's failures and gives the IP its attempt
+// back
+func (s *Service) loginSucceeded(ctx context.Context, email, ip string) {
+	if err := s.clearThrottle(ctx, s.db, emailThrottleKey(email)); err != nil {
+		s.logger.Warn("failed to clear login throttle", zap.Error(err))
+	}
+	s.giveBackAttempt(ctx, ipThrottleKey(ip))
+}
+
+// clearThrottle forgets failures for key, after a successful login or unlock
+func (s *Service) clearThrottle(ctx context.Context, db dbExecutor, key string) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM auth_throttle WHERE key = $1", key)
+	return err
+}
+
+func (s *Service) sendUnlockEmail(ctx context.Context, email string) error {
+	var userID string
+	err := s.db.QueryRowContext(ctx, "SELECT id, email FROM auth_users WHERE LOWER(email) = LOWER($1) ORDER BY created_at LIMIT 1", email).Scan(&userID, &email)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.createUserToken(ctx, userID, purposeUnlockAccount, unlockTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Your account has been locked",
+		Text: fmt.Sprintf("We locked your Minimum account after several failed sign-in attempts.\n\n"+
+			"If that was you, unlock it now:\n\n%s/unlock-account?token=%s\n\n"+
+			"Otherwise it unlocks by itself in %d minutes. If you didn't try to sign in, "+
+			"consider resetting your password.\n",
+			s.config.AppURL, token, int(emailThrottle.lockDuration.Minutes())),
+	})
+}
+
+func (s *Service) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consumeUserToken(ctx, tx, req.Token, purposeUnlockAccount)
+	if err != nil {
+		if err != errInvalidUserToken {
+			s.logger.Error("failed to redeem unlock token", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, err
+	}
+
+	var email string
+	if err := tx.QueryRowContext(ctx, "SELECT email FROM auth_users WHERE id = $1", userID).Scan(&email); err != nil {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if err := s.clearThrottle(ctx, tx, emailThrottleKey(email)); err != nil {
+		s.logger.Error("failed to clear login throttle", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit unlock", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("account unlocked", zap.String("user_id", userID))
+
+	return &pb.UnlockAccountResponse{Success: true}, nil
+}
+
+// purgeThrottle periodically drops stale throttle rows
+func (s *Service) purgeThrottle() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.db.Exec(`
+			DELETE FROM auth_throttle
+			WHERE last_failure_at < NOW() - INTERVAL '1 day'
+			  AND (blocked_until IS NULL OR blocked_until < NOW())
+		`)
+		if err != nil {
+			s.logger.Error("failed to purge login throttle", zap.Error(err))
+		}
+	}
+}
+
+// throttledError builds the status returned for throttled and locked logins.
+// The details let the gateway answer with Retry-After.
+func throttledError(retryAfter time.Duration, locked bool) error {
+	retryAfter = max(retryAfter.Round(time.Second), time.Second)
+	retry := &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}
+
+	st := status.New(codes.ResourceExhausted, "too many failed login attempts")
+	details := []protoadapt.MessageV1{retry}
+	if locked {
+		st = status.New(codes.FailedPrecondition, "account is temporarily locked")
+		details = append(details, &errdetails.ErrorInfo{Reason: "ACCOUNT_LOCKED", Domain: "auth.minimum"})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
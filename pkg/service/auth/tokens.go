@@ -0,0 +1,378 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/auth"
+	"project/pkg/tokens"
+)
+
+// tokenPair is the result of a successful login or refresh
+type tokenPair struct {
+	accessToken  string
+	refreshToken string
+	expiresIn    int64
+}
+
+// dbExecutor is satisfied by both *sql.DB and *sql.Tx
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// clientInfo identifies the device behind a request, as reported by the
+// gateway, so users can recognise their sessions
+type clientInfo struct {
+	ipAddress string
+	userAgent string
+}
+
+// Longer user agents are truncated before they are stored
+const maxUserAgentLength = 512
+
+func (c clientInfo) truncatedUserAgent() string {
+	if len(c.userAgent) <= maxUserAgentLength {
+		return c.userAgent
+	}
+	return strings.ToValidUTF8(c.userAgent[:maxUserAgentLength], "")
+}
+
+// issueTokenPair starts a new session for the user and returns its first token pair
+func (s *Service) issueTokenPair(ctx context.Context, userID, email, name string, roles []string, client clientInfo) (*tokenPair, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var sessionID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO auth_sessions (user_id, ip_address, user_agent, last_seen_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NOW())
+		RETURNING id
+	`, userID, client.ipAddress, client.truncatedUserAgent()).Scan(&sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	refreshToken, err := s.insertRefreshToken(ctx, tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Signing in during the grace period keeps an account its owner asked
+	// to delete
+	res, err := tx.ExecContext(ctx,
+		"UPDATE auth_users SET delete_after = NULL WHERE id = $1 AND delete_after IS NOT NULL",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cancel account deletion: %w", err)
+	}
+	restored, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if restored > 0 {
+		s.logger.Info("account deletion cancelled by sign-in", zap.String("user_id", userID))
+	}
+
+	accessToken, err := s.signAccessToken(userID, email, name, sessionID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+		expiresIn:    int64(s.config.Auth.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// signAccessToken creates a short-lived JWT bound to a session
+func (s *Service) signAccessToken(userID, email, name, sessionID string, roles []string) (string, error) {
+	now := time.Now()
+	return s.keyring.Sign(&tokens.Claims{
+		UserID:    userID,
+		Email:     email,
+		Name:      name,
+		SessionID: sessionID,
+		Roles:     roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokens.Issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.Auth.AccessTokenTTL)),
+		},
+	})
+}
+
+// parseAccessToken verifies the signature and expiry of an access token
+func (s *Service) parseAccessToken(tokenString string, opts ...jwt.ParserOption) (*tokens.Claims, error) {
+	claims, err := tokens.Parse(tokenString, s.keyring.Keyfunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != "" {
+		return nil, fmt.Errorf("%s token is not an access token", claims.Type)
+	}
+	return claims, nil
+}
+
+// sessionActive reports whether the session exists and has not been revoked.
+// Tokens without a session ID predate revocation support and are rejected.
+func (s *Service) sessionActive(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	var active bool
+	err := s.db.QueryRowContext(ctx,
+		"SELECT revoked_at IS NULL FROM auth_sessions WHERE id = $1",
+		sessionID,
+	).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return active, nil
+}
+
+// insertRefreshToken generates an opaque refresh token and stores its hash
+func (s *Service) insertRefreshToken(ctx context.Context, db dbExecutor, sessionID string) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO auth_refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		sessionID, hashToken(token), time.Now().Add(s.config.Auth.RefreshTokenTTL),
+	)
+	if err != nil {
+		return "", fmt.Errorf("store refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// revokeSession marks a session (and with it the whole token family) as revoked
+func (s *Service) revokeSession(ctx context.Context, db dbExecutor, sessionID string) error {
+	_, err := db.ExecContext(ctx,
+		"UPDATE auth_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL",
+		sessionID,
+	)
+	return err
+}
+
+func (s *Service) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var tokenID, sessionID, userID, email, name string
+	var roles []string
+	var expiresAt time.Time
+	var usedAt, revokedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT rt.id, rt.session_id, rt.expires_at, rt.used_at, s.revoked_at,
+		       u.id, u.email, COALESCE(u.name, ''), u.roles
+		FROM auth_refresh_tokens rt
+		JOIN auth_sessions s ON s.id = rt.session_id
+		JOIN auth_users u ON u.id = s.user_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt
+	`, hashToken(req.RefreshToken)).Scan(
+		&tokenID, &sessionID, &expiresAt, &usedAt, &revokedAt,
+		&userID, &email, &name, pq.Array(&roles),
+	)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up refresh token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// A refresh token that was already rotated is being replayed: assume it
+	// leaked and revoke the whole family so the attacker's copy dies too.
+	if usedAt.Valid {
+		s.logger.Warn("refresh token reuse detected, revoking session",
+			zap.String("session_id", sessionID), zap.String("user_id", userID))
+		if err := s.revokeSession(ctx, tx, sessionID); err != nil {
+			s.logger.Error("failed to revoke session", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if err := tx.Commit(); err != nil {
+			s.logger.Error("failed to commit session revocation", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, status.Error(codes.Unauthenticated, "refresh token has already been used")
+	}
+
+	if revokedAt.Valid || time.Now().After(expiresAt) {
+		return nil, status.Error(codes.Unauthenticated, "refresh token expired or revoked")
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE auth_refresh_tokens SET used_at = NOW() WHERE id = $1", tokenID); err != nil {
+		s.logger.Error("failed to mark refresh token used", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// Refreshing is the only regular sign of life from a device, since
+	// access tokens are verified without calling us
+	_, err = tx.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET last_seen_at = NOW(), ip_address = COALESCE(NULLIF($2, ''), ip_address)
+		WHERE id = $1
+	`, sessionID, req.IpAddress)
+	if err != nil {
+		s.logger.Error("failed to update session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	refreshToken, err := s.insertRefreshToken(ctx, tx, sessionID)
+	if err != nil {
+		s.logger.Error("failed to rotate refresh token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit refresh", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	accessToken, err := s.signAccessToken(userID, email, name, sessionID, roles)
+	if err != nil {
+		s.logger.Error("failed to sign token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &pb.RefreshTokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.config.Auth.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *Service) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	var sessionID string
+
+	switch {
+	case req.RefreshToken != "":
+		err := s.db.QueryRowContext(ctx,
+			"SELECT session_id FROM auth_refresh_tokens WHERE token_hash = $1",
+			hashToken(req.RefreshToken),
+		).Scan(&sessionID)
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+		if err != nil {
+			s.logger.Error("failed to look up refresh token", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	case req.Token != "":
+		// An expired access token still identifies the session to end
+		claims, err := s.parseAccessToken(req.Token, jwt.WithoutClaimsValidation())
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		sessionID = claims.SessionID
+		if sessionID == "" {
+			return nil, status.Error(codes.Unauthenticated, "token is not bound to a session")
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "refresh token or access token is required")
+	}
+
+	if err := s.revokeSession(ctx, s.db, sessionID); err != nil {
+		s.logger.Error("failed to revoke session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("session revoked", zap.String("session_id", sessionID))
+
+	return &pb.LogoutResponse{Success: true}, nil
+}
+
+// generateOpaqueToken returns 32 bytes of randomness, URL-safe encoded
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 of a token. Opaque tokens carry 256 bits
+// of entropy, so a fast unsalted hash is sufficient for storage.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListRevokedSessions lets verifiers that check tokens locally keep a
+// revocation list. Only revocations younger than the access token lifetime
+// are returned, since older sessions have no live access tokens left.
+func (s *Service) ListRevokedSessions(ctx context.Context, req *pb.ListRevokedSessionsRequest) (*pb.ListRevokedSessionsResponse, error) {
+	ttl := s.config.Auth.AccessTokenTTL
+	now := time.Now()
+
+	since := now.Add(-ttl)
+	if req.Since > 0 && time.Unix(req.Since, 0).After(since) {
+		since = time.Unix(req.Since, 0)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, revoked_at FROM auth_sessions
+		WHERE revoked_at IS NOT NULL AND revoked_at >= $1
+		ORDER BY revoked_at
+	`, since)
+	if err != nil {
+		s.logger.Error("failed to list revoked sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	var sessions []*pb.RevokedSession
+	for rows.Next() {
+		var id string
+		var revokedAt time.Time
+		if err := rows.Scan(&id, &revokedAt); err != nil {
+			continue
+		}
+		sessions = append(sessions, &pb.RevokedSession{
+			SessionId: id,
+			RevokedAt: revokedAt.Unix(),
+			ExpiresAt: revokedAt.Add(ttl).Unix(),
+		})
+	}
+
+	return &pb.ListRevokedSessionsResponse{
+		Sessions:   sessions,
+		ServerTime: now.Unix(),
+	}, nil
+}
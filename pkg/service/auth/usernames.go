@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	"project/pkg/validation"
+)
+
+var errUsernameTaken = status.Error(codes.AlreadyExists, "username is taken")
+
+// claimUsername gives userID the handle username within tx, recording the
+// handle it replaces. It returns status errors for handles that are invalid
+// or unavailable.
+func (s *Service) claimUsername(ctx context.Context, tx *sql.Tx, userID, username string) error {
+	if err := validation.ValidateUsername(username); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var previous sql.NullString
+	err := tx.QueryRowContext(ctx, "SELECT username FROM auth_users WHERE id = $1 FOR UPDATE", userID).Scan(&previous)
+	if err != nil {
+		return err
+	}
+	if previous.String == username {
+		return nil
+	}
+	retiring := previous.Valid && !strings.EqualFold(previous.String, username)
+
+	// Serialize claims and retirements per handle, so a handle can't be
+	// claimed by someone else in the moment it is being retired
+	handles := []string{strings.ToLower(username)}
+	if retiring {
+		handles = append(handles, strings.ToLower(previous.String))
+	}
+	_, err = tx.ExecContext(ctx,
+		"SELECT pg_advisory_xact_lock(hashtext(h)) FROM unnest($1::text[]) AS h ORDER BY h",
+		pq.Array(handles),
+	)
+	if err != nil {
+		return err
+	}
+
+	var held bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM auth_username_history
+			WHERE LOWER(username) = LOWER($1) AND user_id <> $2 AND retired_at > $3
+		)
+	`, username, userID, time.Now().Add(-common.UsernameRedirectPeriod)).Scan(&held)
+	if err != nil {
+		return err
+	}
+	if held {
+		return errUsernameTaken
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE auth_users SET username = $1 WHERE id = $2", username, userID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return errUsernameTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	if retiring {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO auth_username_history (user_id, username) VALUES ($1, $2)",
+			userID, previous.String,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("username claimed",
+		zap.String("user_id", userID), zap.String("username", username), zap.String("previous", previous.String))
+	return nil
+}
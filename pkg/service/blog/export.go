@@ -0,0 +1,226 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/blog"
+)
+
+// ExportUserData returns the user's profile, their posts as Markdown, and
+// JSON files for their comments, claps, follows, blocks, reading history,
+// bookmarks and notifications
+func (s *Service) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	var files []*pb.ExportFile
+	add := func(name string, v any) error {
+		content, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		files = append(files, &pb.ExportFile{Name: name, Content: content})
+		return nil
+	}
+
+	profile, err := s.exportProfile(ctx, req.UserId)
+	if err == sql.ErrNoRows {
+		// Not projected yet, so there is nothing here to export
+		return &pb.ExportUserDataResponse{}, nil
+	}
+	if err != nil {
+		s.logger.Error("failed to export profile", zap.String("user_id", req.UserId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if err := add("profile.json", profile); err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	posts, err := s.exportPosts(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("failed to export posts", zap.String("user_id", req.UserId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	files = append(files, posts...)
+
+	sections := []struct {
+		name  string
+		query string
+	}{
+		{"comments.json", `
+			SELECT c.post_id, p.title AS post_title, c.content, c.created_at
+			FROM comments c JOIN posts p ON p.id = c.post_id
+			WHERE c.user_id = $1 ORDER BY c.created_at`},
+		{"claps.json", `
+			SELECT i.post_id, p.title AS post_title, i.count AS claps, i.created_at
+			FROM interactions i JOIN posts p ON p.id = i.post_id
+			WHERE i.user_id = $1 AND i.type = 'clap' ORDER BY i.created_at`},
+		{"following.json", `
+			SELECT f.followee_id AS user_id, u.name, f.created_at AS since
+			FROM follows f JOIN users u ON u.id = f.followee_id
+			WHERE f.follower_id = $1 ORDER BY f.created_at`},
+		{"followers.json", `
+			SELECT f.follower_id AS user_id, u.name, f.created_at AS since
+			FROM follows f JOIN users u ON u.id = f.follower_id
+			WHERE f.followee_id = $1 ORDER BY f.created_at`},
+		{"following_tags.json", `
+			SELECT t.name AS tag, tf.created_at AS since
+			FROM tag_follows tf JOIN tags t ON t.id = tf.tag_id
+			WHERE tf.user_id = $1 ORDER BY tf.created_at`},
+		{"reads.json", `
+			SELECT r.post_id, p.title AS post_title, r.read_at
+			FROM post_reads r JOIN posts p ON p.id = r.post_id
+			WHERE r.user_id = $1 ORDER BY r.read_at`},
+		{"blocked.json", `
+			SELECT b.blocked_id AS user_id, u.name, b.created_at AS since
+			FROM user_blocks b JOIN users u ON u.id = b.blocked_id
+			WHERE b.blocker_id = $1 ORDER BY b.created_at`},
+		{"bookmarks.json", `
+			SELECT b.post_id, p.title AS post_title, b.created_at
+			FROM bookmarks b JOIN posts p ON p.id = b.post_id
+			WHERE b.user_id = $1 ORDER BY b.created_at`},
+		{"notifications.json", `
+			SELECT n.type, n.actor_id, n.post_id, n.read, n.created_at
+			FROM notifications n
+			WHERE n.user_id = $1 ORDER BY n.created_at`},
+	}
+	for _, section := range sections {
+		records, err := s.exportRecords(ctx, section.query, req.UserId)
+		if err != nil {
+			s.logger.Error("failed to export user data",
+				zap.String("user_id", req.UserId), zap.String("file", section.name), zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if err := add(section.name, records); err != nil {
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	return &pb.ExportUserDataResponse{Files: files}, nil
+}
+
+type profileExport struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Bio       string    `json:"bio"`
+	AvatarURL string    `json:"avatar_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s *Service) exportProfile(ctx context.Context, userID string) (*profileExport, error) {
+	var p profileExport
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, email, COALESCE(bio, ''), COALESCE(avatar_url, ''), created_at
+		FROM users WHERE id = $1
+	`, userID).Scan(&p.ID, &p.Name, &p.Email, &p.Bio, &p.AvatarURL, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// exportPosts renders each of the user's posts, drafts included, as a
+// Markdown file with the metadata in front matter
+func (s *Service) exportPosts(ctx context.Context, userID string) ([]*pb.ExportFile, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, COALESCE(p.slug, ''), p.title, COALESCE(p.subtitle, ''), p.content,
+		       COALESCE(p.status, ''), p.created_at, p.published_at,
+		       ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)
+		FROM posts p
+		WHERE p.author_id = $1
+		ORDER BY p.created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*pb.ExportFile
+	for rows.Next() {
+		var id, slug, title, subtitle, content, postStatus string
+		var createdAt time.Time
+		var publishedAt sql.NullTime
+		var tags []string
+		if err := rows.Scan(&id, &slug, &title, &subtitle, &content, &postStatus, &createdAt, &publishedAt, pq.Array(&tags)); err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		b.WriteString("---\n")
+		fmt.Fprintf(&b, "id: %s\n", id)
+		fmt.Fprintf(&b, "title: %s\n", strconv.Quote(title))
+		if subtitle != "" {
+			fmt.Fprintf(&b, "subtitle: %s\n", strconv.Quote(subtitle))
+		}
+		fmt.Fprintf(&b, "status: %s\n", postStatus)
+		fmt.Fprintf(&b, "created_at: %s\n", createdAt.Format(time.RFC3339))
+		if publishedAt.Valid {
+			fmt.Fprintf(&b, "published_at: %s\n", publishedAt.Time.Format(time.RFC3339))
+		}
+		if len(tags) > 0 {
+			quoted := make([]string, len(tags))
+			for i, tag := range tags {
+				quoted[i] = strconv.Quote(tag)
+			}
+			fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+		}
+		b.WriteString("---\n\n")
+		b.WriteString(content)
+		b.WriteString("\n")
+
+		// Slugs are URL-safe, but fall back to the ID rather than trust
+		// one with a path separator
+		name := slug
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			name = id
+		}
+		files = append(files, &pb.ExportFile{Name: "posts/" + name + ".md", Content: []byte(b.String())})
+	}
+	return files, rows.Err()
+}
+
+// exportRecords runs query and returns each row as a JSON object keyed by
+// column name
+func (s *Service) exportRecords(ctx context.Context, query, userID string) ([]map[string]any, error) {
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	records := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]any, len(columns))
+		for i, col := range columns {
+			// lib/pq returns text columns as []byte, which JSON would base64
+			if b, ok := values[i].([]byte); ok {
+				record[col] = string(b)
+			} else {
+				record[col] = values[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,324 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/blog"
+)
+
+// Popular posts are those trending over this window. Its scores only
+// change when the rankings are recomputed, so they page stably.
+const popularWindow = "month"
+
+// feedCursor marks where a feed page ended: the last post's publication
+// time and ID, and for popular posts its trending score
+type feedCursor struct {
+	Popular     bool      `json:"popular,omitempty"`
+	Score       float64   `json:"score,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitzero"`
+	ID          string    `json:"id,omitempty"`
+}
+
+func (c feedCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeFeedCursor(s string) (feedCursor, error) {
+	var c feedCursor
+	if s == "" {
+		return c, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || (c.ID != "" && !uuidRegex.MatchString(c.ID)) {
+		return feedCursor{}, status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+	return c, nil
+}
+
+type feedEntry struct {
+	id          string
+	publishedAt time.Time
+	reason      string
+	score       float64
+}
+
+// followedFilter holds for posts by authors or with tags that $1 follows
+const followedFilter = `(
+	EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = $1 AND f.followee_id = p.author_id)
+	OR EXISTS (
+		SELECT 1 FROM post_tags pt JOIN tag_follows tf ON tf.tag_id = pt.tag_id
+		WHERE pt.post_id = p.id AND tf.user_id = $1
+	)
+)`
+
+// feedFilter leaves out the reader's own posts, posts they have read, and
+// posts by users they blocked or who blocked them
+var feedFilter = `
+	p.status = 'published' AND p.published_at IS NOT NULL AND p.author_id <> $1
+	AND NOT EXISTS (SELECT 1 FROM post_reads r WHERE r.user_id = $1 AND r.post_id = p.id)
+	AND ` + notBlocked("p.author_id", "$1::text")
+
+// GetHomeFeed pages through new posts from the authors and tags the user
+// follows, newest first, then through posts trending this month. The feed is
+// built on read: the followed part starts from the user's follows and reads
+// only their posts past the cursor, so it doesn't scan posts by everyone else.
+func (s *Service) GetHomeFeed(ctx context.Context, req *pb.GetHomeFeedRequest) (*pb.GetHomeFeedResponse, error) {
+	cursor, err := decodeFeedCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	var entries []feedEntry
+	var next string
+	if !cursor.Popular {
+		followed, err := s.followedFeedEntries(ctx, req.UserId, cursor, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(followed) > limit {
+			entries = followed[:limit]
+			last := entries[limit-1]
+			next = feedCursor{PublishedAt: last.publishedAt, ID: last.id}.encode()
+		} else {
+			entries = followed
+			cursor = feedCursor{Popular: true}
+		}
+	}
+
+	if cursor.Popular {
+		if want := limit - len(entries); want == 0 {
+			next = cursor.encode()
+		} else {
+			popular, err := s.popularFeedEntries(ctx, req.UserId, cursor, want+1)
+			if err != nil {
+				return nil, err
+			}
+			if len(popular) > want {
+				popular = popular[:want]
+				last := popular[want-1]
+				next = feedCursor{Popular: true, Score: last.score, PublishedAt: last.publishedAt, ID: last.id}.encode()
+			}
+			entries = append(entries, popular...)
+		}
+	}
+
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.id
+	}
+	posts, err := s.postSummaries(ctx, ids, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*pb.FeedItem{}
+	for _, e := range entries {
+		if post, ok := posts[e.id]; ok {
+			items = append(items, &pb.FeedItem{Post: post, Reason: e.reason})
+		}
+	}
+	return &pb.GetHomeFeedResponse{Items: items, NextCursor: next}, nil
+}
+
+// followedFeedEntries pages through posts by the authors and with the tags
+// userID follows. A post matching several follows is listed once, as
+// following when its author is followed.
+func (s *Service) followedFeedEntries(ctx context.Context, userID string, cursor feedCursor, limit int) ([]feedEntry, error) {
+	var follows bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1)
+		    OR EXISTS (SELECT 1 FROM tag_follows WHERE user_id = $1)
+	`, userID).Scan(&follows)
+	if err != nil {
+		s.logger.Error("failed to check follows", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !follows {
+		return nil, nil
+	}
+
+	var after sql.NullTime
+	var afterID sql.NullString
+	if cursor.ID != "" {
+		after = sql.NullTime{Time: cursor.PublishedAt, Valid: true}
+		afterID = sql.NullString{String: cursor.ID, Valid: true}
+	}
+
+	// Each branch walks one follow's posts newest first from the cursor
+	return s.feedEntries(ctx, `
+		SELECT p.id, p.published_at,
+		       CASE WHEN bool_or(m.by_author) THEN 'following' ELSE 'tag' END,
+		       0::float8
+		FROM (
+			SELECT p.id, true AS by_author
+			FROM follows f
+			JOIN posts p ON p.author_id = f.followee_id
+			WHERE f.follower_id = $1 AND p.status = 'published'
+			  AND ($2::timestamptz IS NULL OR (p.published_at, p.id) < ($2::timestamptz, $3::uuid))
+			UNION ALL
+			SELECT p.id, false
+			FROM tag_follows tf
+			JOIN post_tags pt ON pt.tag_id = tf.tag_id
+			JOIN posts p ON p.id = pt.post_id
+			WHERE tf.user_id = $1 AND p.status = 'published'
+			  AND ($2::timestamptz IS NULL OR (p.published_at, p.id) < ($2::timestamptz, $3::uuid))
+		) m
+		JOIN posts p ON p.id = m.id
+		WHERE `+feedFilter+`
+		GROUP BY p.id, p.published_at
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT $4
+	`, userID, after, afterID, limit)
+}
+
+// popularFeedEntries pages through trending posts, leaving out those the
+// followed part of the feed already had
+func (s *Service) popularFeedEntries(ctx context.Context, userID string, cursor feedCursor, limit int) ([]feedEntry, error) {
+	var score sql.NullFloat64
+	var after sql.NullTime
+	var afterID sql.NullString
+	if cursor.ID != "" {
+		score = sql.NullFloat64{Float64: cursor.Score, Valid: true}
+		after = sql.NullTime{Time: cursor.PublishedAt, Valid: true}
+		afterID = sql.NullString{String: cursor.ID, Valid: true}
+	}
+
+	return s.feedEntries(ctx, `
+		SELECT p.id, p.published_at, 'popular', ts.score
+		FROM trending_scores ts
+		JOIN posts p ON p.id = ts.post_id
+		WHERE ts.period = $2
+		  AND `+feedFilter+`
+		  AND NOT `+followedFilter+`
+		  AND ($3::float8 IS NULL OR (ts.score, p.published_at, p.id) < ($3::float8, $4::timestamptz, $5::uuid))
+		ORDER BY ts.score DESC, p.published_at DESC, p.id DESC
+		LIMIT $6
+	`, userID, popularWindow, score, after, afterID, limit)
+}
+
+func (s *Service) feedEntries(ctx context.Context, query string, args ...any) ([]feedEntry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("failed to query feed", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	var entries []feedEntry
+	for rows.Next() {
+		var e feedEntry
+		if err := rows.Scan(&e.id, &e.publishedAt, &e.reason, &e.score); err != nil {
+			s.logger.Error("failed to scan feed entry", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read feed", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return entries, nil
+}
+
+// postSummaries loads posts by ID as list views show them, without content
+func (s *Service) postSummaries(ctx context.Context, ids []string, viewerID string) (map[string]*pb.Post, error) {
+	posts := make(map[string]*pb.Post, len(ids))
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.title, COALESCE(p.subtitle, ''), COALESCE(p.excerpt, ''), p.word_count,
+		       COALESCE(p.reading_time, 0), p.author_id, u.name, u.avatar_url, p.created_at, p.published_at,
+		       COALESCE(p.cover_image, ''), p.status,
+		       ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name),
+		       COALESCE((SELECT SUM(i.count) FROM interactions i WHERE i.post_id = p.id AND i.type = 'clap'), 0),
+		       EXISTS (SELECT 1 FROM bookmarks b WHERE b.post_id = p.id AND b.user_id::text = $2)
+		FROM posts p
+		JOIN users u ON u.id = p.author_id
+		WHERE p.id = ANY($1::uuid[])
+	`, pq.Array(ids), viewerID)
+	if err != nil {
+		s.logger.Error("failed to load posts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post pb.Post
+		var authorName, authorAvatar sql.NullString
+		var createdAt time.Time
+		var publishedAt sql.NullTime
+		err := rows.Scan(&post.Id, &post.Title, &post.Subtitle, &post.Excerpt, &post.WordCount,
+			&post.ReadingTime, &post.AuthorId, &authorName, &authorAvatar, &createdAt, &publishedAt,
+			&post.CoverImage, &post.Status, pq.Array(&post.Tags), &post.ClapsCount, &post.IsBookmarked)
+		if err != nil {
+			s.logger.Error("failed to scan post", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		post.CreatedAt = createdAt.Format(time.RFC3339)
+		if publishedAt.Valid {
+			post.PublishedAt = publishedAt.Time.Format(time.RFC3339)
+		}
+		post.Author = &pb.Author{Id: post.AuthorId, Name: authorName.String, AvatarUrl: authorAvatar.String}
+		posts[post.Id] = &post
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read posts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return posts, nil
+}
+
+// ToggleTagFollow follows or unfollows a tag, whose new posts then show up
+// in the user's feed
+func (s *Service) ToggleTagFollow(ctx context.Context, req *pb.ToggleTagFollowRequest) (*pb.ToggleTagFollowResponse, error) {
+	var tagID string
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM tags WHERE name = $1", req.Tag).Scan(&tagID)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "tag not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up tag", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		"DELETE FROM tag_follows WHERE user_id = $1 AND tag_id = $2",
+		req.UserId, tagID,
+	)
+	if err != nil {
+		s.logger.Error("failed to unfollow tag", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return &pb.ToggleTagFollowResponse{Following: false}, nil
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO tag_follows (user_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		req.UserId, tagID,
+	)
+	if err != nil {
+		s.logger.Error("failed to follow tag", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return &pb.ToggleTagFollowResponse{Following: true}, nil
+}
@@ -0,0 +1,285 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	pb "project/pkg/proto/blog"
+	"project/pkg/tokens"
+)
+
+// Post statuses. Only published posts are shown to anyone but their author.
+const (
+	postDraft     = "draft"
+	postScheduled = "scheduled"
+	postPublished = "published"
+	postArchived  = "archived"
+)
+
+// How often the scheduler looks for posts that are due
+const schedulerInterval = time.Minute
+
+// parsePostStatus validates the status and scheduled_at fields of a create
+// or update request. A schedule takes precedence over the status; an empty
+// result means neither was given.
+func parsePostStatus(postStatus, scheduledAt string) (string, sql.NullTime, error) {
+	if scheduledAt != "" {
+		at, err := time.Parse(time.RFC3339, scheduledAt)
+		if err != nil {
+			return "", sql.NullTime{}, status.Error(codes.InvalidArgument, "scheduled_at must be an RFC 3339 time")
+		}
+		if !at.After(time.Now()) {
+			return "", sql.NullTime{}, status.Error(codes.InvalidArgument, "scheduled_at must be in the future")
+		}
+		return postScheduled, sql.NullTime{Time: at, Valid: true}, nil
+	}
+
+	switch postStatus {
+	case "", postDraft, postPublished, postArchived:
+		return postStatus, sql.NullTime{}, nil
+	case postScheduled:
+		return "", sql.NullTime{}, status.Error(codes.InvalidArgument, "scheduled_at is required to schedule a post")
+	default:
+		return "", sql.NullTime{}, status.Errorf(codes.InvalidArgument, "unknown post status %q", postStatus)
+	}
+}
+
+// statusSets returns the SET clauses of an UPDATE that moves a post to the
+// status in parameter n and the schedule in parameter n+1. A post keeps the
+// date it was first published, so unpublishing and republishing doesn't
+// bump it in feeds.
+func statusSets(n int) []string {
+	return []string{
+		fmt.Sprintf("status = $%d::text", n),
+		fmt.Sprintf("scheduled_at = $%d", n+1),
+		fmt.Sprintf("published_at = CASE WHEN $%d::text = 'published' THEN COALESCE(published_at, NOW()) ELSE published_at END", n),
+	}
+}
+
+// setPostStatus moves a post at version from one of the statuses in from
+// to postStatus. The check and the change are a single UPDATE, so two
+// concurrent changes can't both apply. When nothing matches it reports why:
+// the post is gone, was changed since version, or is in a status the move
+// doesn't apply to (FailedPrecondition with notFrom as the message).
+func (s *Service) setPostStatus(ctx context.Context, postID string, version int64, postStatus string,
+	scheduledAt sql.NullTime, from []string, notFrom string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE posts
+		SET `+strings.Join(statusSets(3), ", ")+`,
+		    version = version + 1,
+		    updated_at = NOW()
+		WHERE id = $1 AND version = $2 AND status = ANY($5)
+	`, postID, version, postStatus, scheduledAt, pq.Array(from))
+	if err != nil {
+		s.logger.Error("failed to change post status", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var current int64
+	err = s.db.QueryRowContext(ctx, "SELECT version FROM posts WHERE id = $1", postID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return status.Error(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up post", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+	if current != version {
+		return common.VersionConflictError("post", current)
+	}
+	return status.Error(codes.FailedPrecondition, notFrom)
+}
+
+// authorizePostChange returns the post's author if userID may change the
+// post: its author, or anyone holding the edit-any permission
+func (s *Service) authorizePostChange(ctx context.Context, postID, userID string, permissions []string) (string, error) {
+	var authorID string
+	err := s.db.QueryRowContext(ctx, "SELECT author_id FROM posts WHERE id = $1", postID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		return "", status.Error(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to look up post", zap.Error(err))
+		return "", status.Error(codes.Internal, "internal error")
+	}
+
+	if authorID != userID {
+		if !slices.Contains(permissions, tokens.PermPostsEditAny) {
+			return "", status.Error(codes.PermissionDenied, "only the author can change this post")
+		}
+		s.logger.Info("post changed with admin override", zap.String("post_id", postID), zap.String("user_id", userID))
+	}
+	return authorID, nil
+}
+
+func (s *Service) PublishPost(ctx context.Context, req *pb.PublishPostRequest) (*pb.PublishPostResponse, error) {
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	authorID, err := s.authorizePostChange(ctx, req.PostId, req.UserId, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	postStatus, scheduledAt, err := parsePostStatus(postPublished, req.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// A scheduled post can be rescheduled or published early, but a
+	// published one can't be either
+	from := []string{postDraft, postScheduled, postArchived}
+	if err := s.setPostStatus(ctx, req.PostId, req.Version, postStatus, scheduledAt, from, "post is already published"); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("post status changed", zap.String("post_id", req.PostId), zap.String("status", postStatus))
+
+	resp, err := s.GetPost(ctx, &pb.GetPostRequest{PostId: req.PostId, CurrentUserId: authorID})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PublishPostResponse{Post: resp.Post}, nil
+}
+
+// UnpublishPost turns a published or scheduled post back into a draft
+func (s *Service) UnpublishPost(ctx context.Context, req *pb.UnpublishPostRequest) (*pb.UnpublishPostResponse, error) {
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	authorID, err := s.authorizePostChange(ctx, req.PostId, req.UserId, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	from := []string{postPublished, postScheduled}
+	if err := s.setPostStatus(ctx, req.PostId, req.Version, postDraft, sql.NullTime{}, from, "post isn't published or scheduled"); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("post status changed", zap.String("post_id", req.PostId), zap.String("status", postDraft))
+
+	resp, err := s.GetPost(ctx, &pb.GetPostRequest{PostId: req.PostId, CurrentUserId: authorID})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UnpublishPostResponse{Post: resp.Post}, nil
+}
+
+func (s *Service) ListMyDrafts(ctx context.Context, req *pb.ListMyDraftsRequest) (*pb.ListMyDraftsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	offset := max(int(req.Page-1)*limit, 0)
+
+	var total int32
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM posts WHERE author_id = $1 AND status IN ('draft', 'scheduled')",
+		req.UserId,
+	).Scan(&total)
+	if err != nil {
+		s.logger.Error("failed to count drafts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.title, COALESCE(p.subtitle, ''), COALESCE(p.excerpt, ''), p.word_count, COALESCE(p.reading_time, 0),
+		       p.author_id, p.created_at, COALESCE(p.cover_image, ''), p.status, p.scheduled_at, p.version,
+		       ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)
+		FROM posts p
+		WHERE p.author_id = $1 AND p.status IN ('draft', 'scheduled')
+		ORDER BY p.updated_at DESC
+		LIMIT $2 OFFSET $3
+	`, req.UserId, limit, offset)
+	if err != nil {
+		s.logger.Error("failed to query drafts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	posts := []*pb.Post{}
+	for rows.Next() {
+		var post pb.Post
+		var createdAt time.Time
+		var scheduledAt sql.NullTime
+		err := rows.Scan(&post.Id, &post.Title, &post.Subtitle, &post.Excerpt, &post.WordCount, &post.ReadingTime,
+			&post.AuthorId, &createdAt, &post.CoverImage, &post.Status, &scheduledAt, &post.Version, pq.Array(&post.Tags))
+		if err != nil {
+			s.logger.Error("failed to scan draft", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		post.CreatedAt = createdAt.Format(time.RFC3339)
+		if scheduledAt.Valid {
+			post.ScheduledAt = scheduledAt.Time.Format(time.RFC3339)
+		}
+		post.Author = &pb.Author{Id: post.AuthorId}
+		posts = append(posts, &post)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read drafts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &pb.ListMyDraftsResponse{Posts: posts, Total: total}, nil
+}
+
+// publishScheduledPosts periodically publishes scheduled posts that are due
+func (s *Service) publishScheduledPosts() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.publishDuePosts(context.Background())
+	}
+}
+
+// publishDuePosts publishes the scheduled posts that are due and returns
+// their IDs. Every replica runs it; the UPDATE re-checks the status of each
+// row it locks, so a post claimed by one replica is skipped by the others
+// and is published exactly once.
+func (s *Service) publishDuePosts(ctx context.Context) []string {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE posts
+		SET status = 'published',
+		    published_at = COALESCE(published_at, scheduled_at),
+		    scheduled_at = NULL,
+		    version = version + 1,
+		    updated_at = NOW()
+		WHERE status = 'scheduled' AND scheduled_at <= NOW()
+		RETURNING id
+	`)
+	if err != nil {
+		s.logger.Error("failed to publish scheduled posts", zap.Error(err))
+		return nil
+	}
+	defer rows.Close()
+
+	var published []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			s.logger.Info("scheduled post published", zap.String("post_id", id))
+			published = append(published, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read published posts", zap.Error(err))
+	}
+	return published
+}
@@ -0,0 +1,126 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"project/pkg/markdown"
+	pb "project/pkg/proto/blog"
+)
+
+// Posts are rendered in batches of this many when the pipeline changes
+const rerenderBatchSize = 100
+
+const saveRenderingQuery = `
+	UPDATE posts
+	SET content_html = $2, excerpt = $3, toc = $4, word_count = $5, reading_time = $6, render_version = $7
+	WHERE id = $1
+`
+
+// renderingArgs returns the arguments of saveRenderingQuery
+func renderingArgs(postID string, doc *markdown.Document) ([]any, error) {
+	toc, err := json.Marshal(doc.TOC)
+	if err != nil {
+		return nil, err
+	}
+	return []any{postID, doc.HTML, doc.Excerpt, toc, doc.WordCount, doc.ReadingTime, markdown.Version}, nil
+}
+
+// storeRendering renders content and saves the HTML, excerpt and table of
+// contents on the post, within tx. Call it whenever a post's content is
+// written.
+func (s *Service) storeRendering(ctx context.Context, tx *sql.Tx, postID, content string) (*markdown.Document, error) {
+	doc, err := markdown.Render(content)
+	if err != nil {
+		return nil, err
+	}
+	args, err := renderingArgs(postID, doc)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, saveRenderingQuery, args...); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// setRendering fills in the rendered fields of post
+func setRendering(post *pb.Post, doc *markdown.Document) {
+	post.ContentHtml = doc.HTML
+	post.Excerpt = doc.Excerpt
+	post.Toc = tocEntries(doc.TOC)
+	post.WordCount = int32(doc.WordCount)
+	post.ReadingTime = int32(doc.ReadingTime)
+}
+
+func tocEntries(headings []markdown.Heading) []*pb.TocEntry {
+	entries := make([]*pb.TocEntry, len(headings))
+	for i, h := range headings {
+		entries[i] = &pb.TocEntry{Level: int32(h.Level), Id: h.ID, Text: h.Text}
+	}
+	return entries
+}
+
+// rerenderPosts renders again every post rendered by an older version of
+// the pipeline, or never rendered at all. It walks the posts in ID order so
+// a post that fails to render is skipped rather than retried forever.
+func (s *Service) rerenderPosts() {
+	ctx := context.Background()
+	lastID := "00000000-0000-0000-0000-000000000000"
+	rendered := 0
+
+	for {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT id, content FROM posts
+			WHERE render_version < $1 AND id > $2
+			ORDER BY id
+			LIMIT $3
+		`, markdown.Version, lastID, rerenderBatchSize)
+		if err != nil {
+			s.logger.Error("failed to find posts to render", zap.Error(err))
+			return
+		}
+		type stalePost struct{ id, content string }
+		var batch []stalePost
+		for rows.Next() {
+			var p stalePost
+			if err := rows.Scan(&p.id, &p.content); err != nil {
+				s.logger.Error("failed to scan post to render", zap.Error(err))
+				rows.Close()
+				return
+			}
+			batch = append(batch, p)
+		}
+		rows.Close()
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, p := range batch {
+			doc, err := markdown.Render(p.content)
+			if err != nil {
+				s.logger.Error("failed to render post", zap.String("post_id", p.id), zap.Error(err))
+				continue
+			}
+			args, err := renderingArgs(p.id, doc)
+			if err != nil {
+				continue
+			}
+			// A post edited in the meantime was rendered by its edit
+			_, err = s.db.ExecContext(ctx, saveRenderingQuery+" AND content = $8", append(args, p.content)...)
+			if err != nil {
+				s.logger.Error("failed to store rendered post", zap.String("post_id", p.id), zap.Error(err))
+				continue
+			}
+			rendered++
+		}
+		lastID = batch[len(batch)-1].id
+	}
+
+	if rendered > 0 {
+		s.logger.Info("posts rendered", zap.Int("count", rendered), zap.Int("render_version", markdown.Version))
+	}
+}
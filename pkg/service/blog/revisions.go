@@ -0,0 +1,247 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	"project/pkg/diff"
+	pb "project/pkg/proto/blog"
+)
+
+// recordRevision saves the post's current title, content and cover image as
+// its next revision. The caller must hold the post's row lock in tx, which
+// keeps revision numbers from colliding.
+func (s *Service) recordRevision(ctx context.Context, tx *sql.Tx, postID, editorID string, restoredFrom int32) (int32, error) {
+	var number int32
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO post_revisions (post_id, number, title, content, cover_image, editor_id, restored_from)
+		SELECT p.id, COALESCE((SELECT MAX(number) FROM post_revisions WHERE post_id = p.id), 0) + 1,
+		       p.title, p.content, p.cover_image, $2, NULLIF($3, 0)
+		FROM posts p WHERE p.id = $1
+		RETURNING number
+	`, postID, editorID, restoredFrom).Scan(&number)
+	return number, err
+}
+
+// Revisions are read with the editor's name and avatar. ListRevisions
+// selects an empty content column to keep the listing small.
+const revisionQuery = `
+	SELECT r.number, r.post_id, r.title, %s, COALESCE(r.cover_image, ''),
+	       r.editor_id, u.name, u.avatar_url, r.created_at, COALESCE(r.restored_from, 0)
+	FROM post_revisions r
+	LEFT JOIN users u ON u.id = r.editor_id
+`
+
+func scanRevision(row interface{ Scan(...any) error }) (*pb.Revision, error) {
+	var rev pb.Revision
+	var editorID, editorName, editorAvatar sql.NullString
+	var createdAt time.Time
+	err := row.Scan(&rev.Number, &rev.PostId, &rev.Title, &rev.Content, &rev.CoverImage,
+		&editorID, &editorName, &editorAvatar, &createdAt, &rev.RestoredFrom)
+	if err != nil {
+		return nil, err
+	}
+	rev.CreatedAt = createdAt.Format(time.RFC3339)
+	if editorID.Valid {
+		rev.Editor = &pb.Author{Id: editorID.String, Name: editorName.String, AvatarUrl: editorAvatar.String}
+	}
+	return &rev, nil
+}
+
+func (s *Service) getRevision(ctx context.Context, postID string, number int32) (*pb.Revision, error) {
+	rev, err := scanRevision(s.db.QueryRowContext(ctx,
+		fmt.Sprintf(revisionQuery, "r.content")+" WHERE r.post_id = $1 AND r.number = $2",
+		postID, number,
+	))
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "revision %d not found", number)
+	}
+	if err != nil {
+		s.logger.Error("failed to get revision", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return rev, nil
+}
+
+// ListRevisions returns the post's revisions without their content. Like the
+// other revision RPCs, it is only open to those who may edit the post.
+func (s *Service) ListRevisions(ctx context.Context, req *pb.ListRevisionsRequest) (*pb.ListRevisionsResponse, error) {
+	if _, err := s.authorizePostChange(ctx, req.PostId, req.UserId, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(revisionQuery, "''")+" WHERE r.post_id = $1 ORDER BY r.number DESC",
+		req.PostId,
+	)
+	if err != nil {
+		s.logger.Error("failed to list revisions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	revisions := []*pb.Revision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			s.logger.Error("failed to scan revision", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read revisions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &pb.ListRevisionsResponse{Revisions: revisions}, nil
+}
+
+func (s *Service) GetRevision(ctx context.Context, req *pb.GetRevisionRequest) (*pb.GetRevisionResponse, error) {
+	if _, err := s.authorizePostChange(ctx, req.PostId, req.UserId, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	rev, err := s.getRevision(ctx, req.PostId, req.Number)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetRevisionResponse{Revision: rev}, nil
+}
+
+// DiffRevisions compares two revisions of a post. to defaults to the latest
+// revision and from to the one before to.
+func (s *Service) DiffRevisions(ctx context.Context, req *pb.DiffRevisionsRequest) (*pb.DiffRevisionsResponse, error) {
+	if _, err := s.authorizePostChange(ctx, req.PostId, req.UserId, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	var diffContent func(a, b string) []diff.Chunk
+	switch req.Granularity {
+	case "", "line":
+		diffContent = diff.Lines
+	case "word":
+		diffContent = diff.Words
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown granularity %q", req.Granularity)
+	}
+
+	to := req.To
+	if to == 0 {
+		err := s.db.QueryRowContext(ctx,
+			"SELECT COALESCE(MAX(number), 0) FROM post_revisions WHERE post_id = $1",
+			req.PostId,
+		).Scan(&to)
+		if err != nil {
+			s.logger.Error("failed to find latest revision", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+	from := req.From
+	if from == 0 {
+		from = max(to-1, 1)
+	}
+
+	older, err := s.getRevision(ctx, req.PostId, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.getRevision(ctx, req.PostId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DiffRevisionsResponse{
+		Title:          diffChunks(diff.Words(older.Title, newer.Title)),
+		Content:        diffChunks(diffContent(older.Content, newer.Content)),
+		FromCoverImage: older.CoverImage,
+		ToCoverImage:   newer.CoverImage,
+	}, nil
+}
+
+func diffChunks(chunks []diff.Chunk) []*pb.DiffChunk {
+	out := make([]*pb.DiffChunk, len(chunks))
+	for i, c := range chunks {
+		out[i] = &pb.DiffChunk{Op: string(c.Op), Text: c.Text}
+	}
+	return out
+}
+
+// RestoreRevision puts an old revision's title, content and cover image
+// back. The restore is itself saved as a new revision, so nothing is lost.
+func (s *Service) RestoreRevision(ctx context.Context, req *pb.RestoreRevisionRequest) (*pb.RestoreRevisionResponse, error) {
+	// A restore is an edit, so like UpdatePost it must not clobber changes
+	// made since the caller read the post
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	authorID, err := s.authorizePostChange(ctx, req.PostId, req.UserId, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var content string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE posts p
+		SET title = r.title, content = r.content, cover_image = r.cover_image,
+		    version = p.version + 1, updated_at = NOW()
+		FROM post_revisions r
+		WHERE p.id = $1 AND r.post_id = p.id AND r.number = $2 AND p.version = $3
+		RETURNING p.content
+	`, req.PostId, req.Number, req.Version).Scan(&content)
+	if err == sql.ErrNoRows {
+		var current int64
+		err := tx.QueryRowContext(ctx, "SELECT version FROM posts WHERE id = $1", req.PostId).Scan(&current)
+		if err == nil && current != req.Version {
+			return nil, common.VersionConflictError("post", current)
+		}
+		return nil, status.Errorf(codes.NotFound, "revision %d not found", req.Number)
+	}
+	if err != nil {
+		s.logger.Error("failed to restore revision", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if _, err := s.storeRendering(ctx, tx, req.PostId, content); err != nil {
+		s.logger.Error("failed to store rendered post", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	number, err := s.recordRevision(ctx, tx, req.PostId, req.UserId, req.Number)
+	if err != nil {
+		s.logger.Error("failed to record revision", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit restore", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("revision restored",
+		zap.String("post_id", req.PostId), zap.Int32("from", req.Number), zap.Int32("revision", number))
+
+	post, err := s.GetPost(ctx, &pb.GetPostRequest{PostId: req.PostId, CurrentUserId: authorID})
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.getRevision(ctx, req.PostId, number)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RestoreRevisionResponse{Post: post.Post, Revision: rev}, nil
+}
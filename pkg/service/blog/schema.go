@@ -0,0 +1,330 @@
+package blog
+
+import (
+	"go.uber.org/zap"
+)
+
+// ensureSchema creates the blog tables if they are missing and applies
+// additive migrations to databases created from older definitions. Every
+// statement is idempotent, so it runs on each startup.
+func (s *Service) ensureSchema() {
+	s.logger.Info("Ensuring tables exist...")
+
+	// Core Tables (Postgres Init should handle this but for safety/Lambda)
+	// Note: In serverless, migrations usually happen externally. Here we do "ensure definitions".
+
+	_, err := s.db.Exec(`
+		CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+		CREATE TABLE IF NOT EXISTS users (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(255) NOT NULL,
+			email VARCHAR(255) UNIQUE NOT NULL,
+			bio TEXT,
+			avatar_url VARCHAR(255),
+			last_event_id BIGINT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS posts (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			title VARCHAR(255) NOT NULL,
+			subtitle VARCHAR(255),
+			content TEXT NOT NULL,
+			author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			slug VARCHAR(255) UNIQUE,
+			status VARCHAR(50) DEFAULT 'draft',
+			reading_time INTEGER,
+			published_at TIMESTAMP WITH TIME ZONE,
+			cover_image VARCHAR(255),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS tags (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(100) UNIQUE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		
+		CREATE TABLE IF NOT EXISTS post_tags (
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			PRIMARY KEY (post_id, tag_id)
+		);
+		
+		CREATE TABLE IF NOT EXISTS comments (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			content TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS interactions (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			type VARCHAR(50) NOT NULL,
+			count INTEGER DEFAULT 1,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE(post_id, user_id, type)
+		);
+
+		CREATE TABLE IF NOT EXISTS follows (
+			follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (follower_id, followee_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS bookmarks (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (user_id, post_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS notifications (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			type VARCHAR(20) NOT NULL,
+			actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			post_id UUID REFERENCES posts(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			read BOOLEAN DEFAULT FALSE
+		);
+	`)
+	if err != nil {
+		// Log but don't fail, assume tables might exist
+		s.logger.Warn("Table creation warning", zap.Error(err))
+	}
+
+	// Users are a projection of the auth service's accounts (see
+	// ApplyUserEvent). Credentials live only in auth.
+	_, err = s.db.Exec(`
+		ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS last_event_id BIGINT;
+	`)
+	if err != nil {
+		s.logger.Error("failed to migrate users table", zap.Error(err))
+	}
+
+	// Set when a deleted user is anonymized rather than removed (see
+	// DELETED_USER_CONTENT)
+	_, err = s.db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add users deleted_at column", zap.Error(err))
+	}
+
+	// Handles come from the auth service, which keeps them unique. Retired
+	// handles are remembered so old profile links still resolve for a while.
+	_, err = s.db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(30);
+		CREATE INDEX IF NOT EXISTS idx_users_username ON users(LOWER(username));
+
+		CREATE TABLE IF NOT EXISTS user_handle_history (
+			handle VARCHAR(30) NOT NULL,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			retired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_handle_history_handle ON user_handle_history(LOWER(handle), retired_at);
+	`)
+	if err != nil {
+		s.logger.Error("failed to add usernames", zap.Error(err))
+	}
+
+	// Posts waiting for publishScheduledPosts. The partial index keeps its
+	// per-minute scan cheap. Databases created from database/schema.sql
+	// have a status check that predates scheduling, so it is replaced.
+	_, err = s.db.Exec(`
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+		ALTER TABLE posts ADD CONSTRAINT posts_status_check
+			CHECK (status IN ('draft', 'scheduled', 'published', 'archived'));
+		CREATE INDEX IF NOT EXISTS idx_posts_scheduled ON posts(scheduled_at) WHERE status = 'scheduled';
+		CREATE INDEX IF NOT EXISTS idx_posts_author_status ON posts(author_id, status);
+	`)
+	if err != nil {
+		s.logger.Error("failed to add post scheduling", zap.Error(err))
+	}
+
+	// Every save of a post is kept (see recordRevision). Posts written
+	// before revisions existed start out with their current text.
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS post_revisions (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			number INTEGER NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			content TEXT NOT NULL,
+			cover_image VARCHAR(255),
+			editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			restored_from INTEGER,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE (post_id, number)
+		);
+
+		INSERT INTO post_revisions (post_id, number, title, content, cover_image, editor_id, created_at)
+		SELECT p.id, 1, p.title, p.content, p.cover_image, p.author_id, p.updated_at
+		FROM posts p
+		WHERE NOT EXISTS (SELECT 1 FROM post_revisions r WHERE r.post_id = p.id);
+	`)
+	if err != nil {
+		s.logger.Error("failed to create post_revisions table", zap.Error(err))
+	}
+
+	// Versions for optimistic concurrency. A user's is a copy of their
+	// profile version in the auth service.
+	_, err = s.db.Exec(`
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add version columns", zap.Error(err))
+	}
+
+	// content rendered by the Markdown pipeline. Posts whose render_version
+	// is behind markdown.Version are rendered again by rerenderPosts.
+	_, err = s.db.Exec(`
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS content_html TEXT;
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS excerpt TEXT;
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS toc JSONB;
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS render_version INTEGER NOT NULL DEFAULT 0;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add rendered content columns", zap.Error(err))
+	}
+
+	// Word counts come from the Markdown pipeline, along with reading_time
+	_, err = s.db.Exec(`
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS word_count INTEGER NOT NULL DEFAULT 0;
+	`)
+	if err != nil {
+		s.logger.Error("failed to add word_count column", zap.Error(err))
+	}
+
+	// Full-text search. search_vector weighs title over subtitle over tags
+	// over body, and the triggers keep it current as posts and their tags
+	// change.
+	_, err = s.db.Exec(`
+		ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+		CREATE INDEX IF NOT EXISTS idx_posts_search ON posts USING GIN (search_vector);
+
+		CREATE OR REPLACE FUNCTION post_search_vector(post_id UUID, title TEXT, subtitle TEXT, content TEXT)
+		RETURNS TSVECTOR AS $$
+			SELECT setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+			       setweight(to_tsvector('english', COALESCE(subtitle, '')), 'B') ||
+			       setweight(to_tsvector('english', COALESCE((
+			           SELECT string_agg(t.name, ' ') FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+			           WHERE pt.post_id = post_search_vector.post_id
+			       ), '')), 'C') ||
+			       setweight(to_tsvector('english', COALESCE(content, '')), 'D')
+		$$ LANGUAGE SQL STABLE;
+
+		CREATE OR REPLACE FUNCTION posts_search_update() RETURNS TRIGGER AS $$
+		BEGIN
+			NEW.search_vector := post_search_vector(NEW.id, NEW.title, NEW.subtitle, NEW.content);
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS posts_search_update ON posts;
+		CREATE TRIGGER posts_search_update BEFORE INSERT OR UPDATE OF title, subtitle, content ON posts
+			FOR EACH ROW EXECUTE FUNCTION posts_search_update();
+
+		CREATE OR REPLACE FUNCTION post_tags_search_update() RETURNS TRIGGER AS $$
+		DECLARE
+			changed UUID;
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				changed := OLD.post_id;
+			ELSE
+				changed := NEW.post_id;
+			END IF;
+			UPDATE posts p SET search_vector = post_search_vector(p.id, p.title, p.subtitle, p.content)
+			WHERE p.id = changed;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS post_tags_search_update ON post_tags;
+		CREATE TRIGGER post_tags_search_update AFTER INSERT OR DELETE ON post_tags
+			FOR EACH ROW EXECUTE FUNCTION post_tags_search_update();
+
+		UPDATE posts SET search_vector = post_search_vector(id, title, subtitle, content)
+		WHERE search_vector IS NULL;
+	`)
+	if err != nil {
+		s.logger.Error("failed to set up post search", zap.Error(err))
+	}
+
+	// Blocks between users, and trigram indexes for finding users and tags
+	// by partial or misspelled names
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_blocks (
+			blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (blocker_id, blocked_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked ON user_blocks(blocked_id);
+
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+		CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (name gin_trgm_ops);
+		CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+		CREATE INDEX IF NOT EXISTS idx_tags_name_trgm ON tags USING GIN (name gin_trgm_ops);
+	`)
+	if err != nil {
+		s.logger.Error("failed to set up user and tag search", zap.Error(err))
+	}
+
+	// Home feeds: followed tags, posts already read, and indexes for
+	// walking published posts newest first, overall and per author or tag
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS tag_follows (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (user_id, tag_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS post_reads (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			read_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (user_id, post_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_posts_published ON posts(published_at DESC, id DESC) WHERE status = 'published';
+		CREATE INDEX IF NOT EXISTS idx_posts_author_published ON posts(author_id, published_at DESC, id DESC) WHERE status = 'published';
+		CREATE INDEX IF NOT EXISTS idx_post_tags_tag ON post_tags(tag_id);
+	`)
+	if err != nil {
+		s.logger.Error("failed to set up home feeds", zap.Error(err))
+	}
+
+	// Trending rankings, recomputed by refreshTrending
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS trending_scores (
+			period VARCHAR(10) NOT NULL,
+			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+			score DOUBLE PRECISION NOT NULL,
+			computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (period, post_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_trending_scores_rank ON trending_scores(period, score DESC);
+		CREATE INDEX IF NOT EXISTS idx_interactions_created ON interactions(created_at);
+		CREATE INDEX IF NOT EXISTS idx_comments_created ON comments(created_at);
+		CREATE INDEX IF NOT EXISTS idx_bookmarks_created ON bookmarks(created_at);
+		CREATE INDEX IF NOT EXISTS idx_post_reads_read ON post_reads(read_at);
+	`)
+	if err != nil {
+		s.logger.Error("failed to set up trending rankings", zap.Error(err))
+	}
+}
@@ -0,0 +1,383 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "project/pkg/proto/blog"
+)
+
+// ts_headline marks matches with these control characters, which can't
+// appear in the escaped output, and highlight turns them into <mark> tags
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// How many tags and authors are listed as facets
+const facetLimit = 20
+
+// searchMatches selects the published posts matching the query in $1 that
+// have tag $2, were written by author $3 and whose author and the viewer in
+// $4 haven't blocked each other. An empty tag, author or viewer leaves that
+// filter out.
+var searchMatches = `
+	SELECT p.id, p.author_id, ts_rank_cd(p.search_vector, q) AS rank
+	FROM posts p, websearch_to_tsquery('english', $1) q
+	WHERE p.status = 'published' AND p.search_vector @@ q
+	  AND ($2 = '' OR EXISTS (
+	      SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+	      WHERE pt.post_id = p.id AND t.name = $2
+	  ))
+	  AND ($3 = '' OR p.author_id::text = $3)
+	  AND ` + notBlocked("p.author_id", "$4")
+
+// notBlocked is a condition that holds unless the user in column and the
+// viewer in param have blocked each other, in either direction. An empty
+// viewer has blocked no one.
+func notBlocked(column, param string) string {
+	return fmt.Sprintf(`(%[2]s = '' OR NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_id::text = %[2]s AND b.blocked_id = %[1]s)
+		   OR (b.blocker_id = %[1]s AND b.blocked_id::text = %[2]s)
+	))`, column, param)
+}
+
+// SearchPosts ranks published posts against a web search style query, with
+// highlighted snippets and facet counts by tag and author
+func (s *Service) SearchPosts(ctx context.Context, req *pb.SearchPostsRequest) (*pb.SearchPostsResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	var authorID string
+	if req.Author != "" {
+		id, _, err := s.resolveUserRef(ctx, req.Author)
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "author not found")
+		}
+		if err != nil {
+			s.logger.Error("failed to resolve author", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		authorID = id
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	offset := max(int(req.Page-1)*limit, 0)
+
+	var total int32
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+searchMatches+") m",
+		query, req.Tag, authorID, req.CurrentUserId,
+	).Scan(&total)
+	if err != nil {
+		s.logger.Error("failed to count search results", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	hits, err := s.searchPostHits(ctx, query, req.Tag, authorID, req.CurrentUserId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.searchFacets(ctx, `
+		WITH m AS (`+searchMatches+`)
+		SELECT t.name, t.name, COUNT(*) FROM m
+		JOIN post_tags pt ON pt.post_id = m.id
+		JOIN tags t ON t.id = pt.tag_id
+		GROUP BY t.name
+		ORDER BY COUNT(*) DESC, t.name
+		LIMIT $5
+	`, query, "", authorID, req.CurrentUserId, facetLimit)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := s.searchFacets(ctx, `
+		WITH m AS (`+searchMatches+`)
+		SELECT u.id, u.name, COUNT(*) FROM m
+		JOIN users u ON u.id = m.author_id
+		GROUP BY u.id, u.name
+		ORDER BY COUNT(*) DESC, u.name
+		LIMIT $5
+	`, query, req.Tag, "", req.CurrentUserId, facetLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SearchPostsResponse{Hits: hits, Total: total, Tags: tags, Authors: authors}, nil
+}
+
+// searchPostHits returns a page of the posts selected by searchMatches, best
+// match first, with highlighted titles and snippets
+func (s *Service) searchPostHits(ctx context.Context, query, tag, authorID, viewerID string, limit, offset int) ([]*pb.SearchHit, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH m AS (`+searchMatches+`)
+		SELECT p.id, p.title, COALESCE(p.subtitle, ''), COALESCE(p.excerpt, ''), p.word_count,
+		       COALESCE(p.reading_time, 0), p.author_id, u.name, u.avatar_url,
+		       p.created_at, p.published_at, COALESCE(p.cover_image, ''),
+		       ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name),
+		       m.rank,
+		       ts_headline('english', p.title, q, $7),
+		       ts_headline('english', p.content, q, $8)
+		FROM m
+		JOIN posts p ON p.id = m.id
+		JOIN users u ON u.id = p.author_id,
+		     websearch_to_tsquery('english', $1) q
+		ORDER BY m.rank DESC, p.published_at DESC
+		LIMIT $5 OFFSET $6
+	`, query, tag, authorID, viewerID, limit, offset,
+		`HighlightAll=true, StartSel="`+highlightStart+`", StopSel="`+highlightStop+`"`,
+		`MaxFragments=2, MinWords=10, MaxWords=30, FragmentDelimiter=" … ", StartSel="`+highlightStart+`", StopSel="`+highlightStop+`"`,
+	)
+	if err != nil {
+		s.logger.Error("failed to search posts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	hits := []*pb.SearchHit{}
+	for rows.Next() {
+		var post pb.Post
+		var hit pb.SearchHit
+		var authorName, authorAvatar sql.NullString
+		var createdAt time.Time
+		var publishedAt sql.NullTime
+		var title, snippet string
+		err := rows.Scan(&post.Id, &post.Title, &post.Subtitle, &post.Excerpt, &post.WordCount,
+			&post.ReadingTime, &post.AuthorId, &authorName, &authorAvatar, &createdAt, &publishedAt, &post.CoverImage,
+			pq.Array(&post.Tags), &hit.Rank, &title, &snippet)
+		if err != nil {
+			s.logger.Error("failed to scan search result", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		post.Status = postPublished
+		post.CreatedAt = createdAt.Format(time.RFC3339)
+		if publishedAt.Valid {
+			post.PublishedAt = publishedAt.Time.Format(time.RFC3339)
+		}
+		post.Author = &pb.Author{Id: post.AuthorId, Name: authorName.String, AvatarUrl: authorAvatar.String}
+		hit.Post = &post
+		hit.TitleHighlight = highlight(title)
+		hit.Snippet = highlight(snippet)
+		hits = append(hits, &hit)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read search results", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return hits, nil
+}
+
+func (s *Service) searchFacets(ctx context.Context, query string, args ...any) ([]*pb.Facet, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Error("failed to count search facets", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	facets := []*pb.Facet{}
+	for rows.Next() {
+		var f pb.Facet
+		if err := rows.Scan(&f.Value, &f.Label, &f.Count); err != nil {
+			s.logger.Error("failed to scan search facet", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		facets = append(facets, &f)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read search facets", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return facets, nil
+}
+
+// highlight escapes a ts_headline fragment for HTML and marks its matches
+func highlight(fragment string) string {
+	escaped := html.EscapeString(fragment)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
+
+// Search result types
+const (
+	resultUser = "user"
+	resultTag  = "tag"
+	resultPost = "post"
+)
+
+// Search finds users, tags and posts for one query. Users and tags are
+// matched on trigram similarity, so typos still find them; posts use the
+// full-text index like SearchPosts.
+func (s *Service) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	types := map[string]bool{}
+	for _, t := range req.Types {
+		switch t {
+		case resultUser, resultTag, resultPost:
+			types[t] = true
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown result type %q", t)
+		}
+	}
+	if len(types) == 0 {
+		types = map[string]bool{resultUser: true, resultTag: true, resultPost: true}
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+	contains := "%" + escapeLike(strings.TrimPrefix(query, "@")) + "%"
+
+	results := []*pb.SearchResult{}
+	if types[resultUser] {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT u.id, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, ''), COALESCE(u.bio, ''),
+			       GREATEST(similarity(u.name, $1), similarity(COALESCE(u.username, ''), $1)) AS score
+			FROM users u
+			WHERE u.deleted_at IS NULL
+			  AND (u.name % $1 OR u.username % $1 OR u.name ILIKE $2 OR u.username ILIKE $2)
+			  AND `+notBlocked("u.id", "$3")+`
+			ORDER BY score DESC, u.name
+			LIMIT $4
+		`, strings.TrimPrefix(query, "@"), contains, req.CurrentUserId, limit)
+		if err != nil {
+			s.logger.Error("failed to search users", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var u pb.UserSummary
+			var score float64
+			if err := rows.Scan(&u.Id, &u.Name, &u.Username, &u.AvatarUrl, &u.Bio, &score); err != nil {
+				s.logger.Error("failed to scan user result", zap.Error(err))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			results = append(results, &pb.SearchResult{Type: resultUser, Score: score, User: &u})
+		}
+		if err := rows.Err(); err != nil {
+			s.logger.Error("failed to read user results", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if types[resultTag] {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT t.name, COUNT(p.id), similarity(t.name, $1) AS score
+			FROM tags t
+			LEFT JOIN post_tags pt ON pt.tag_id = t.id
+			LEFT JOIN posts p ON p.id = pt.post_id AND p.status = 'published'
+			WHERE t.name % $1 OR t.name ILIKE $2
+			GROUP BY t.id, t.name
+			ORDER BY score DESC, COUNT(p.id) DESC
+			LIMIT $3
+		`, query, contains, limit)
+		if err != nil {
+			s.logger.Error("failed to search tags", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var t pb.TagSummary
+			var score float64
+			if err := rows.Scan(&t.Name, &t.PostCount, &score); err != nil {
+				s.logger.Error("failed to scan tag result", zap.Error(err))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			results = append(results, &pb.SearchResult{Type: resultTag, Score: score, Tag: &t})
+		}
+		if err := rows.Err(); err != nil {
+			s.logger.Error("failed to read tag results", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if types[resultPost] {
+		hits, err := s.searchPostHits(ctx, query, "", "", req.CurrentUserId, limit, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			results = append(results, &pb.SearchResult{Type: resultPost, Score: hit.Rank, Post: hit})
+		}
+	}
+
+	return &pb.SearchResponse{Results: results}, nil
+}
+
+// SuggestSearch completes a prefix to user names, handles and tags, for
+// search-as-you-type. Matching is by prefix of the name or of any word in
+// it, which the trigram indexes keep fast.
+func (s *Service) SuggestSearch(ctx context.Context, req *pb.SuggestSearchRequest) (*pb.SuggestSearchResponse, error) {
+	prefix := strings.TrimPrefix(strings.TrimSpace(req.Prefix), "@")
+	suggestions := []*pb.Suggestion{}
+	if prefix == "" {
+		return &pb.SuggestSearchResponse{Suggestions: suggestions}, nil
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 10 {
+		limit = 5
+	}
+	starts := escapeLike(prefix) + "%"
+	wordStarts := "% " + starts
+
+	rows, err := s.db.QueryContext(ctx, `
+		(SELECT 'user', u.id::text, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, '')
+		 FROM users u
+		 WHERE u.deleted_at IS NULL
+		   AND (u.name ILIKE $1 OR u.name ILIKE $2 OR u.username ILIKE $1)
+		   AND `+notBlocked("u.id", "$4")+`
+		 ORDER BY GREATEST(similarity(u.name, $3), similarity(COALESCE(u.username, ''), $3)) DESC, u.name
+		 LIMIT $5)
+		UNION ALL
+		(SELECT 'tag', t.name, t.name, '', ''
+		 FROM tags t
+		 WHERE t.name ILIKE $1 OR t.name ILIKE $2
+		 ORDER BY similarity(t.name, $3) DESC, t.name
+		 LIMIT $5)
+	`, starts, wordStarts, prefix, req.CurrentUserId, limit)
+	if err != nil {
+		s.logger.Error("failed to suggest search terms", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sg pb.Suggestion
+		if err := rows.Scan(&sg.Type, &sg.Value, &sg.Label, &sg.Username, &sg.AvatarUrl); err != nil {
+			s.logger.Error("failed to scan suggestion", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		suggestions = append(suggestions, &sg)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read suggestions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &pb.SuggestSearchResponse{Suggestions: suggestions}, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -3,11 +3,13 @@ package blog
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -15,10 +17,15 @@ import (
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"project/pkg/common"
+	"project/pkg/markdown"
 	pb "project/pkg/proto/blog"
+	"project/pkg/tokens"
 )
 
 type Service struct {
@@ -69,12 +76,17 @@ func Run() {
 		db:     db,
 	}
 
-	// Run Seeding (Dev mode only for safety)
+	// Create tables and apply migrations, then seed (dev mode only for safety)
+	svc.ensureSchema()
 	svc.seedData()
 
 	// Start Activity Simulation
 	go svc.simulateActivity()
 
+	go svc.publishScheduledPosts()
+	go svc.rerenderPosts()
+	go svc.refreshTrending()
+
 	// Start gRPC server
 	svc.startGRPCServer()
 }
@@ -96,113 +108,18 @@ func (s *Service) seedData() {
 
 	s.logger.Info("Starting initial data seeding...")
 
-	// 1. Ensure tables exist
-	s.logger.Info("Ensuring tables exist...")
-
-	// Core Tables (Postgres Init should handle this but for safety/Lambda)
-	// Note: In serverless, migrations usually happen externally. Here we do "ensure definitions".
-
-	_, err = s.db.Exec(`
-		CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-
-		CREATE TABLE IF NOT EXISTS users (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			name VARCHAR(255) NOT NULL,
-			email VARCHAR(255) UNIQUE NOT NULL,
-			password_hash VARCHAR(255) NOT NULL,
-			bio TEXT,
-			avatar_url VARCHAR(255),
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		);
-
-		CREATE TABLE IF NOT EXISTS posts (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			title VARCHAR(255) NOT NULL,
-			subtitle VARCHAR(255),
-			content TEXT NOT NULL,
-			author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			slug VARCHAR(255) UNIQUE,
-			status VARCHAR(50) DEFAULT 'draft',
-			reading_time INTEGER,
-			published_at TIMESTAMP WITH TIME ZONE,
-			cover_image VARCHAR(255),
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		);
-
-		CREATE TABLE IF NOT EXISTS tags (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			name VARCHAR(100) UNIQUE NOT NULL,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		);
-		
-		CREATE TABLE IF NOT EXISTS post_tags (
-			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
-			tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
-			PRIMARY KEY (post_id, tag_id)
-		);
-		
-		CREATE TABLE IF NOT EXISTS comments (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			content TEXT NOT NULL,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		);
-
-		CREATE TABLE IF NOT EXISTS interactions (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			type VARCHAR(50) NOT NULL,
-			count INTEGER DEFAULT 1,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			UNIQUE(post_id, user_id, type)
-		);
-
-		CREATE TABLE IF NOT EXISTS follows (
-			follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			PRIMARY KEY (follower_id, followee_id)
-		);
-
-		CREATE TABLE IF NOT EXISTS bookmarks (
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			PRIMARY KEY (user_id, post_id)
-		);
-
-		CREATE TABLE IF NOT EXISTS notifications (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			type VARCHAR(20) NOT NULL,
-			actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			post_id UUID REFERENCES posts(id) ON DELETE CASCADE,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			read BOOLEAN DEFAULT FALSE
-		);
-	`)
-	if err != nil {
-		// Log but don't fail, assume tables might exist
-		s.logger.Warn("Table creation warning", zap.Error(err))
-	}
-
-	// 2. Insert Main User (Alice Chen)
+	// 1. Insert Main User (Alice Chen)
 	aliceID := "00000000-0000-0000-0000-000000000001"
 	_, err = s.db.Exec(`
-		INSERT INTO users (id, name, email, password_hash, bio, avatar_url, created_at, updated_at) 
-		VALUES ($1, 'Alice Chen', 'alice@example.com', 'hashed_placeholder', 'Senior Software Engineer | Tech Enthusiast.', 'https://ui-avatars.com/api/?name=Alice+Chen&background=0D8ABC&color=fff', NOW(), NOW())
+		INSERT INTO users (id, name, email, bio, avatar_url, username, created_at, updated_at) 
+		VALUES ($1, 'Alice Chen', 'alice@example.com', 'Senior Software Engineer | Tech Enthusiast.', 'https://ui-avatars.com/api/?name=Alice+Chen&background=0D8ABC&color=fff', 'alice', NOW(), NOW())
 		ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email
 	`, aliceID)
 	if err != nil {
 		s.logger.Fatal("failed to seed Alice", zap.Error(err))
 	}
 
-	// 3. Generate 40 Users
+	// 2. Generate 40 Users
 	firstNames := []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen"}
 	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin"}
 	titles := []string{"Product Manager", "UX Designer", "Software Engineer", "Data Scientist", "DevOps Engineer", "Tech Writer", "Startup Founder", "Cloud Architect", "Frontend Developer", "Backend Developer"}
@@ -223,8 +140,8 @@ func (s *Service) seedData() {
 
 		var id string
 		err := s.db.QueryRow(`
-			INSERT INTO users (name, email, password_hash, bio, avatar_url, created_at, updated_at)
-			VALUES ($1, $2, 'hashed_placeholder', $3, $4, NOW(), NOW())
+			INSERT INTO users (name, email, bio, avatar_url, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, NOW(), NOW())
 			RETURNING id
 		`, name, email, bio, avatar).Scan(&id)
 
@@ -235,7 +152,7 @@ func (s *Service) seedData() {
 		userIDs = append(userIDs, id)
 	}
 
-	// 4. Generate Posts
+	// 3. Generate Posts
 	topics := []string{"Programming", "Technology", "Design", "Productivity", "Writing", "Self Improvement"}
 
 	// Create Tags first
@@ -392,7 +309,7 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 
 	// Base query with Claps Count
 	query := `
-		SELECT DISTINCT p.id, p.title, p.subtitle, p.content, p.author_id, p.created_at, p.reading_time,
+		SELECT DISTINCT p.id, p.title, COALESCE(p.subtitle, ''), COALESCE(p.excerpt, ''), p.word_count, p.author_id, p.created_at, p.reading_time,
 		       u.name as author_name, u.avatar_url, p.published_at,
 			   (SELECT COUNT(*) FROM interactions WHERE post_id = p.id AND type = 'clap') as claps_count,
 			   EXISTS(SELECT 1 FROM bookmarks WHERE post_id = p.id AND user_id = $1::uuid) as is_bookmarked,
@@ -424,10 +341,10 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 		argCount++
 	}
 
-	// Full-text search on title and content
+	// Full-text search; SearchPosts ranks the matches instead
 	if req.SearchQuery != "" {
-		args = append(args, "%"+req.SearchQuery+"%")
-		query += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.content ILIKE $%d)", argCount, argCount)
+		args = append(args, req.SearchQuery)
+		query += fmt.Sprintf(" AND p.search_vector @@ websearch_to_tsquery('english', $%d)", argCount)
 		argCount++
 	}
 
@@ -455,7 +372,6 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 		var post pb.Post
 		var authorName sql.NullString
 		var avatarURL sql.NullString
-		var subtitle sql.NullString
 		var readingTime sql.NullInt32
 		var publishedAt sql.NullTime
 		var clapsCount int32
@@ -466,8 +382,9 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 		err := rows.Scan(
 			&post.Id,
 			&post.Title,
-			&subtitle,
-			&post.Content,
+			&post.Subtitle,
+			&post.Excerpt,
+			&post.WordCount,
 			&post.AuthorId,
 			&createdAt,
 			&readingTime,
@@ -486,6 +403,10 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 		if createdAt.Valid {
 			post.CreatedAt = createdAt.Time.Format(time.RFC3339)
 		}
+		post.Status = postPublished
+		if publishedAt.Valid {
+			post.PublishedAt = publishedAt.Time.Format(time.RFC3339)
+		}
 
 		// Parse tags
 		tagsString := string(tagsBytes)
@@ -496,11 +417,7 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 		}
 
 		post.ClapsCount = clapsCount
-
-		// Set optional fields logic
-		if subtitle.Valid && subtitle.String != "" {
-			post.Content = subtitle.String
-		}
+		post.ReadingTime = readingTime.Int32
 
 		// Map Author
 		post.Author = &pb.Author{
@@ -525,14 +442,23 @@ func (s *Service) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.
 func (s *Service) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
 	s.logger.Info("GetUser request", zap.String("id", req.Id))
 
+	// Profiles can be addressed by handle as well as by ID
+	userID, moved, err := s.resolveUserRef(ctx, req.Id)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.logger.Error("failed to resolve user", zap.Error(err))
+		}
+		return nil, err
+	}
+
 	var user pb.User
 	var bio sql.NullString
 	var avatarURL sql.NullString
 
 	// Get user details
-	query := `SELECT id, name, email, bio, avatar_url FROM users WHERE id = $1`
-	err := s.db.QueryRowContext(ctx, query, req.Id).Scan(
-		&user.Id, &user.Name, &user.Email, &bio, &avatarURL,
+	query := `SELECT id, name, email, bio, avatar_url, COALESCE(username, ''), version FROM users WHERE id = $1`
+	err = s.db.QueryRowContext(ctx, query, userID).Scan(
+		&user.Id, &user.Name, &user.Email, &bio, &avatarURL, &user.Username, &user.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -550,42 +476,85 @@ func (s *Service) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetU
 	}
 
 	// Get follower counts
-	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM follows WHERE followee_id = $1", req.Id).Scan(&user.Followers)
-	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM follows WHERE follower_id = $1", req.Id).Scan(&user.Following)
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM follows WHERE followee_id = $1", userID).Scan(&user.Followers)
+	s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM follows WHERE follower_id = $1", userID).Scan(&user.Following)
 
 	// Check IsFollowing if CurrentUserId provided
-	if req.CurrentUserId != "" && req.CurrentUserId != req.Id {
+	if req.CurrentUserId != "" && req.CurrentUserId != userID {
 		var isFollowing bool
-		s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)", req.CurrentUserId, req.Id).Scan(&isFollowing)
+		s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)", req.CurrentUserId, userID).Scan(&isFollowing)
 		user.IsFollowing = isFollowing
 	}
 
 	return &pb.GetUserResponse{
-		User: &user,
+		User:  &user,
+		Moved: moved,
 	}, nil
 }
 
 func (s *Service) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.CreatePostResponse, error) {
 	s.logger.Info("CreatePost request", zap.String("title", req.Title))
 
+	postStatus, scheduledAt, err := parsePostStatus(req.Status, req.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	if postStatus == "" {
+		postStatus = postPublished
+	}
+	var publishedAt sql.NullTime
+	if postStatus == postPublished {
+		publishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
 	// Insert post into database
 	query := `
-		INSERT INTO posts (author_id, title, content, cover_image, status, published_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, 'published', NOW(), NOW(), NOW())
-		RETURNING id, created_at
+		INSERT INTO posts (author_id, title, content, cover_image, status, published_at, scheduled_at, subtitle, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NOW(), NOW())
+		RETURNING id, created_at, version
 	`
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	var post pb.Post
-	err := s.db.QueryRowContext(ctx, query, req.AuthorId, req.Title, req.Content, req.CoverImage).Scan(&post.Id, &post.CreatedAt)
+	err = tx.QueryRowContext(ctx, query, req.AuthorId, req.Title, req.Content, req.CoverImage,
+		postStatus, publishedAt, scheduledAt, req.Subtitle).Scan(&post.Id, &post.CreatedAt, &post.Version)
 	if err != nil {
 		s.logger.Error("failed to create post", zap.Error(err))
 		return nil, err
 	}
+	if _, err := s.recordRevision(ctx, tx, post.Id, req.AuthorId, 0); err != nil {
+		s.logger.Error("failed to record revision", zap.Error(err))
+		return nil, err
+	}
+	doc, err := s.storeRendering(ctx, tx, post.Id, req.Content)
+	if err != nil {
+		s.logger.Error("failed to store rendered post", zap.Error(err))
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit post", zap.Error(err))
+		return nil, err
+	}
+	setRendering(&post, doc)
 
 	post.Title = req.Title
+	post.Subtitle = req.Subtitle
 	post.Content = req.Content
 	post.AuthorId = req.AuthorId
 	post.CoverImage = req.CoverImage
+	post.Status = postStatus
+	if publishedAt.Valid {
+		post.PublishedAt = publishedAt.Time.Format(time.RFC3339)
+	}
+	if scheduledAt.Valid {
+		post.ScheduledAt = scheduledAt.Time.Format(time.RFC3339)
+	}
 
 	// Handle Tags
 	var savedTags []string
@@ -694,12 +663,17 @@ func (s *Service) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.GetP
 	var post pb.Post
 	var authorName sql.NullString
 	var avatarURL sql.NullString
-	var publishedAt sql.NullTime
+	var publishedAt, scheduledAt sql.NullTime
 	var coverImage sql.NullString
+	var postStatus sql.NullString
+	var toc []byte
+	var renderVersion int
+	var readingTime sql.NullInt32
 
 	query := `
-		SELECT p.id, p.title, p.content, p.author_id, p.created_at, p.cover_image,
-		       u.name, u.avatar_url, p.published_at,
+		SELECT p.id, p.title, COALESCE(p.subtitle, ''), p.content, p.author_id, p.created_at, p.cover_image,
+		       u.name, u.avatar_url, p.published_at, p.status, p.scheduled_at, p.version,
+		       COALESCE(p.content_html, ''), COALESCE(p.excerpt, ''), p.toc, p.word_count, p.reading_time, p.render_version,
 		       COALESCE((SELECT SUM(count) FROM interactions WHERE post_id = p.id AND type = 'clap'), 0) as claps_count
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
@@ -707,8 +681,9 @@ func (s *Service) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.GetP
 	`
 
 	err := s.db.QueryRowContext(ctx, query, req.PostId).Scan(
-		&post.Id, &post.Title, &post.Content, &post.AuthorId, &post.CreatedAt, &coverImage,
-		&authorName, &avatarURL, &publishedAt, &post.ClapsCount,
+		&post.Id, &post.Title, &post.Subtitle, &post.Content, &post.AuthorId, &post.CreatedAt, &coverImage,
+		&authorName, &avatarURL, &publishedAt, &postStatus, &scheduledAt, &post.Version,
+		&post.ContentHtml, &post.Excerpt, &toc, &post.WordCount, &readingTime, &renderVersion, &post.ClapsCount,
 	)
 
 	if err != nil {
@@ -716,10 +691,50 @@ func (s *Service) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.GetP
 		return nil, err
 	}
 
+	// Unpublished posts don't exist as far as anyone but the author knows
+	if postStatus.String != postPublished && req.CurrentUserId != post.AuthorId {
+		return nil, status.Error(codes.NotFound, "post not found")
+	}
+
+	// Read posts are left out of the reader's home feed
+	if req.MarkRead && req.CurrentUserId != "" && req.CurrentUserId != post.AuthorId {
+		_, err := s.db.ExecContext(ctx, `
+			INSERT INTO post_reads (user_id, post_id) VALUES ($1, $2)
+			ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = NOW()
+		`, req.CurrentUserId, post.Id)
+		if err != nil {
+			s.logger.Error("failed to record read", zap.Error(err))
+		}
+	}
+	post.Status = postStatus.String
+	if publishedAt.Valid {
+		post.PublishedAt = publishedAt.Time.Format(time.RFC3339)
+	}
+	if scheduledAt.Valid {
+		post.ScheduledAt = scheduledAt.Time.Format(time.RFC3339)
+	}
+
 	if coverImage.Valid {
 		post.CoverImage = coverImage.String
 	}
 
+	// Until rerenderPosts gets to it, a stale rendering is redone here
+	if renderVersion < markdown.Version {
+		doc, err := markdown.Render(post.Content)
+		if err != nil {
+			s.logger.Error("failed to render post", zap.Error(err))
+			return nil, err
+		}
+		setRendering(&post, doc)
+	} else {
+		var headings []markdown.Heading
+		if err := json.Unmarshal(toc, &headings); err != nil {
+			s.logger.Error("failed to read table of contents", zap.Error(err))
+		}
+		post.Toc = tocEntries(headings)
+		post.ReadingTime = readingTime.Int32
+	}
+
 	post.Author = &pb.Author{
 		Id:   post.AuthorId,
 		Name: authorName.String,
@@ -747,49 +762,149 @@ func (s *Service) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.GetP
 	return &pb.GetPostResponse{Post: &post}, nil
 }
 
+// postUpdatePaths returns the fields an UpdatePost writes. Without a mask
+// that is every editable field but the subtitle, replacing the post
+// wholesale; the subtitle came later and clients that don't know it would
+// otherwise clear it.
+func postUpdatePaths(mask *fieldmaskpb.FieldMask) (map[string]bool, error) {
+	if len(mask.GetPaths()) == 0 {
+		return map[string]bool{"title": true, "content": true, "cover_image": true, "tags": true}, nil
+	}
+
+	masked := make(map[string]bool, len(mask.Paths))
+	for _, path := range mask.Paths {
+		switch path {
+		case "title", "subtitle", "content", "cover_image", "tags":
+			masked[path] = true
+		case "status", "scheduled_at":
+			// Applied whenever set
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown update_mask path %q", path)
+		}
+	}
+	return masked, nil
+}
+
 func (s *Service) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest) (*pb.UpdatePostResponse, error) {
 	s.logger.Info("UpdatePost request", zap.String("post_id", req.PostId), zap.String("user_id", req.UserId))
 
+	// Edits are made against a version, so concurrent ones can't clobber
+	// each other
+	if req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	paths, err := postUpdatePaths(req.UpdateMask)
+	if err != nil {
+		return nil, err
+	}
+
+	postStatus, scheduledAt, err := parsePostStatus(req.Status, req.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	// Masking an empty schedule unschedules the post, but a post always has
+	// a status, so masking an empty one is an error
+	masked := req.UpdateMask.GetPaths()
+	if postStatus == "" && slices.Contains(masked, "status") {
+		return nil, status.Error(codes.InvalidArgument, "status can't be cleared")
+	}
+	unschedule := postStatus == "" && slices.Contains(masked, "scheduled_at")
+
 	// Check ownership
 	var authorID string
-	err := s.db.QueryRowContext(ctx, "SELECT author_id FROM posts WHERE id = $1", req.PostId).Scan(&authorID)
+	err = s.db.QueryRowContext(ctx, "SELECT author_id FROM posts WHERE id = $1", req.PostId).Scan(&authorID)
 	if err != nil {
 		return nil, fmt.Errorf("post not found")
 	}
 
 	if authorID != req.UserId {
-		return nil, fmt.Errorf("unauthorized: only the author can edit this post")
+		if !slices.Contains(req.Permissions, tokens.PermPostsEditAny) {
+			return nil, fmt.Errorf("unauthorized: only the author can edit this post")
+		}
+		s.logger.Info("post edited by admin", zap.String("post_id", req.PostId), zap.String("user_id", req.UserId))
 	}
 
-	// Update post
-	_, err = s.db.ExecContext(ctx, `
-		UPDATE posts 
-		SET title = $1, content = $2, cover_image = $3, updated_at = NOW()
-		WHERE id = $4
-	`, req.Title, req.Content, req.CoverImage, req.PostId)
+	// Update post, keeping the new text as a revision. Its status and tags
+	// change in the same transaction, so the version is bumped once and
+	// covers them too, even when only tags change.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
 
+	var sets []string
+	var args []interface{}
+	for _, field := range []struct {
+		column string
+		value  string
+		set    string
+	}{
+		{"title", req.Title, "title = $%d"},
+		{"subtitle", req.Subtitle, "subtitle = NULLIF($%d, '')"},
+		{"content", req.Content, "content = $%d"},
+		{"cover_image", req.CoverImage, "cover_image = $%d"},
+	} {
+		if paths[field.column] {
+			args = append(args, field.value)
+			sets = append(sets, fmt.Sprintf(field.set, len(args)))
+		}
+	}
+	// Revisions don't keep the subtitle
+	textChanged := paths["title"] || paths["content"] || paths["cover_image"]
+	if postStatus != "" {
+		args = append(args, postStatus, scheduledAt)
+		sets = append(sets, statusSets(len(args)-1)...)
+	} else if unschedule {
+		sets = append(sets, "status = CASE WHEN status = 'scheduled' THEN 'draft' ELSE status END", "scheduled_at = NULL")
+	}
+	args = append(args, req.PostId, req.Version)
+	sets = append(sets, "version = version + 1", "updated_at = NOW()")
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE posts 
+		SET %s
+		WHERE id = $%d AND version = $%d
+	`, strings.Join(sets, ", "), len(args)-1, len(args)), args...)
 	if err != nil {
 		s.logger.Error("failed to update post", zap.Error(err))
 		return nil, err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var current int64
+		if err := tx.QueryRowContext(ctx, "SELECT version FROM posts WHERE id = $1", req.PostId).Scan(&current); err != nil {
+			return nil, fmt.Errorf("post not found")
+		}
+		return nil, common.VersionConflictError("post", current)
+	}
 
-	// Update tags: delete existing and re-insert
-	s.db.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = $1", req.PostId)
-
-	for _, tagName := range req.Tags {
-		var tagID string
-		err := s.db.QueryRowContext(ctx, `
-			INSERT INTO tags (name) VALUES ($1)
-			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
-			RETURNING id
-		`, tagName).Scan(&tagID)
-		if err == nil {
-			s.db.ExecContext(ctx, "INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", req.PostId, tagID)
+	if textChanged {
+		if _, err := s.recordRevision(ctx, tx, req.PostId, req.UserId, 0); err != nil {
+			s.logger.Error("failed to record revision", zap.Error(err))
+			return nil, err
 		}
 	}
+	if paths["content"] {
+		if _, err := s.storeRendering(ctx, tx, req.PostId, req.Content); err != nil {
+			s.logger.Error("failed to store rendered post", zap.Error(err))
+			return nil, err
+		}
+	}
+	if paths["tags"] {
+		if err := s.replacePostTags(ctx, tx, req.PostId, req.Tags); err != nil {
+			s.logger.Error("failed to update post tags", zap.Error(err))
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit post update", zap.Error(err))
+		return nil, err
+	}
 
 	// Return updated post
-	getResp, err := s.GetPost(ctx, &pb.GetPostRequest{PostId: req.PostId})
+	getResp, err := s.GetPost(ctx, &pb.GetPostRequest{PostId: req.PostId, CurrentUserId: authorID})
 	if err != nil {
 		return nil, err
 	}
@@ -797,6 +912,32 @@ func (s *Service) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest) (*p
 	return &pb.UpdatePostResponse{Post: getResp.Post}, nil
 }
 
+// replacePostTags sets the tags of a post to names, within tx
+func (s *Service) replacePostTags(ctx context.Context, tx *sql.Tx, postID string, names []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = $1", postID); err != nil {
+		return err
+	}
+	for _, name := range names {
+		var tagID string
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO tags (name) VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, name).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			postID, tagID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Service) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*pb.DeletePostResponse, error) {
 	s.logger.Info("DeletePost request", zap.String("post_id", req.PostId), zap.String("user_id", req.UserId))
 
@@ -808,7 +949,10 @@ func (s *Service) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*p
 	}
 
 	if authorID != req.UserId {
-		return nil, fmt.Errorf("unauthorized: only the author can delete this post")
+		if !slices.Contains(req.Permissions, tokens.PermPostsDeleteAny) {
+			return nil, fmt.Errorf("unauthorized: only the author can delete this post")
+		}
+		s.logger.Info("post removed by moderator", zap.String("post_id", req.PostId), zap.String("user_id", req.UserId))
 	}
 
 	// Delete post (cascades to comments, interactions, bookmarks, post_tags)
@@ -947,7 +1091,10 @@ func (s *Service) DeleteComment(ctx context.Context, req *pb.DeleteCommentReques
 	}
 
 	if req.UserId != commentUserID && req.UserId != postAuthorID {
-		return nil, fmt.Errorf("unauthorized")
+		if !slices.Contains(req.Permissions, tokens.PermCommentsDeleteAny) {
+			return nil, fmt.Errorf("unauthorized")
+		}
+		s.logger.Info("comment removed by moderator", zap.String("comment_id", req.CommentId), zap.String("user_id", req.UserId))
 	}
 
 	_, err = s.db.ExecContext(ctx, "DELETE FROM comments WHERE id = $1", req.CommentId)
@@ -980,6 +1127,19 @@ func (s *Service) ToggleFollow(ctx context.Context, req *pb.ToggleFollowRequest)
 			return nil, err
 		}
 	} else {
+		// Users who blocked each other can't follow each other
+		var blocked bool
+		err = s.db.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM user_blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1))
+		`, req.FollowerId, req.FolloweeId).Scan(&blocked)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, status.Error(codes.PermissionDenied, "can't follow this user")
+		}
+
 		// Follow
 		_, err = s.db.ExecContext(ctx, "INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2)", req.FollowerId, req.FolloweeId)
 		if err != nil {
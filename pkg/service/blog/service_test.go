@@ -0,0 +1,1082 @@
+package blog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"project/pkg/common"
+	pb "project/pkg/proto/blog"
+)
+
+const (
+	testPostID = "11111111-1111-1111-1111-111111111111"
+	testUserID = "22222222-2222-2222-2222-222222222222"
+)
+
+func newTestService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &Service{config: &common.Config{}, logger: zap.NewNop(), db: db}, mock
+}
+
+var summaryColumns = []string{"id", "title", "subtitle", "excerpt", "word_count", "reading_time", "author_id",
+	"name", "avatar_url", "created_at", "published_at", "cover_image", "status", "tags", "claps", "bookmarked"}
+
+// sameTime matches a time argument whatever its location
+type sameTime time.Time
+
+func (t sameTime) Match(v driver.Value) bool {
+	got, ok := v.(time.Time)
+	return ok && got.Equal(time.Time(t))
+}
+
+// expectGetPost answers the queries of GetPost for a post
+func expectGetPost(mock sqlmock.Sqlmock, postID, authorID, postStatus string, version int64) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT p.id, p.title, COALESCE(p.subtitle, ''), p.content")).
+		WithArgs(postID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "subtitle", "content", "author_id", "created_at",
+			"cover_image", "name", "avatar_url", "published_at", "status", "scheduled_at", "version", "content_html",
+			"excerpt", "toc", "word_count", "reading_time", "render_version", "claps_count"}).
+			AddRow(postID, "Title", "", "Body", authorID, "2026-01-01T00:00:00Z", nil, "Alice", nil,
+				time.Now(), postStatus, nil, version, "", "", nil, 0, nil, 0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT t.name FROM tags t")).
+		WithArgs(postID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+}
+
+func TestPostUpdatePaths(t *testing.T) {
+	paths, err := postUpdatePaths(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths["subtitle"] || !paths["title"] || !paths["content"] || !paths["cover_image"] || !paths["tags"] {
+		t.Errorf("postUpdatePaths(nil) = %v, want every field but subtitle", paths)
+	}
+
+	paths, err = postUpdatePaths(&fieldmaskpb.FieldMask{Paths: []string{"subtitle", "status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || !paths["subtitle"] {
+		t.Errorf("postUpdatePaths(subtitle, status) = %v, want only subtitle", paths)
+	}
+
+	_, err = postUpdatePaths(&fieldmaskpb.FieldMask{Paths: []string{"author_id"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("postUpdatePaths(author_id) error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestUpdatePostMask(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	// An empty subtitle is stored as NULL, and revisions don't keep it
+	mock.ExpectExec(regexp.QuoteMeta("SET subtitle = NULLIF($1, ''), version = version + 1, updated_at = NOW() WHERE id = $2 AND version = $3")).
+		WithArgs("", testPostID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM post_tags WHERE post_id = $1")).
+		WithArgs(testPostID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	expectGetPost(mock, testPostID, testUserID, postPublished, 4)
+
+	_, err := svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+		PostId: testPostID, UserId: testUserID, Title: "ignored", Version: 3,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"subtitle", "tags"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdatePost() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdatePostClearsSchedule(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SET status = CASE WHEN status = 'scheduled' THEN 'draft' ELSE status END, scheduled_at = NULL, version = version + 1")).
+		WithArgs(testPostID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectGetPost(mock, testPostID, testUserID, postDraft, 4)
+
+	resp, err := svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+		PostId: testPostID, UserId: testUserID, Version: 3,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"scheduled_at"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdatePost() error = %v", err)
+	}
+	if resp.Post.Status != postDraft {
+		t.Errorf("UpdatePost() status = %q, want %q", resp.Post.Status, postDraft)
+	}
+
+	// A post always has a status
+	_, err = svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+		PostId: testPostID, UserId: testUserID, Version: 4,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdatePost() clearing status error = %v, want InvalidArgument", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdatePostWritesTagsInTransaction(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	// The status change is part of the same UPDATE
+	mock.ExpectExec(regexp.QuoteMeta("status = $4::text, scheduled_at = $5")).
+		WithArgs("Title", "Body", "", postDraft, nil, testPostID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO post_revisions")).
+		WithArgs(testPostID, testUserID, int32(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"number"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta("SET content_html = $2")).
+		WithArgs(testPostID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM post_tags WHERE post_id = $1")).
+		WithArgs(testPostID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO tags (name)")).
+		WithArgs("go").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tag-1"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO post_tags")).
+		WithArgs(testPostID, "tag-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectGetPost(mock, testPostID, testUserID, postDraft, 4)
+
+	resp, err := svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+		PostId: testPostID, UserId: testUserID, Title: "Title", Content: "Body",
+		Tags: []string{"go"}, Status: postDraft, Version: 3,
+	})
+	if err != nil {
+		t.Fatalf("UpdatePost() error = %v", err)
+	}
+	if resp.Post.Status != postDraft || resp.Post.Version != 4 {
+		t.Errorf("UpdatePost() = status %q, version %d, want %q, 4", resp.Post.Status, resp.Post.Version, postDraft)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdatePostTagFailureRollsBack(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE posts")).
+		WithArgs("Title", "Body", "", testPostID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO post_revisions")).
+		WithArgs(testPostID, testUserID, int32(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"number"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta("SET content_html = $2")).
+		WithArgs(testPostID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM post_tags WHERE post_id = $1")).
+		WithArgs(testPostID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO tags (name)")).
+		WithArgs("go").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err := svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+		PostId: testPostID, UserId: testUserID, Title: "Title", Content: "Body", Tags: []string{"go"}, Version: 3,
+	})
+	if err == nil {
+		t.Fatal("UpdatePost() succeeded although its tags couldn't be saved")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestUpdatePostVersionConflict(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	_, err := svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{PostId: testPostID, UserId: testUserID})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("UpdatePost() without version error = %v, want InvalidArgument", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("WHERE id = $6 AND version = $7")).
+		WithArgs("Title", "Body", "", postPublished, nil, testPostID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+	mock.ExpectRollback()
+
+	_, err = svc.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+		PostId: testPostID, UserId: testUserID, Title: "Title", Content: "Body",
+		Tags: []string{"go"}, Status: postPublished, Version: 3,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("UpdatePost() error = %v, want FailedPrecondition", err)
+	}
+	if current, ok := common.ConflictVersion(err); !ok || current != 5 {
+		t.Errorf("ConflictVersion() = %d, %v, want 5, true", current, ok)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPublishDuePosts(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE status = 'scheduled' AND scheduled_at <= NOW()")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("post-1").AddRow("post-2"))
+	if got := svc.publishDuePosts(context.Background()); !slices.Equal(got, []string{"post-1", "post-2"}) {
+		t.Errorf("publishDuePosts() = %v, want [post-1 post-2]", got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE status = 'scheduled'")).
+		WillReturnError(errors.New("connection reset"))
+	if got := svc.publishDuePosts(context.Background()); got != nil {
+		t.Errorf("publishDuePosts() after an error = %v, want nil", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPublishPost(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	if _, err := svc.PublishPost(context.Background(), &pb.PublishPostRequest{PostId: testPostID}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("PublishPost() without version error = %v, want InvalidArgument", err)
+	}
+
+	// The status check and the change are one versioned UPDATE
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectExec(regexp.QuoteMeta("WHERE id = $1 AND version = $2 AND status = ANY($5)")).
+		WithArgs(testPostID, int64(3), postPublished, nil, `{"draft","scheduled","archived"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectGetPost(mock, testPostID, testUserID, postPublished, 4)
+
+	resp, err := svc.PublishPost(context.Background(), &pb.PublishPostRequest{PostId: testPostID, UserId: testUserID, Version: 3})
+	if err != nil || resp.Post.Version != 4 {
+		t.Fatalf("PublishPost() = %v, %v, want the post at version 4", resp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPublishPostRejectedTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int64
+		want    codes.Code
+	}{
+		// Nothing changed since the caller read it, so the status is wrong
+		{"already published", 3, codes.FailedPrecondition},
+		{"stale version", 5, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock := newTestService(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+				WithArgs(testPostID).
+				WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+			mock.ExpectExec(regexp.QuoteMeta("AND status = ANY($5)")).
+				WithArgs(testPostID, int64(3), postPublished, nil, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM posts WHERE id = $1")).
+				WithArgs(testPostID).
+				WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(tt.current))
+
+			_, err := svc.PublishPost(context.Background(), &pb.PublishPostRequest{PostId: testPostID, UserId: testUserID, Version: 3})
+			if status.Code(err) != tt.want {
+				t.Fatalf("PublishPost() error = %v, want %v", err, tt.want)
+			}
+			current, conflict := common.ConflictVersion(err)
+			if conflict != (tt.current != 3) || (conflict && current != tt.current) {
+				t.Errorf("ConflictVersion() = %d, %v for current version %d", current, conflict, tt.current)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnpublishDraft(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectExec(regexp.QuoteMeta("AND status = ANY($5)")).
+		WithArgs(testPostID, int64(2), postDraft, nil, `{"published","scheduled"}`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	_, err := svc.UnpublishPost(context.Background(), &pb.UnpublishPostRequest{PostId: testPostID, UserId: testUserID, Version: 2})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UnpublishPost() of a draft error = %v, want FailedPrecondition", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRestoreRevision(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SET title = r.title, content = r.content")).
+		WithArgs(testPostID, int32(2), int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"content"}).AddRow("# Earlier\n\nText"))
+	mock.ExpectExec(regexp.QuoteMeta("SET content_html = $2")).
+		WithArgs(testPostID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO post_revisions")).
+		WithArgs(testPostID, testUserID, int32(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"number"}).AddRow(6))
+	mock.ExpectCommit()
+	expectGetPost(mock, testPostID, testUserID, postPublished, 5)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE r.post_id = $1 AND r.number = $2")).
+		WithArgs(testPostID, int32(6)).
+		WillReturnRows(sqlmock.NewRows([]string{"number", "post_id", "title", "content", "cover_image",
+			"editor_id", "name", "avatar_url", "created_at", "restored_from"}).
+			AddRow(6, testPostID, "Earlier", "# Earlier\n\nText", "", testUserID, "Alice", nil, time.Now(), 2))
+
+	resp, err := svc.RestoreRevision(context.Background(), &pb.RestoreRevisionRequest{
+		PostId: testPostID, Number: 2, UserId: testUserID, Version: 4,
+	})
+	if err != nil {
+		t.Fatalf("RestoreRevision() error = %v", err)
+	}
+	if resp.Revision.Number != 6 || resp.Revision.RestoredFrom != 2 || resp.Post.Version != 5 {
+		t.Errorf("RestoreRevision() = revision %d from %d, post version %d, want 6 from 2, 5",
+			resp.Revision.Number, resp.Revision.RestoredFrom, resp.Post.Version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRestoreRevisionNotFound(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SET title = r.title, content = r.content")).
+		WithArgs(testPostID, int32(9), int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"content"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectRollback()
+
+	_, err := svc.RestoreRevision(context.Background(), &pb.RestoreRevisionRequest{
+		PostId: testPostID, Number: 9, UserId: testUserID, Version: 3,
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreRevision() error = %v, want NotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRestoreRevisionVersionConflict(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	_, err := svc.RestoreRevision(context.Background(), &pb.RestoreRevisionRequest{PostId: testPostID, Number: 2})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("RestoreRevision() without version error = %v, want InvalidArgument", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT author_id FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(testUserID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SET title = r.title, content = r.content")).
+		WithArgs(testPostID, int32(2), int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"content"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM posts WHERE id = $1")).
+		WithArgs(testPostID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+	mock.ExpectRollback()
+
+	_, err = svc.RestoreRevision(context.Background(), &pb.RestoreRevisionRequest{
+		PostId: testPostID, Number: 2, UserId: testUserID, Version: 3,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("RestoreRevision() error = %v, want FailedPrecondition", err)
+	}
+	if current, ok := common.ConflictVersion(err); !ok || current != 5 {
+		t.Errorf("ConflictVersion() = %d, %v, want 5, true", current, ok)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchPostsArguments(t *testing.T) {
+	svc, mock := newTestService(t)
+	const query, viewer = "go generics", "viewer-1"
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM users WHERE LOWER(username) = LOWER($1)")).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testUserID))
+	// Authors blocked by or blocking the viewer are left out
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM (")+".*"+
+		regexp.QuoteMeta("b.blocker_id::text = $4 AND b.blocked_id = p.author_id")).
+		WithArgs(query, "go", testUserID, viewer).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT $5 OFFSET $6")).
+		WithArgs(query, "go", testUserID, viewer, 5, 5, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "subtitle", "excerpt", "word_count", "reading_time",
+			"author_id", "name", "avatar_url", "created_at", "published_at", "cover_image", "tags", "rank",
+			"title_highlight", "snippet"}).
+			AddRow(testPostID, "Go generics", "", "", 800, 4, testUserID, "Alice", nil, time.Now(), time.Now(), "",
+				"{go}", 0.5, "\x02Go\x03 <generics>", "… \x02generics\x03 …"))
+	// Each facet is counted without its own filter
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT t.name, t.name, COUNT(*) FROM m")).
+		WithArgs(query, "", testUserID, viewer, facetLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "label", "count"}).AddRow("go", "go", 12).AddRow("rust", "rust", 2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT u.id, u.name, COUNT(*) FROM m")).
+		WithArgs(query, "go", "", viewer, facetLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "label", "count"}).AddRow(testUserID, "Alice", 12))
+
+	resp, err := svc.SearchPosts(context.Background(), &pb.SearchPostsRequest{
+		Query: "  " + query + " ", Tag: "go", Author: "@alice", Page: 2, Limit: 5, CurrentUserId: viewer,
+	})
+	if err != nil {
+		t.Fatalf("SearchPosts() error = %v", err)
+	}
+	if resp.Total != 12 || len(resp.Tags) != 2 || len(resp.Authors) != 1 {
+		t.Errorf("SearchPosts() = total %d, %d tag facets, %d author facets, want 12, 2, 1",
+			resp.Total, len(resp.Tags), len(resp.Authors))
+	}
+	if len(resp.Hits) != 1 || resp.Hits[0].TitleHighlight != "<mark>Go</mark> &lt;generics&gt;" {
+		t.Errorf("SearchPosts() hits = %v, want one with an escaped, highlighted title", resp.Hits)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSearchPostsUnknownAuthor(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	if _, err := svc.SearchPosts(context.Background(), &pb.SearchPostsRequest{Query: " "}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SearchPosts() without query error = %v, want InvalidArgument", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM users WHERE LOWER(username) = LOWER($1)")).
+		WithArgs("nobody").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM user_handle_history h")).
+		WithArgs("nobody", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	_, err := svc.SearchPosts(context.Background(), &pb.SearchPostsRequest{Query: "go", Author: "@nobody"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("SearchPosts() error = %v, want NotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestNotBlocked(t *testing.T) {
+	cond := notBlocked("u.id", "$3")
+	for _, want := range []string{
+		"$3 = '' OR NOT EXISTS",
+		"b.blocker_id::text = $3 AND b.blocked_id = u.id",
+		"b.blocker_id = u.id AND b.blocked_id::text = $3",
+	} {
+		if !strings.Contains(cond, want) {
+			t.Errorf("notBlocked() = %s, want it to contain %q", cond, want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	svc, mock := newTestService(t)
+	const viewer = "viewer-1"
+
+	if _, err := svc.Search(context.Background(), &pb.SearchRequest{Query: "go", Types: []string{"comment"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Search() with an unknown type error = %v, want InvalidArgument", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM users u")+".*"+
+		regexp.QuoteMeta("b.blocker_id::text = $3 AND b.blocked_id = u.id")).
+		WithArgs("al_ice", `%al\_ice%`, viewer, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "username", "avatar_url", "bio", "score"}).
+			AddRow(testUserID, "Alice", "al_ice", "", "", 0.8))
+	mock.ExpectQuery(regexp.QuoteMeta("WITH m AS (")).
+		WithArgs("@al_ice", "", "", viewer, 3, 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	resp, err := svc.Search(context.Background(), &pb.SearchRequest{
+		Query: " @al_ice ", Types: []string{"user", "post"}, Limit: 3, CurrentUserId: viewer,
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Type != resultUser || resp.Results[0].User.Username != "al_ice" {
+		t.Errorf("Search() = %v, want the one user", resp.Results)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestSuggestSearch(t *testing.T) {
+	svc, mock := newTestService(t)
+	const viewer = "viewer-1"
+
+	resp, err := svc.SuggestSearch(context.Background(), &pb.SuggestSearchRequest{Prefix: " @ "})
+	if err != nil || len(resp.Suggestions) != 0 {
+		t.Errorf("SuggestSearch() of an empty prefix = %v, %v, want no suggestions", resp, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM users u")+".*"+
+		regexp.QuoteMeta("b.blocker_id::text = $4 AND b.blocked_id = u.id")).
+		WithArgs(`go%`, `% go%`, "go", viewer, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "value", "label", "username", "avatar_url"}).
+			AddRow(resultUser, testUserID, "Gopher", "gopher", "").
+			AddRow(resultTag, "golang", "golang", "", ""))
+
+	resp, err = svc.SuggestSearch(context.Background(), &pb.SuggestSearchRequest{Prefix: "@go", CurrentUserId: viewer})
+	if err != nil {
+		t.Fatalf("SuggestSearch() error = %v", err)
+	}
+	if len(resp.Suggestions) != 2 || resp.Suggestions[1].Value != "golang" {
+		t.Errorf("SuggestSearch() = %v, want a user and a tag", resp.Suggestions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyUserEventRejectsMalformed(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	for _, ev := range []*pb.UserEvent{
+		nil,
+		{Id: 1, Type: common.EventUserCreated},
+		{UserId: testUserID, Type: common.EventUserCreated},
+		{Id: 1, UserId: testUserID, Type: "user.renamed"},
+	} {
+		_, err := svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{Event: ev})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ApplyUserEvent(%v) error = %v, want InvalidArgument", ev, err)
+		}
+	}
+}
+
+// expectUserUpsert expects upsertUser to apply ev, with name, to a user
+// whose current handle is previous
+func expectUserUpsert(mock sqlmock.Sqlmock, ev *pb.UserEvent, name, previous string) *sqlmock.ExpectedExec {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT username FROM users WHERE id = $1 FOR UPDATE")).
+		WithArgs(ev.UserId).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow(previous))
+	return mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users")+".*"+
+		regexp.QuoteMeta("WHERE users.last_event_id IS NULL OR users.last_event_id < EXCLUDED.last_event_id")).
+		WithArgs(ev.UserId, ev.Email, name, ev.Bio, ev.AvatarUrl, ev.Id, ev.Username, ev.Version)
+}
+
+func TestApplyUserEventUpsertsUser(t *testing.T) {
+	svc, mock := newTestService(t)
+	ev := &pb.UserEvent{Id: 7, Type: common.EventUserUpdated, UserId: testUserID,
+		Email: "alice@example.com", Username: "alice_c", Version: 3}
+
+	// Without a display name the user goes by the email's local part, and
+	// the handle they gave up is remembered for redirects
+	expectUserUpsert(mock, ev, "alice", "alice").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_handle_history (handle, user_id)")).
+		WithArgs("alice", testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{Event: ev})
+	if err != nil || !resp.Applied {
+		t.Errorf("ApplyUserEvent() = %v, %v, want applied", resp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyUserEventSkipsStaleEvent(t *testing.T) {
+	svc, mock := newTestService(t)
+	ev := &pb.UserEvent{Id: 5, Type: common.EventUserUpdated, UserId: testUserID,
+		Email: "alice@example.com", Name: "Alice", Username: "alice_old", Version: 2}
+
+	// A redelivered or older event matches no row: it is acknowledged, and
+	// the handle it carries isn't recorded as retired
+	expectUserUpsert(mock, ev, "Alice", "alice_c").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	resp, err := svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{Event: ev})
+	if err != nil || resp.Applied {
+		t.Errorf("ApplyUserEvent() = %v, %v, want acknowledged but not applied", resp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyUserEventEmailTaken(t *testing.T) {
+	svc, mock := newTestService(t)
+	ev := &pb.UserEvent{Id: 8, Type: common.EventUserCreated, UserId: testUserID,
+		Email: "bob@example.com", Name: "Alice"}
+
+	// Retrying can't help, so the outbox parks the event
+	expectUserUpsert(mock, ev, "Alice", "").WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	_, err := svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{Event: ev})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("ApplyUserEvent() error = %v, want AlreadyExists", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyUserEventAnonymizes(t *testing.T) {
+	svc, mock := newTestService(t)
+	ev := &pb.UserEvent{Id: 9, Type: common.EventUserDeleted, UserId: testUserID}
+
+	// Published posts stay under "Deleted user"; the handle and everything
+	// personal goes, in one transaction
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("name = 'Deleted user'")+".*"+regexp.QuoteMeta("username = NULL")).
+		WithArgs(testUserID, int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"posts", "follows", "user_blocks", "tag_follows", "post_reads",
+		"bookmarks", "notifications", "user_handle_history"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE")).
+			WithArgs(testUserID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	resp, err := svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{Event: ev})
+	if err != nil || !resp.Applied {
+		t.Errorf("ApplyUserEvent() = %v, %v, want applied", resp, err)
+	}
+
+	// Redelivery finds the user already anonymized and changes nothing
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("name = 'Deleted user'")).
+		WithArgs(testUserID, int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	resp, err = svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{Event: ev})
+	if err != nil || resp.Applied {
+		t.Errorf("ApplyUserEvent() redelivered = %v, %v, want not applied", resp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestApplyUserEventCascades(t *testing.T) {
+	svc, mock := newTestService(t)
+	svc.config.DeletedUserContent = common.DeletedUserCascade
+
+	// Foreign keys take the user's posts, comments and the rest with them
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE id = $1")).
+		WithArgs(testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := svc.ApplyUserEvent(context.Background(), &pb.ApplyUserEventRequest{
+		Event: &pb.UserEvent{Id: 9, Type: common.EventUserDeleted, UserId: testUserID},
+	})
+	if err != nil || !resp.Applied {
+		t.Errorf("ApplyUserEvent() = %v, %v, want applied", resp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestExportUserData(t *testing.T) {
+	svc, mock := newTestService(t)
+	created := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, email")).
+		WithArgs(testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "bio", "avatar_url", "created_at"}).
+			AddRow(testUserID, "Alice", "alice@example.com", "", "", created))
+	// A slug with a path separator falls back to the post ID
+	mock.ExpectQuery(regexp.QuoteMeta("FROM posts p") + ".*" + regexp.QuoteMeta("WHERE p.author_id = $1")).
+		WithArgs(testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "subtitle", "content", "status",
+			"created_at", "published_at", "tags"}).
+			AddRow(testPostID, "../escape", `Say "hi"`, "", "Body", postDraft, created, nil, "{go,rust}"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM comments c")).
+		WithArgs(testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "post_title", "content", "created_at"}).
+			AddRow(testPostID, []byte("Title"), []byte("Nice post"), created))
+	for _, from := range []string{"FROM interactions i", "FROM follows f", "FROM follows f", "FROM tag_follows tf",
+		"FROM post_reads r", "FROM user_blocks b", "FROM bookmarks b", "FROM notifications n"} {
+		mock.ExpectQuery(regexp.QuoteMeta(from)).
+			WithArgs(testUserID).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id"}))
+	}
+
+	resp, err := svc.ExportUserData(context.Background(), &pb.ExportUserDataRequest{UserId: testUserID})
+	if err != nil {
+		t.Fatalf("ExportUserData() error = %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range resp.Files {
+		files[f.Name] = string(f.Content)
+	}
+	if len(files) != 11 {
+		t.Errorf("ExportUserData() files = %d, want 11", len(files))
+	}
+	post := files["posts/"+testPostID+".md"]
+	for _, want := range []string{`title: "Say \"hi\""`, "status: draft", `tags: ["go", "rust"]`, "---\n\nBody\n"} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post export = %q, want it to contain %q", post, want)
+		}
+	}
+	// Text columns come back as bytes, which must not end up base64 encoded
+	if !strings.Contains(files["comments.json"], `"content": "Nice post"`) {
+		t.Errorf("comments.json = %s", files["comments.json"])
+	}
+	if files["bookmarks.json"] != "[]" {
+		t.Errorf("bookmarks.json = %s, want []", files["bookmarks.json"])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestExportUserDataNotProjected(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, email")).
+		WithArgs(testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "bio", "avatar_url", "created_at"}))
+
+	resp, err := svc.ExportUserData(context.Background(), &pb.ExportUserDataRequest{UserId: testUserID})
+	if err != nil || len(resp.Files) != 0 {
+		t.Errorf("ExportUserData() = %v, %v, want no files", resp, err)
+	}
+}
+
+func TestToggleBlock(t *testing.T) {
+	svc, mock := newTestService(t)
+	ctx := context.Background()
+	const other = "33333333-3333-3333-3333-333333333333"
+
+	if _, err := svc.ToggleBlock(ctx, &pb.ToggleBlockRequest{BlockerId: testUserID, BlockedId: testUserID}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ToggleBlock() of oneself error = %v, want InvalidArgument", err)
+	}
+
+	// Blocking also ends follows in both directions
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user_blocks")).
+		WithArgs(testUserID, other).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_blocks")).
+		WithArgs(testUserID, other).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM follows")).
+		WithArgs(testUserID, other).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	resp, err := svc.ToggleBlock(ctx, &pb.ToggleBlockRequest{BlockerId: testUserID, BlockedId: other})
+	if err != nil || !resp.Blocked {
+		t.Fatalf("ToggleBlock() = %v, %v, want blocked", resp, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user_blocks")).
+		WithArgs(testUserID, other).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	resp, err = svc.ToggleBlock(ctx, &pb.ToggleBlockRequest{BlockerId: testUserID, BlockedId: other})
+	if err != nil || resp.Blocked {
+		t.Fatalf("ToggleBlock() again = %v, %v, want unblocked", resp, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user_blocks")).
+		WithArgs(testUserID, other).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_blocks")).
+		WithArgs(testUserID, other).
+		WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectRollback()
+	if _, err := svc.ToggleBlock(ctx, &pb.ToggleBlockRequest{BlockerId: testUserID, BlockedId: other}); status.Code(err) != codes.NotFound {
+		t.Errorf("ToggleBlock() of an unknown user error = %v, want NotFound", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestDecodeFeedCursor(t *testing.T) {
+	want := feedCursor{Popular: true, Score: 12.75, PublishedAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC), ID: testPostID}
+	got, err := decodeFeedCursor(want.encode())
+	if err != nil {
+		t.Fatalf("decodeFeedCursor() error = %v", err)
+	}
+	if got.Popular != want.Popular || got.Score != want.Score || !got.PublishedAt.Equal(want.PublishedAt) || got.ID != want.ID {
+		t.Errorf("decodeFeedCursor() = %+v, want %+v", got, want)
+	}
+
+	if got, err := decodeFeedCursor(""); err != nil || got != (feedCursor{}) {
+		t.Errorf("decodeFeedCursor(\"\") = %+v, %v, want the first page", got, err)
+	}
+	for _, bad := range []string{"not base64!", "bm90IGpzb24", feedCursor{ID: "1; DROP TABLE posts"}.encode()} {
+		if _, err := decodeFeedCursor(bad); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("decodeFeedCursor(%q) error = %v, want InvalidArgument", bad, err)
+		}
+	}
+}
+
+// expectFollows expects the check for whether the reader follows anyone
+func expectFollows(mock sqlmock.Sqlmock, follows bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1)")).
+		WithArgs(testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(follows))
+}
+
+func TestGetHomeFeedWithoutFollows(t *testing.T) {
+	svc, mock := newTestService(t)
+
+	// Without follows the followed part isn't queried at all
+	expectFollows(mock, false)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trending_scores ts")).
+		WithArgs(testUserID, popularWindow, nil, nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "published_at", "reason", "score"}))
+
+	page, err := svc.GetHomeFeed(context.Background(), &pb.GetHomeFeedRequest{UserId: testUserID, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetHomeFeed() error = %v", err)
+	}
+	if len(page.Items) != 0 || page.NextCursor != "" {
+		t.Errorf("GetHomeFeed() = %v, next %q, want an empty feed", page.Items, page.NextCursor)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetHomeFeedPaging(t *testing.T) {
+	svc, mock := newTestService(t)
+	ctx := context.Background()
+	ids := []string{
+		"00000000-0000-0000-0000-000000000001",
+		"00000000-0000-0000-0000-000000000002",
+		"00000000-0000-0000-0000-000000000003",
+		"00000000-0000-0000-0000-000000000004",
+	}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	entryColumns := []string{"id", "published_at", "reason", "score"}
+	summaries := func(ids ...string) *sqlmock.Rows {
+		rows := sqlmock.NewRows(summaryColumns)
+		for _, id := range ids {
+			rows.AddRow(id, "Title", "", "", 100, 1, testUserID, "Alice", nil, day(1), day(1), "", postPublished, "{}", 0, false)
+		}
+		return rows
+	}
+	// Neither part shows posts by users the reader blocked or who blocked them
+	blocked := regexp.QuoteMeta("b.blocker_id::text = $1::text AND b.blocked_id = p.author_id")
+
+	// First page: followed posts fill it, with one more left. The query
+	// starts from the reader's follows and pages in SQL.
+	expectFollows(mock, true)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM follows f JOIN posts p ON p.author_id = f.followee_id")+".*"+
+		regexp.QuoteMeta("FROM tag_follows tf")+".*"+blocked+".*"+regexp.QuoteMeta("LIMIT $4")).
+		WithArgs(testUserID, nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows(entryColumns).
+			AddRow(ids[0], day(9), "following", 0.0).
+			AddRow(ids[1], day(8), "tag", 0.0).
+			AddRow(ids[2], day(7), "following", 0.0))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE p.id = ANY($1::uuid[])")).
+		WithArgs(sqlmock.AnyArg(), testUserID).
+		WillReturnRows(summaries(ids[0], ids[1]))
+
+	page, err := svc.GetHomeFeed(ctx, &pb.GetHomeFeedRequest{UserId: testUserID, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetHomeFeed() error = %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Post.Id != ids[0] || page.Items[1].Reason != "tag" {
+		t.Fatalf("GetHomeFeed() first page = %v", page.Items)
+	}
+
+	// Second page: the last followed post, then the top trending one
+	expectFollows(mock, true)
+	mock.ExpectQuery(regexp.QuoteMeta("THEN 'following' ELSE 'tag' END")).
+		WithArgs(testUserID, sameTime(day(8)), ids[1], 3).
+		WillReturnRows(sqlmock.NewRows(entryColumns).AddRow(ids[2], day(7), "following", 0.0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trending_scores ts")+".*"+blocked).
+		WithArgs(testUserID, popularWindow, nil, nil, nil, 2).
+		WillReturnRows(sqlmock.NewRows(entryColumns).
+			AddRow(ids[3], day(2), "popular", 40.5).
+			AddRow(ids[0], day(9), "popular", 12.0))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE p.id = ANY($1::uuid[])")).
+		WithArgs(sqlmock.AnyArg(), testUserID).
+		WillReturnRows(summaries(ids[2], ids[3]))
+
+	page, err = svc.GetHomeFeed(ctx, &pb.GetHomeFeedRequest{UserId: testUserID, Cursor: page.NextCursor, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetHomeFeed() second page error = %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Post.Id != ids[2] || page.Items[1].Reason != "popular" {
+		t.Fatalf("GetHomeFeed() second page = %v", page.Items)
+	}
+
+	// Third page: popular posts continue after the last score, not at an
+	// offset, so posts read in the meantime don't shift it
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trending_scores ts")).
+		WithArgs(testUserID, popularWindow, 40.5, sameTime(day(2)), ids[3], 3).
+		WillReturnRows(sqlmock.NewRows(entryColumns).AddRow(ids[0], day(9), "popular", 12.0))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE p.id = ANY($1::uuid[])")).
+		WithArgs(sqlmock.AnyArg(), testUserID).
+		WillReturnRows(summaries(ids[0]))
+
+	page, err = svc.GetHomeFeed(ctx, &pb.GetHomeFeedRequest{UserId: testUserID, Cursor: page.NextCursor, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetHomeFeed() third page error = %v", err)
+	}
+	if len(page.Items) != 1 || page.NextCursor != "" {
+		t.Errorf("GetHomeFeed() last page = %v, next %q, want one item and no next cursor", page.Items, page.NextCursor)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// testTrendingWindows differ from the defaults, so tests show the
+// configured ones are used
+var testTrendingWindows = []common.TrendingWindow{
+	{Name: "day", Period: 12 * time.Hour, HalfLife: 3 * time.Hour},
+	{Name: "week", Period: 7 * 24 * time.Hour, HalfLife: 48 * time.Hour},
+}
+
+func TestComputeTrending(t *testing.T) {
+	svc, mock := newTestService(t)
+	svc.config.TrendingWindows = testTrendingWindows
+
+	// Another replica holds the lock
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_xact_lock")).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	mock.ExpectRollback()
+	svc.computeTrending()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_xact_lock")).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM trending_scores")).
+		WillReturnResult(sqlmock.NewResult(0, 30))
+	for _, w := range testTrendingWindows {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO trending_scores")).
+			WithArgs(w.Name, w.Period.Seconds(), w.HalfLife.Seconds(),
+				clapWeight, commentWeight, bookmarkWeight, readWeight, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 10))
+	}
+	mock.ExpectCommit()
+	svc.computeTrending()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestListTrending(t *testing.T) {
+	svc, mock := newTestService(t)
+	svc.config.TrendingWindows = testTrendingWindows
+	const viewer = "viewer-1"
+	computedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, window := range []string{"year", "month"} {
+		if _, err := svc.ListTrending(context.Background(), &pb.ListTrendingRequest{Window: window}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListTrending() of unconfigured window %q error = %v, want InvalidArgument", window, err)
+		}
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trending_scores ts")+".*"+
+		regexp.QuoteMeta("b.blocker_id::text = $3 AND b.blocked_id = p.author_id")).
+		WithArgs(defaultTrendingWindow, "go", viewer, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "score", "computed_at"}).
+			AddRow(testPostID, 12.5, computedAt))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE p.id = ANY($1::uuid[])")).
+		WithArgs(sqlmock.AnyArg(), viewer).
+		WillReturnRows(sqlmock.NewRows(summaryColumns).
+			AddRow(testPostID, "Title", "", "", 100, 1, testUserID, "Alice", nil, computedAt, computedAt, "", postPublished, "{go}", 3, false))
+
+	resp, err := svc.ListTrending(context.Background(), &pb.ListTrendingRequest{Tag: "go", CurrentUserId: viewer})
+	if err != nil {
+		t.Fatalf("ListTrending() error = %v", err)
+	}
+	if len(resp.Posts) != 1 || resp.Posts[0].Score != 12.5 || resp.ComputedAt != "2026-03-01T12:00:00Z" {
+		t.Errorf("ListTrending() = %v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
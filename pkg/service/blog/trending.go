@@ -0,0 +1,176 @@
+package blog
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	pb "project/pkg/proto/blog"
+)
+
+// Weights of engagement in trending scores. Commenting on or saving a post
+// says more about it than a clap; opening it says less.
+const (
+	clapWeight     = 1.0
+	commentWeight  = 3.0
+	bookmarkWeight = 2.0
+	readWeight     = 0.5
+)
+
+const defaultTrendingWindow = "week"
+
+// trendingScoresQuery scores published posts for one window as of $8.
+// Authors engaging with their own posts don't count.
+const trendingScoresQuery = `
+	INSERT INTO trending_scores (period, post_id, score, computed_at)
+	SELECT $1, e.post_id, SUM(e.weight * POWER(0.5, EXTRACT(EPOCH FROM $8::timestamptz - e.at) / $3::float8)), $8
+	FROM (
+		SELECT post_id, user_id, created_at AS at, count * $4::float8 AS weight
+		FROM interactions WHERE type = 'clap'
+		UNION ALL
+		SELECT post_id, user_id, created_at, $5::float8 FROM comments
+		UNION ALL
+		SELECT post_id, user_id, created_at, $6::float8 FROM bookmarks
+		UNION ALL
+		SELECT post_id, user_id, read_at, $7::float8 FROM post_reads
+	) e
+	JOIN posts p ON p.id = e.post_id
+	WHERE e.at > $8::timestamptz - $2::float8 * INTERVAL '1 second'
+	  AND p.status = 'published' AND e.user_id <> p.author_id
+	GROUP BY e.post_id
+`
+
+// ListTrending returns the highest scoring posts of a window, optionally
+// with a tag. Scores are read from the rankings refreshTrending keeps, not
+// computed per request.
+func (s *Service) ListTrending(ctx context.Context, req *pb.ListTrendingRequest) (*pb.ListTrendingResponse, error) {
+	window := req.Window
+	if window == "" {
+		window = defaultTrendingWindow
+	}
+	if !slices.ContainsFunc(s.config.TrendingWindows, func(w common.TrendingWindow) bool { return w.Name == window }) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown window %q, use day, week or month", window)
+	}
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ts.post_id, ts.score, ts.computed_at
+		FROM trending_scores ts
+		JOIN posts p ON p.id = ts.post_id
+		WHERE ts.period = $1 AND p.status = 'published'
+		  AND ($2 = '' OR EXISTS (
+		      SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+		      WHERE pt.post_id = ts.post_id AND t.name = $2
+		  ))
+		  AND `+notBlocked("p.author_id", "$3")+`
+		ORDER BY ts.score DESC, ts.post_id
+		LIMIT $4
+	`, window, req.Tag, req.CurrentUserId, limit)
+	if err != nil {
+		s.logger.Error("failed to query trending posts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer rows.Close()
+
+	type ranked struct {
+		id    string
+		score float64
+	}
+	var ranking []ranked
+	var computedAt time.Time
+	for rows.Next() {
+		var r ranked
+		if err := rows.Scan(&r.id, &r.score, &computedAt); err != nil {
+			s.logger.Error("failed to scan trending post", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		ranking = append(ranking, r)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error("failed to read trending posts", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	ids := make([]string, len(ranking))
+	for i, r := range ranking {
+		ids[i] = r.id
+	}
+	posts, err := s.postSummaries(ctx, ids, req.CurrentUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListTrendingResponse{Posts: []*pb.TrendingPost{}}
+	for _, r := range ranking {
+		if post, ok := posts[r.id]; ok {
+			resp.Posts = append(resp.Posts, &pb.TrendingPost{Post: post, Score: r.score})
+		}
+	}
+	if len(ranking) > 0 {
+		resp.ComputedAt = computedAt.Format(time.RFC3339)
+	}
+	return resp, nil
+}
+
+// refreshTrending recomputes the trending rankings now and then every
+// TrendingInterval
+func (s *Service) refreshTrending() {
+	s.computeTrending()
+
+	ticker := time.NewTicker(s.config.TrendingInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.computeTrending()
+	}
+}
+
+// computeTrending replaces the rankings of every window in one transaction,
+// so readers see either the old rankings or the new ones. Every replica
+// runs it; one that finds the advisory lock taken leaves the work to the
+// replica holding it.
+func (s *Service) computeTrending() {
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock(hashtext('trending_scores'))").Scan(&locked)
+	if err != nil {
+		s.logger.Error("failed to lock trending rankings", zap.Error(err))
+		return
+	}
+	if !locked {
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM trending_scores"); err != nil {
+		s.logger.Error("failed to clear trending rankings", zap.Error(err))
+		return
+	}
+	now := time.Now()
+	for _, w := range s.config.TrendingWindows {
+		_, err := tx.ExecContext(ctx, trendingScoresQuery, w.Name, w.Period.Seconds(), w.HalfLife.Seconds(),
+			clapWeight, commentWeight, bookmarkWeight, readWeight, now)
+		if err != nil {
+			s.logger.Error("failed to compute trending rankings", zap.String("window", w.Name), zap.Error(err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit trending rankings", zap.Error(err))
+	}
+}
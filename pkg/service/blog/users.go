@@ -0,0 +1,274 @@
+package blog
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	pb "project/pkg/proto/blog"
+)
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ApplyUserEvent updates the users projection from an auth service event.
+// Delivery is at-least-once, so events at or below the row's last_event_id
+// are acknowledged without being applied again.
+func (s *Service) ApplyUserEvent(ctx context.Context, req *pb.ApplyUserEventRequest) (*pb.ApplyUserEventResponse, error) {
+	ev := req.Event
+	if ev == nil || ev.UserId == "" || ev.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "event with id and user_id is required")
+	}
+
+	switch ev.Type {
+	case common.EventUserCreated, common.EventUserUpdated:
+		return s.upsertUser(ctx, ev)
+	case common.EventUserDeleted:
+		if s.config.DeletedUserContent == common.DeletedUserCascade {
+			return s.deleteUser(ctx, ev)
+		}
+		return s.anonymizeUser(ctx, ev)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown event type %q", ev.Type)
+	}
+}
+
+func (s *Service) upsertUser(ctx context.Context, ev *pb.UserEvent) (*pb.ApplyUserEventResponse, error) {
+	// Accounts can be created without a display name
+	name := ev.Name
+	if name == "" {
+		name, _, _ = strings.Cut(ev.Email, "@")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	var previous sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT username FROM users WHERE id = $1 FOR UPDATE", ev.UserId).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		s.logger.Error("failed to look up user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// Events carry the whole profile, so empty fields were cleared in the
+	// auth service and are cleared here too
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO users (id, email, name, bio, avatar_url, username, version, last_event_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($7, ''), GREATEST($8, 1), $6, NOW(), NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			email = EXCLUDED.email,
+			name = EXCLUDED.name,
+			bio = EXCLUDED.bio,
+			avatar_url = EXCLUDED.avatar_url,
+			username = COALESCE(EXCLUDED.username, users.username),
+			version = CASE WHEN $8 = 0 THEN users.version ELSE EXCLUDED.version END,
+			last_event_id = EXCLUDED.last_event_id,
+			updated_at = NOW()
+		WHERE users.last_event_id IS NULL OR users.last_event_id < EXCLUDED.last_event_id
+	`, ev.UserId, ev.Email, name, ev.Bio, ev.AvatarUrl, ev.Id, ev.Username, ev.Version)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			// Another row already holds this email; retrying won't help
+			s.logger.Error("user event conflicts with existing email",
+				zap.String("user_id", ev.UserId), zap.Int64("event_id", ev.Id))
+			return nil, status.Error(codes.AlreadyExists, "email belongs to another user")
+		}
+		s.logger.Error("failed to apply user event", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	n, _ := res.RowsAffected()
+
+	// Remember the old handle so links to it keep working for a while
+	if n > 0 && previous.String != "" && ev.Username != "" && !strings.EqualFold(previous.String, ev.Username) {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO user_handle_history (handle, user_id) VALUES ($1, $2)",
+			previous.String, ev.UserId,
+		)
+		if err != nil {
+			s.logger.Error("failed to record retired handle", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit user event", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if n > 0 {
+		s.logger.Info("user projected", zap.String("user_id", ev.UserId), zap.String("type", ev.Type))
+	}
+	return &pb.ApplyUserEventResponse{Applied: n > 0}, nil
+}
+
+// resolveUserRef turns a user ID or handle, with or without a leading @,
+// into a user ID. moved reports that ref is a handle the user has since
+// given up, which still resolves for common.UsernameRedirectPeriod.
+func (s *Service) resolveUserRef(ctx context.Context, ref string) (userID string, moved bool, err error) {
+	if uuidRegex.MatchString(ref) {
+		return ref, false, nil
+	}
+
+	handle := strings.TrimPrefix(ref, "@")
+	err = s.db.QueryRowContext(ctx,
+		"SELECT id FROM users WHERE LOWER(username) = LOWER($1) AND deleted_at IS NULL",
+		handle,
+	).Scan(&userID)
+	if err != sql.ErrNoRows {
+		return userID, false, err
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT h.user_id FROM user_handle_history h
+		JOIN users u ON u.id = h.user_id
+		WHERE LOWER(h.handle) = LOWER($1) AND h.retired_at > $2 AND u.deleted_at IS NULL
+		ORDER BY h.retired_at DESC
+		LIMIT 1
+	`, handle, time.Now().Add(-common.UsernameRedirectPeriod)).Scan(&userID)
+	if err != nil {
+		return "", false, err
+	}
+	return userID, true, nil
+}
+
+// deleteUser removes the user and, through ON DELETE CASCADE, everything
+// they wrote or did
+func (s *Service) deleteUser(ctx context.Context, ev *pb.UserEvent) (*pb.ApplyUserEventResponse, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE id = $1", ev.UserId)
+	if err != nil {
+		s.logger.Error("failed to delete user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	n, _ := res.RowsAffected()
+	s.logger.Info("user deleted", zap.String("user_id", ev.UserId))
+	return &pb.ApplyUserEventResponse{Applied: n > 0}, nil
+}
+
+// anonymizeUser keeps the user's published posts, comments and claps so
+// threads stay readable, but strips the profile and removes everything
+// personal: handles, drafts, follows, blocks, reads, bookmarks and
+// notifications
+func (s *Service) anonymizeUser(ctx context.Context, ev *pb.UserEvent) (*pb.ApplyUserEventResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	// The email must stay unique, and .invalid can never be delivered to.
+	// The handle is freed, so its old links stop resolving to the user.
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET
+			name = 'Deleted user',
+			email = 'deleted-' || id || '@users.invalid',
+			username = NULL,
+			bio = NULL,
+			avatar_url = NULL,
+			deleted_at = NOW(),
+			last_event_id = $2,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND (last_event_id IS NULL OR last_event_id < $2)
+	`, ev.UserId, ev.Id)
+	if err != nil {
+		s.logger.Error("failed to anonymize user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return &pb.ApplyUserEventResponse{Applied: false}, nil
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM posts WHERE author_id = $1 AND status <> 'published'",
+		"DELETE FROM follows WHERE follower_id = $1 OR followee_id = $1",
+		"DELETE FROM user_blocks WHERE blocker_id = $1 OR blocked_id = $1",
+		"DELETE FROM tag_follows WHERE user_id = $1",
+		"DELETE FROM post_reads WHERE user_id = $1",
+		"DELETE FROM bookmarks WHERE user_id = $1",
+		"DELETE FROM notifications WHERE user_id = $1 OR actor_id = $1",
+		"DELETE FROM user_handle_history WHERE user_id = $1",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, ev.UserId); err != nil {
+			s.logger.Error("failed to remove personal data", zap.String("statement", stmt), zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit anonymization", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("user anonymized", zap.String("user_id", ev.UserId))
+	return &pb.ApplyUserEventResponse{Applied: true}, nil
+}
+
+// ToggleBlock blocks or unblocks a user. Blocking ends any follow between
+// the two, in both directions.
+func (s *Service) ToggleBlock(ctx context.Context, req *pb.ToggleBlockRequest) (*pb.ToggleBlockResponse, error) {
+	if req.BlockerId == req.BlockedId {
+		return nil, status.Error(codes.InvalidArgument, "you can't block yourself")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Error("failed to begin transaction", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2",
+		req.BlockerId, req.BlockedId,
+	)
+	if err != nil {
+		s.logger.Error("failed to remove block", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	n, _ := res.RowsAffected()
+	blocked := n == 0
+
+	if blocked {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)",
+			req.BlockerId, req.BlockedId,
+		)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		if err != nil {
+			s.logger.Error("failed to add block", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM follows
+			WHERE (follower_id = $1 AND followee_id = $2) OR (follower_id = $2 AND followee_id = $1)
+		`, req.BlockerId, req.BlockedId)
+		if err != nil {
+			s.logger.Error("failed to remove follows", zap.Error(err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to commit block", zap.Error(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.logger.Info("block toggled",
+		zap.String("blocker_id", req.BlockerId), zap.String("blocked_id", req.BlockedId), zap.Bool("blocked", blocked))
+	return &pb.ToggleBlockResponse{Blocked: blocked}, nil
+}
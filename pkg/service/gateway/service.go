@@ -1,24 +1,37 @@
 package gateway
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"project/pkg/common"
 	"project/pkg/middleware"
 	authpb "project/pkg/proto/auth"
 	blogpb "project/pkg/proto/blog"
+	"project/pkg/tokens"
 	"project/pkg/validation"
 	"project/pkg/ws"
 )
@@ -29,6 +42,7 @@ type Service struct {
 	router     *gin.Engine
 	authClient authpb.AuthServiceClient
 	blogClient blogpb.BlogServiceClient
+	verifier   *middleware.TokenVerifier
 	wsHub      *ws.Hub
 }
 
@@ -55,6 +69,14 @@ func NewService() *Service {
 	}
 	blogClient := blogpb.NewBlogServiceClient(blogConn)
 
+	// Verify tokens locally, syncing keys and revocations from auth
+	pollInterval, err := time.ParseDuration(getEnv("REVOCATION_POLL_INTERVAL", "5s"))
+	if err != nil || pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	verifier := middleware.NewTokenVerifier(authClient, logger, pollInterval)
+	go verifier.Start(context.Background())
+
 	// Initialize WebSocket hub
 	wsHub := ws.NewHub(logger)
 	go wsHub.Run()
@@ -64,6 +86,7 @@ func NewService() *Service {
 		logger:     logger,
 		authClient: authClient,
 		blogClient: blogClient,
+		verifier:   verifier,
 		wsHub:      wsHub,
 	}
 }
@@ -90,12 +113,19 @@ func (s *Service) SetupRouter() {
 	// Health check
 	r.GET("/health", s.healthCheck)
 
+	// Public signing keys so other services can verify tokens offline
+	r.GET("/.well-known/jwks.json", s.jwks)
+
 	// WebSocket endpoint for real-time notifications
 	r.GET("/ws", s.handleWebSocket)
 
 	// Auth middleware for protected routes
-	authMiddleware := middleware.AuthMiddleware(s.authClient)
-	optionalAuthMiddleware := middleware.OptionalAuthMiddleware(s.authClient)
+	authMiddleware := middleware.AuthMiddleware(s.verifier)
+	optionalAuthMiddleware := middleware.OptionalAuthMiddleware(s.verifier)
+
+	// Personal access tokens are limited to their scopes; account security
+	// settings need a real login
+	sessionOnly := middleware.SessionOnly()
 
 	// Rate limiters
 	authRateLimiter := middleware.NewRateLimiter(10, time.Minute) // 10 requests per minute for auth
@@ -111,40 +141,134 @@ func (s *Service) SetupRouter() {
 		{
 			auth.POST("/login", s.login)
 			auth.POST("/register", s.register)
+			auth.POST("/refresh", s.refreshToken)
+			auth.POST("/logout", s.logout)
+			auth.POST("/password/forgot", s.requestPasswordReset)
+			auth.POST("/password/reset", s.resetPassword)
+			auth.POST("/email/verify", s.verifyEmail)
+			auth.POST("/email/verification", authMiddleware, sessionOnly, s.sendVerificationEmail)
+			auth.POST("/mfa/verify", s.verifyMFA)
+			auth.POST("/unlock", s.unlockAccount)
+			auth.POST("/magic-link", s.requestMagicLink)
+			auth.POST("/magic-link/consume", s.consumeMagicLink)
+			auth.POST("/mfa/totp/enroll", authMiddleware, sessionOnly, s.enrollTOTP)
+			auth.POST("/mfa/totp/confirm", authMiddleware, sessionOnly, s.confirmTOTP)
+			auth.POST("/mfa/totp/disable", authMiddleware, sessionOnly, s.disableTOTP)
+			auth.GET("/oauth/:provider/start", s.startOAuth)
+			auth.GET("/oauth/:provider/callback", s.completeOAuth)
 		}
 
 		// Posts routes - GET is public, POST requires auth
 		posts := api.Group("/posts")
+		posts.Use(middleware.RequireScopes(tokens.ScopeRead, tokens.ScopePostsWrite))
 		{
 			posts.GET("", optionalAuthMiddleware, s.listPosts)
+			posts.GET("/drafts", authMiddleware, s.listMyDrafts)
+			posts.GET("/search", optionalAuthMiddleware, s.searchPosts)
+			posts.GET("/trending", optionalAuthMiddleware, s.listTrending)
 			posts.GET("/:id", optionalAuthMiddleware, s.getPost)
 			posts.POST("", authMiddleware, s.createPost)
 			posts.PUT("/:id", authMiddleware, s.updatePost)
+			posts.PATCH("/:id", authMiddleware, s.patchPost)
 			posts.DELETE("/:id", authMiddleware, s.deletePost)
+			posts.POST("/:id/publish", authMiddleware, s.publishPost)
+			posts.POST("/:id/unpublish", authMiddleware, s.unpublishPost)
 			posts.POST("/:id/clap", authMiddleware, s.toggleClap)
 			posts.POST("/:id/bookmark", authMiddleware, s.toggleBookmark)
 		}
 
+		// Personal access token management
+		accessTokens := api.Group("/users/me/tokens")
+		accessTokens.Use(authMiddleware, sessionOnly)
+		{
+			accessTokens.GET("", s.listAccessTokens)
+			accessTokens.POST("", s.createAccessToken)
+			accessTokens.DELETE("/:tokenId", s.revokeAccessToken)
+		}
+
+		// Signed-in devices
+		sessions := api.Group("/users/me/sessions")
+		sessions.Use(authMiddleware, sessionOnly)
+		{
+			sessions.GET("", s.listSessions)
+			sessions.DELETE("/:sessionId", s.revokeSession)
+		}
+
+		// Account deletion and data export
+		account := api.Group("/users/me")
+		account.Use(authMiddleware, sessionOnly)
+		{
+			account.DELETE("", s.deleteAccount)
+			account.GET("/export", s.exportUserData)
+		}
+
 		// User routes
 		users := api.Group("/users")
+		users.Use(middleware.RequireScopes(tokens.ScopeRead, tokens.ScopeUsersWrite))
 		{
 			users.GET("/:id", optionalAuthMiddleware, s.getUser)
 			users.POST("/:id/follow", authMiddleware, s.toggleFollow)
+			users.POST("/:id/block", authMiddleware, s.toggleBlock)
 			users.PUT("/me", authMiddleware, s.updateProfile)
+			users.PATCH("/me", authMiddleware, s.patchProfile)
+		}
+
+		// Search across users, tags and posts
+		search := api.Group("/search")
+		search.Use(middleware.RequireScope(tokens.ScopeRead), optionalAuthMiddleware)
+		{
+			search.GET("", s.search)
+			search.GET("/suggest", s.suggestSearch)
+		}
+
+		// Home feed of followed authors and tags
+		feed := api.Group("/feed")
+		feed.Use(middleware.RequireScope(tokens.ScopeRead), authMiddleware)
+		{
+			feed.GET("", s.getHomeFeed)
+		}
+
+		// Tag routes
+		tags := api.Group("/tags")
+		tags.Use(middleware.RequireScopes(tokens.ScopeRead, tokens.ScopeUsersWrite))
+		{
+			tags.POST("/:name/follow", authMiddleware, s.toggleTagFollow)
 		}
 
 		// Notifications routes
 		notifications := api.Group("/notifications")
+		notifications.Use(middleware.RequireScope(tokens.ScopeRead))
 		{
 			notifications.GET("", authMiddleware, s.listNotifications)
 			notifications.POST("/:id/read", authMiddleware, s.markNotificationRead)
 		}
 
+		// Administration
+		admin := api.Group("/admin")
+		admin.Use(authMiddleware, sessionOnly)
+		{
+			admin.PUT("/users/:id/roles", middleware.RequirePermission(tokens.PermUsersManage), s.setUserRoles)
+		}
+
+		// Revision history, visible to those who may edit the post
+		revisions := api.Group("/posts/:id/revisions")
+		revisions.Use(middleware.RequireScopes(tokens.ScopeRead, tokens.ScopePostsWrite), authMiddleware)
+		{
+			revisions.GET("", s.listRevisions)
+			revisions.GET("/diff", s.diffRevisions)
+			revisions.GET("/:number", s.getRevision)
+			revisions.POST("/:number/restore", s.restoreRevision)
+		}
+
 		// Comments routes
 		// Nested under posts for RESTful structure
-		posts.GET("/:id/comments", optionalAuthMiddleware, s.listComments)
-		posts.POST("/:id/comments", authMiddleware, s.createComment)
-		posts.DELETE("/:id/comments/:commentId", authMiddleware, s.deleteComment)
+		comments := api.Group("/posts/:id/comments")
+		comments.Use(middleware.RequireScopes(tokens.ScopeRead, tokens.ScopeCommentsWrite))
+		{
+			comments.GET("", optionalAuthMiddleware, s.listComments)
+			comments.POST("", authMiddleware, s.createComment)
+			comments.DELETE("/:commentId", authMiddleware, s.deleteComment)
+		}
 	}
 
 	s.router = r
@@ -221,216 +345,1641 @@ func (s *Service) listPosts(c *gin.Context) {
 		return
 	}
 
-	common.RespondSuccess(c, resp.Posts)
+	common.RespondSuccess(c, resp.Posts)
+}
+
+// searchPosts ranks posts against q, which takes web search syntax
+// ("phrases", or, -word), optionally narrowed by tag and author
+func (s *Service) searchPosts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	resp, err := s.blogClient.SearchPosts(context.Background(), &blogpb.SearchPostsRequest{
+		Query:         c.Query("q"),
+		Tag:           c.Query("tag"),
+		Author:        c.Query("author"),
+		Page:          int32(page),
+		Limit:         int32(limit),
+		CurrentUserId: middleware.GetUserID(c),
+	})
+	if err != nil {
+		s.logger.Error("grpc search posts failed", zap.Error(err))
+		st := status.Convert(err)
+		switch st.Code() {
+		case codes.InvalidArgument:
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", st.Message())
+		case codes.NotFound:
+			common.RespondError(c, http.StatusNotFound, "NOT_FOUND", st.Message())
+		default:
+			common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to search posts")
+		}
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"hits":  resp.Hits,
+		"total": resp.Total,
+		"facets": gin.H{
+			"tags":    resp.Tags,
+			"authors": resp.Authors,
+		},
+	})
+}
+
+// listTrending returns the top posts of the last day, week or month, as
+// chosen by window, optionally with a tag
+func (s *Service) listTrending(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	resp, err := s.blogClient.ListTrending(context.Background(), &blogpb.ListTrendingRequest{
+		Window:        c.Query("window"),
+		Tag:           c.Query("tag"),
+		Limit:         int32(limit),
+		CurrentUserId: middleware.GetUserID(c),
+	})
+	if err != nil {
+		s.logger.Error("grpc list trending failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", status.Convert(err).Message())
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list trending posts")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"posts":       resp.Posts,
+		"computed_at": resp.ComputedAt,
+	})
+}
+
+// search returns users, tags and posts matching q. type narrows it to a
+// comma-separated list of result types.
+func (s *Service) search(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	var types []string
+	if t := c.Query("type"); t != "" {
+		types = strings.Split(t, ",")
+	}
+
+	resp, err := s.blogClient.Search(context.Background(), &blogpb.SearchRequest{
+		Query:         c.Query("q"),
+		Types:         types,
+		Limit:         int32(limit),
+		CurrentUserId: middleware.GetUserID(c),
+	})
+	if err != nil {
+		s.logger.Error("grpc search failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", status.Convert(err).Message())
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to search")
+		return
+	}
+
+	common.RespondSuccess(c, resp.Results)
+}
+
+// suggestSearch completes the prefix in q to user and tag names
+func (s *Service) suggestSearch(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	resp, err := s.blogClient.SuggestSearch(context.Background(), &blogpb.SuggestSearchRequest{
+		Prefix:        c.Query("q"),
+		Limit:         int32(limit),
+		CurrentUserId: middleware.GetUserID(c),
+	})
+	if err != nil {
+		s.logger.Error("grpc suggest search failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to suggest")
+		return
+	}
+
+	common.RespondSuccess(c, resp.Suggestions)
+}
+
+// getHomeFeed returns a page of the user's feed. Pass next_cursor from the
+// previous page as cursor to get the next one.
+func (s *Service) getHomeFeed(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	resp, err := s.blogClient.GetHomeFeed(context.Background(), &blogpb.GetHomeFeedRequest{
+		UserId: middleware.GetUserID(c),
+		Cursor: c.Query("cursor"),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		s.logger.Error("grpc get home feed failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", status.Convert(err).Message())
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get feed")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"items":       resp.Items,
+		"next_cursor": resp.NextCursor,
+	})
+}
+
+func (s *Service) getPost(c *gin.Context) {
+	postID := c.Param("id")
+	currentUserId := middleware.GetUserID(c)
+
+	resp, err := s.blogClient.GetPost(context.Background(), &blogpb.GetPostRequest{
+		PostId:        postID,
+		CurrentUserId: currentUserId,
+		MarkRead:      currentUserId != "",
+	})
+	if err != nil {
+		s.logger.Error("grpc get post failed", zap.Error(err))
+		common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Post not found")
+		return
+	}
+
+	setETag(c, resp.Post.Version)
+	common.RespondSuccess(c, resp.Post)
+}
+
+func (s *Service) updatePost(c *gin.Context) {
+	postID := c.Param("id")
+	userID := middleware.GetUserID(c)
+
+	var req struct {
+		Title       string    `json:"title"`
+		Subtitle    *string   `json:"subtitle"`
+		Content     string    `json:"content"`
+		Tags        *[]string `json:"tags"`
+		CoverImage  *string   `json:"cover_image"`
+		Status      string    `json:"status"`
+		ScheduledAt string    `json:"scheduled_at"`
+		Version     int64     `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	version, ok := postVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	// The title and content are always replaced; the subtitle, tags and
+	// cover image only when sent, so clients that leave them out don't
+	// clear them
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"title", "content"}}
+	var subtitle, coverImage string
+	var tags []string
+	if req.Subtitle != nil {
+		subtitle = *req.Subtitle
+		mask.Paths = append(mask.Paths, "subtitle")
+	}
+	if req.Tags != nil {
+		tags = *req.Tags
+		mask.Paths = append(mask.Paths, "tags")
+	}
+	if req.CoverImage != nil {
+		coverImage = *req.CoverImage
+		mask.Paths = append(mask.Paths, "cover_image")
+	}
+
+	resp, err := s.blogClient.UpdatePost(context.Background(), &blogpb.UpdatePostRequest{
+		PostId:      postID,
+		UserId:      userID,
+		Title:       req.Title,
+		Subtitle:    subtitle,
+		Content:     req.Content,
+		Tags:        tags,
+		CoverImage:  coverImage,
+		Permissions: middleware.GetPermissions(c),
+		Status:      req.Status,
+		ScheduledAt: req.ScheduledAt,
+		Version:     version,
+		UpdateMask:  mask,
+	})
+	if err != nil {
+		s.logger.Error("grpc update post failed", zap.Error(err))
+		if respondVersionConflict(c, err) {
+			return
+		}
+		s.respondPostError(c, err, "Failed to update post")
+		return
+	}
+
+	setETag(c, resp.Post.Version)
+	common.RespondSuccess(c, resp.Post)
+}
+
+// patchPost changes only the fields present in the body. Unlike PUT, a
+// field sent as null or empty is cleared rather than left alone.
+func (s *Service) patchPost(c *gin.Context) {
+	postID := c.Param("id")
+	userID := middleware.GetUserID(c)
+
+	var req struct {
+		Title       string   `json:"title"`
+		Subtitle    string   `json:"subtitle"`
+		Content     string   `json:"content"`
+		Tags        []string `json:"tags"`
+		CoverImage  string   `json:"cover_image"`
+		Status      string   `json:"status"`
+		ScheduledAt string   `json:"scheduled_at"`
+		Version     int64    `json:"version"`
+	}
+	paths, ok := bindPatch(c, &req, "title", "subtitle", "content", "tags", "cover_image", "status", "scheduled_at")
+	if !ok {
+		return
+	}
+
+	version, ok := postVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	resp, err := s.blogClient.UpdatePost(context.Background(), &blogpb.UpdatePostRequest{
+		PostId:      postID,
+		UserId:      userID,
+		Title:       req.Title,
+		Subtitle:    req.Subtitle,
+		Content:     req.Content,
+		Tags:        req.Tags,
+		CoverImage:  req.CoverImage,
+		Permissions: middleware.GetPermissions(c),
+		Status:      req.Status,
+		ScheduledAt: req.ScheduledAt,
+		Version:     version,
+		UpdateMask:  &fieldmaskpb.FieldMask{Paths: paths},
+	})
+	if err != nil {
+		s.logger.Error("grpc patch post failed", zap.Error(err))
+		if respondVersionConflict(c, err) {
+			return
+		}
+		s.respondPostError(c, err, "Failed to update post")
+		return
+	}
+
+	setETag(c, resp.Post.Version)
+	common.RespondSuccess(c, resp.Post)
+}
+
+// bindPatch decodes a PATCH body into dst and returns the keys it sets, for
+// use as an update mask. The version key isn't a field and is left out. Keys
+// outside allowed are answered with 400 and false.
+func bindPatch(c *gin.Context, dst any, allowed ...string) ([]string, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+		return nil, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Request body must be a JSON object")
+		return nil, false
+	}
+	paths := []string{}
+	for key := range fields {
+		if key == "version" {
+			continue
+		}
+		if !slices.Contains(allowed, key) {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Unknown field "+strconv.Quote(key))
+			return nil, false
+		}
+		paths = append(paths, key)
+	}
+	if len(paths) == 0 {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Nothing to update")
+		return nil, false
+	}
+	slices.Sort(paths)
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return nil, false
+	}
+	return paths, true
+}
+
+// requestVersion returns the version an update is made against: the
+// If-Match header if present, else the version from the body. It is 0, for
+// an unconditional update, when neither is given or If-Match is "*", which
+// matches any current version. A malformed If-Match is answered with 400
+// and false.
+func requestVersion(c *gin.Context, bodyVersion int64) (int64, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		return bodyVersion, true
+	}
+	if ifMatch == "*" {
+		return 0, true
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "If-Match must be a single ETag from this API")
+		return 0, false
+	}
+	return version, true
+}
+
+// postVersion is requestVersion for posts, which are only updated against
+// a version. If-Match "*" would skip that check, so it is refused with 400
+// rather than taken as a missing precondition; no version at all gets 428.
+func postVersion(c *gin.Context, bodyVersion int64) (int64, bool) {
+	if strings.TrimSpace(c.GetHeader("If-Match")) == "*" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"If-Match: * is not supported for posts; send the ETag from GET")
+		return 0, false
+	}
+	version, ok := requestVersion(c, bodyVersion)
+	if !ok {
+		return 0, false
+	}
+	if version == 0 {
+		common.RespondError(c, http.StatusPreconditionRequired, "PRECONDITION_REQUIRED",
+			"Send the post's version in If-Match or the version field")
+		return 0, false
+	}
+	return version, true
+}
+
+// setETag tags the response with a post or profile version
+func setETag(c *gin.Context, version int64) {
+	if version > 0 {
+		c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+	}
+}
+
+// respondVersionConflict answers an update made against a stale version
+// with 409 and the current version, and reports whether err was one
+func respondVersionConflict(c *gin.Context, err error) bool {
+	current, ok := common.ConflictVersion(err)
+	if !ok {
+		return false
+	}
+	setETag(c, current)
+	common.RespondErrorData(c, http.StatusConflict, "VERSION_CONFLICT", status.Convert(err).Message(),
+		gin.H{"current_version": current})
+	return true
+}
+
+func (s *Service) deletePost(c *gin.Context) {
+	postID := c.Param("id")
+	userID := middleware.GetUserID(c)
+
+	_, err := s.blogClient.DeletePost(context.Background(), &blogpb.DeletePostRequest{
+		PostId:      postID,
+		UserId:      userID,
+		Permissions: middleware.GetPermissions(c),
+	})
+	if err != nil {
+		s.logger.Error("grpc delete post failed", zap.Error(err))
+		common.RespondError(c, http.StatusForbidden, "FORBIDDEN", err.Error())
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"success": true})
+}
+
+func (s *Service) createPost(c *gin.Context) {
+	var req struct {
+		Title       string   `json:"title"`
+		Subtitle    string   `json:"subtitle"`
+		Content     string   `json:"content"`
+		Tags        []string `json:"tags"`
+		CoverImage  string   `json:"cover_image"`
+		Status      string   `json:"status"`
+		ScheduledAt string   `json:"scheduled_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	// Get author ID from authenticated user context
+	authorId := middleware.GetUserID(c)
+	if authorId == "" {
+		common.RespondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+		return
+	}
+
+	resp, err := s.blogClient.CreatePost(context.Background(), &blogpb.CreatePostRequest{
+		Title:       req.Title,
+		Subtitle:    req.Subtitle,
+		Content:     req.Content,
+		AuthorId:    authorId,
+		Tags:        req.Tags,
+		CoverImage:  req.CoverImage,
+		Status:      req.Status,
+		ScheduledAt: req.ScheduledAt,
+	})
+	if err != nil {
+		s.logger.Error("grpc create post failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", status.Convert(err).Message())
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create post")
+		return
+	}
+
+	common.RespondCreated(c, resp.Post)
+}
+
+func (s *Service) listMyDrafts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	resp, err := s.blogClient.ListMyDrafts(context.Background(), &blogpb.ListMyDraftsRequest{
+		UserId: middleware.GetUserID(c),
+		Page:   int32(page),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		s.logger.Error("grpc list drafts failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch drafts")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"posts": resp.Posts,
+		"total": resp.Total,
+	})
+}
+
+func (s *Service) publishPost(c *gin.Context) {
+	// The body is optional; without scheduled_at the post goes out now. The
+	// version may come in If-Match instead.
+	var req struct {
+		ScheduledAt string `json:"scheduled_at"`
+		Version     int64  `json:"version"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	version, ok := postVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	resp, err := s.blogClient.PublishPost(context.Background(), &blogpb.PublishPostRequest{
+		PostId:      c.Param("id"),
+		UserId:      middleware.GetUserID(c),
+		ScheduledAt: req.ScheduledAt,
+		Permissions: middleware.GetPermissions(c),
+		Version:     version,
+	})
+	if err != nil {
+		s.logger.Warn("grpc publish post failed", zap.Error(err))
+		if respondVersionConflict(c, err) {
+			return
+		}
+		s.respondPostError(c, err, "Failed to publish post")
+		return
+	}
+
+	setETag(c, resp.Post.Version)
+	common.RespondSuccess(c, resp.Post)
+}
+
+func (s *Service) unpublishPost(c *gin.Context) {
+	// The version may come in If-Match instead of the body
+	var req struct {
+		Version int64 `json:"version"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	version, ok := postVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	resp, err := s.blogClient.UnpublishPost(context.Background(), &blogpb.UnpublishPostRequest{
+		PostId:      c.Param("id"),
+		UserId:      middleware.GetUserID(c),
+		Permissions: middleware.GetPermissions(c),
+		Version:     version,
+	})
+	if err != nil {
+		s.logger.Warn("grpc unpublish post failed", zap.Error(err))
+		if respondVersionConflict(c, err) {
+			return
+		}
+		s.respondPostError(c, err, "Failed to unpublish post")
+		return
+	}
+
+	setETag(c, resp.Post.Version)
+	common.RespondSuccess(c, resp.Post)
+}
+
+func (s *Service) listRevisions(c *gin.Context) {
+	resp, err := s.blogClient.ListRevisions(context.Background(), &blogpb.ListRevisionsRequest{
+		PostId:      c.Param("id"),
+		UserId:      middleware.GetUserID(c),
+		Permissions: middleware.GetPermissions(c),
+	})
+	if err != nil {
+		s.logger.Warn("grpc list revisions failed", zap.Error(err))
+		s.respondPostError(c, err, "Failed to fetch revisions")
+		return
+	}
+
+	common.RespondSuccess(c, resp.Revisions)
+}
+
+// revisionNumber parses the :number route parameter, responding with 400
+// when it isn't a revision number
+func revisionNumber(c *gin.Context) (int32, bool) {
+	n, err := strconv.ParseInt(c.Param("number"), 10, 32)
+	if err != nil || n < 1 {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid revision number")
+		return 0, false
+	}
+	return int32(n), true
+}
+
+func (s *Service) getRevision(c *gin.Context) {
+	number, ok := revisionNumber(c)
+	if !ok {
+		return
+	}
+
+	resp, err := s.blogClient.GetRevision(context.Background(), &blogpb.GetRevisionRequest{
+		PostId:      c.Param("id"),
+		Number:      number,
+		UserId:      middleware.GetUserID(c),
+		Permissions: middleware.GetPermissions(c),
+	})
+	if err != nil {
+		s.logger.Warn("grpc get revision failed", zap.Error(err))
+		s.respondPostError(c, err, "Failed to fetch revision")
+		return
+	}
+
+	common.RespondSuccess(c, resp.Revision)
+}
+
+func (s *Service) diffRevisions(c *gin.Context) {
+	// from and to default to the latest revision and the one before it
+	var from, to int
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = strconv.Atoi(v); err != nil || from < 1 {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid from revision")
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil || to < 1 {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid to revision")
+			return
+		}
+	}
+
+	resp, err := s.blogClient.DiffRevisions(context.Background(), &blogpb.DiffRevisionsRequest{
+		PostId:      c.Param("id"),
+		From:        int32(from),
+		To:          int32(to),
+		Granularity: c.Query("granularity"),
+		UserId:      middleware.GetUserID(c),
+		Permissions: middleware.GetPermissions(c),
+	})
+	if err != nil {
+		s.logger.Warn("grpc diff revisions failed", zap.Error(err))
+		s.respondPostError(c, err, "Failed to diff revisions")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"title":            resp.Title,
+		"content":          resp.Content,
+		"from_cover_image": resp.FromCoverImage,
+		"to_cover_image":   resp.ToCoverImage,
+	})
+}
+
+func (s *Service) restoreRevision(c *gin.Context) {
+	number, ok := revisionNumber(c)
+	if !ok {
+		return
+	}
+
+	// The version may come in If-Match instead of the body
+	var req struct {
+		Version int64 `json:"version"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	version, ok := postVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	resp, err := s.blogClient.RestoreRevision(context.Background(), &blogpb.RestoreRevisionRequest{
+		PostId:      c.Param("id"),
+		Number:      number,
+		UserId:      middleware.GetUserID(c),
+		Permissions: middleware.GetPermissions(c),
+		Version:     version,
+	})
+	if err != nil {
+		s.logger.Warn("grpc restore revision failed", zap.Error(err))
+		if respondVersionConflict(c, err) {
+			return
+		}
+		s.respondPostError(c, err, "Failed to restore revision")
+		return
+	}
+
+	setETag(c, resp.Post.Version)
+
+	common.RespondSuccess(c, gin.H{
+		"post":     resp.Post,
+		"revision": resp.Revision,
+	})
+}
+
+func (s *Service) respondPostError(c *gin.Context, err error, fallback string) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument:
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", st.Message())
+	case codes.FailedPrecondition:
+		common.RespondError(c, http.StatusConflict, "INVALID_STATE", st.Message())
+	case codes.PermissionDenied:
+		common.RespondError(c, http.StatusForbidden, "FORBIDDEN", st.Message())
+	case codes.NotFound:
+		common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Post not found")
+	default:
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
+
+func (s *Service) healthCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "healthy",
+		"timestamp": time.Now().UTC(),
+		"service":   "api-gateway",
+	})
+}
+
+func (s *Service) jwks(c *gin.Context) {
+	keys, err := s.verifier.PublicKeys(c.Request.Context())
+	if err != nil {
+		s.logger.Error("grpc get jwks failed", zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signing keys unavailable"})
+		return
+	}
+
+	// JWKS is a bare standard document, not wrapped in APIResponse
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+func (s *Service) login(c *gin.Context) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	// Call gRPC service
+	resp, err := s.authClient.Login(context.Background(), &authpb.LoginRequest{
+		Email:     req.Email,
+		Password:  req.Password,
+		IpAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		s.logger.Error("grpc login failed", zap.Error(err))
+		if s.respondThrottled(c, err) {
+			return
+		}
+		common.RespondError(c, http.StatusUnauthorized, "AUTH_FAILED", "Invalid credentials")
+		return
+	}
+
+	if resp.MfaRequired {
+		common.RespondSuccess(c, gin.H{
+			"mfa_required": true,
+			"mfa_token":    resp.MfaToken,
+		})
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"token":         resp.Token,
+		"refresh_token": resp.RefreshToken,
+		"expires_in":    resp.ExpiresIn,
+		"user":          resp.User,
+	})
+}
+
+// respondThrottled answers throttled (429) and locked-out (423) logins,
+// passing on the auth service's retry delay as Retry-After
+func (s *Service) respondThrottled(c *gin.Context, err error) bool {
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted && st.Code() != codes.FailedPrecondition {
+		return false
+	}
+
+	var locked bool
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.ErrorInfo); ok {
+			locked = d.Reason == "ACCOUNT_LOCKED"
+		}
+	}
+	setRetryAfter(c, st)
+
+	switch {
+	case locked:
+		common.RespondError(c, http.StatusLocked, "ACCOUNT_LOCKED",
+			"Account is temporarily locked after too many failed attempts. Check your email to unlock it.")
+	case st.Code() == codes.ResourceExhausted:
+		common.RespondError(c, http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", "Too many failed attempts, try again later")
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Service) unlockAccount(c *gin.Context) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "token is required")
+		return
+	}
+
+	_, err := s.authClient.UnlockAccount(context.Background(), &authpb.UnlockAccountRequest{
+		Token: req.Token,
+	})
+	if err != nil {
+		s.logger.Warn("grpc unlock account failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", "Unlock link is invalid or has expired")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to unlock account")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"success": true})
+}
+
+// oauthStateCookie binds a social login to the browser that started it, so
+// a callback URL from someone else's login can't sign this browser in
+const oauthStateCookie = "oauth_state"
+
+// startOAuth redirects the browser to the provider's sign-in page
+func (s *Service) startOAuth(c *gin.Context) {
+	resp, err := s.authClient.StartOAuth(context.Background(), &authpb.StartOAuthRequest{
+		Provider: c.Param("provider"),
+	})
+	if err != nil {
+		s.logger.Warn("grpc start oauth failed", zap.Error(err))
+		switch status.Code(err) {
+		case codes.NotFound:
+			common.RespondError(c, http.StatusNotFound, "PROVIDER_NOT_FOUND", "Unknown login provider")
+		case codes.Unavailable:
+			common.RespondError(c, http.StatusBadGateway, "PROVIDER_UNAVAILABLE", "Login provider is unavailable")
+		default:
+			common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to start login")
+		}
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, resp.State, 600, "/api/v1/auth/oauth", "", s.config.Environment == "production", true)
+	c.Redirect(http.StatusFound, resp.AuthorizationUrl)
+}
+
+// completeOAuth handles the provider's redirect and hands the outcome to
+// the web app in the fragment of its /oauth/callback URL, which keeps the
+// tokens out of server logs and Referer headers
+func (s *Service) completeOAuth(c *gin.Context) {
+	result := url.Values{}
+	defer func() {
+		c.Redirect(http.StatusFound, s.config.AppURL+"/oauth/callback#"+result.Encode())
+	}()
+
+	state, _ := c.Cookie(oauthStateCookie)
+	c.SetCookie(oauthStateCookie, "", -1, "/api/v1/auth/oauth", "", s.config.Environment == "production", true)
+
+	// The user declined, or the provider refused the request
+	if providerErr := c.Query("error"); providerErr != "" {
+		result.Set("error", "access_denied")
+		return
+	}
+	if state == "" || state != c.Query("state") {
+		result.Set("error", "invalid_state")
+		return
+	}
+
+	resp, err := s.authClient.CompleteOAuth(context.Background(), &authpb.CompleteOAuthRequest{
+		Provider:  c.Param("provider"),
+		Code:      c.Query("code"),
+		State:     state,
+		IpAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		s.logger.Warn("grpc complete oauth failed", zap.Error(err))
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			result.Set("error", "expired")
+		case codes.FailedPrecondition:
+			result.Set("error", "email_unverified")
+		default:
+			result.Set("error", "login_failed")
+		}
+		return
+	}
+
+	if resp.MfaRequired {
+		result.Set("mfa_required", "true")
+		result.Set("mfa_token", resp.MfaToken)
+		return
+	}
+	result.Set("token", resp.Token)
+	result.Set("refresh_token", resp.RefreshToken)
+	result.Set("expires_in", strconv.FormatInt(resp.ExpiresIn, 10))
+}
+
+func (s *Service) verifyMFA(c *gin.Context) {
+	var req struct {
+		MfaToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.MfaToken == "" || req.Code == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "mfa_token and code are required")
+		return
+	}
+
+	resp, err := s.authClient.VerifyMFA(context.Background(), &authpb.VerifyMFARequest{
+		MfaToken:  req.MfaToken,
+		Code:      req.Code,
+		IpAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		s.logger.Warn("grpc verify mfa failed", zap.Error(err))
+		if s.respondThrottled(c, err) {
+			return
+		}
+		if status.Code(err) == codes.Unauthenticated {
+			common.RespondError(c, http.StatusUnauthorized, "AUTH_FAILED", "Invalid or expired code")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to verify code")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"token":         resp.Token,
+		"refresh_token": resp.RefreshToken,
+		"expires_in":    resp.ExpiresIn,
+		"user":          resp.User,
+	})
+}
+
+func (s *Service) enrollTOTP(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	resp, err := s.authClient.EnrollTOTP(context.Background(), &authpb.EnrollTOTPRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		s.logger.Warn("grpc enroll totp failed", zap.Error(err))
+		s.respondTOTPError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"secret":      resp.Secret,
+		"otpauth_url": resp.OtpauthUrl,
+	})
+}
+
+func (s *Service) confirmTOTP(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "code is required")
+		return
+	}
+
+	resp, err := s.authClient.ConfirmTOTP(context.Background(), &authpb.ConfirmTOTPRequest{
+		UserId: userID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		s.logger.Warn("grpc confirm totp failed", zap.Error(err))
+		s.respondTOTPError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"recovery_codes": resp.RecoveryCodes})
+}
+
+func (s *Service) disableTOTP(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "code is required")
+		return
+	}
+
+	_, err := s.authClient.DisableTOTP(context.Background(), &authpb.DisableTOTPRequest{
+		UserId: userID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		s.logger.Warn("grpc disable totp failed", zap.Error(err))
+		s.respondTOTPError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"success": true})
+}
+
+// respondTOTPError maps errors from the TOTP management RPCs to HTTP
+func (s *Service) respondTOTPError(c *gin.Context, err error, fallback string) {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		common.RespondError(c, http.StatusBadRequest, "INVALID_CODE", "Invalid code")
+	case codes.FailedPrecondition:
+		common.RespondError(c, http.StatusConflict, "MFA_STATE_CONFLICT", status.Convert(err).Message())
+	case codes.NotFound:
+		common.RespondError(c, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+	default:
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", fallback)
+	}
+}
+
+func (s *Service) refreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "refresh_token is required")
+		return
+	}
+
+	resp, err := s.authClient.RefreshToken(context.Background(), &authpb.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+		IpAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	})
+	if err != nil {
+		s.logger.Warn("grpc refresh token failed", zap.Error(err))
+		if status.Code(err) == codes.Unauthenticated {
+			common.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "Refresh token is invalid, expired or revoked")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to refresh token")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"token":         resp.Token,
+		"refresh_token": resp.RefreshToken,
+		"expires_in":    resp.ExpiresIn,
+	})
+}
+
+func (s *Service) logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	// Body is optional: the bearer token alone is enough to end its session
+	_ = c.ShouldBindJSON(&req)
+
+	var accessToken string
+	if parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2); len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+		accessToken = parts[1]
+	}
+
+	if req.RefreshToken == "" && accessToken == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "refresh_token or Authorization header is required")
+		return
+	}
+
+	_, err := s.authClient.Logout(context.Background(), &authpb.LogoutRequest{
+		RefreshToken: req.RefreshToken,
+		Token:        accessToken,
+	})
+	if err != nil {
+		s.logger.Warn("grpc logout failed", zap.Error(err))
+		if status.Code(err) == codes.Unauthenticated {
+			common.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "Token is invalid")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to log out")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"success": true})
+}
+
+func (s *Service) register(c *gin.Context) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Username string `json:"username"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	// Validate email format
+	if err := validation.ValidateEmail(req.Email); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	// Validate password strength
+	if err := validation.ValidatePassword(req.Password); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	// A handle is optional at sign-up and can be picked later
+	if req.Username != "" {
+		if err := validation.ValidateUsername(req.Username); err != nil {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+	}
+
+	// Call gRPC service
+	resp, err := s.authClient.Register(context.Background(), &authpb.RegisterRequest{
+		Email:    req.Email,
+		Password: req.Password,
+		Username: req.Username,
+	})
+	if err != nil {
+		s.logger.Error("grpc register failed", zap.Error(err))
+		if s.respondUsernameError(c, err) {
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Registration failed")
+		return
+	}
+
+	common.RespondCreated(c, gin.H{
+		"id":       resp.Id,
+		"email":    resp.Email,
+		"username": req.Username,
+	})
+}
+
+// setRetryAfter copies the RetryInfo delay of an auth service error, if
+// any, into the Retry-After header
+func setRetryAfter(c *gin.Context, st *status.Status) {
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.RetryInfo); ok {
+			c.Header("Retry-After", strconv.Itoa(int(d.RetryDelay.AsDuration().Seconds())))
+			return
+		}
+	}
+}
+
+func (s *Service) requestMagicLink(c *gin.Context) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if err := validation.ValidateEmail(req.Email); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	_, err := s.authClient.RequestMagicLink(context.Background(), &authpb.RequestMagicLinkRequest{
+		Email:     req.Email,
+		IpAddress: c.ClientIP(),
+	})
+	if err != nil {
+		s.logger.Warn("grpc request magic link failed", zap.Error(err))
+		if st := status.Convert(err); st.Code() == codes.ResourceExhausted {
+			setRetryAfter(c, st)
+			common.RespondError(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "Too many sign-in links requested, try again later")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to send sign-in link")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"message": "Check your email for a sign-in link"})
+}
+
+func (s *Service) consumeMagicLink(c *gin.Context) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	resp, err := s.authClient.ConsumeMagicLink(context.Background(), &authpb.ConsumeMagicLinkRequest{
+		Token:     req.Token,
+		IpAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		s.logger.Warn("grpc consume magic link failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", "Sign-in link is invalid or has expired")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to sign in")
+		return
+	}
+
+	if resp.MfaRequired {
+		common.RespondSuccess(c, gin.H{
+			"mfa_required": true,
+			"mfa_token":    resp.MfaToken,
+		})
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"token":         resp.Token,
+		"refresh_token": resp.RefreshToken,
+		"expires_in":    resp.ExpiresIn,
+		"user":          resp.User,
+	})
+}
+
+func (s *Service) requestPasswordReset(c *gin.Context) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if err := validation.ValidateEmail(req.Email); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	_, err := s.authClient.RequestPasswordReset(context.Background(), &authpb.RequestPasswordResetRequest{
+		Email: req.Email,
+	})
+	if err != nil {
+		s.logger.Error("grpc request password reset failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to request password reset")
+		return
+	}
+
+	// Same answer for known and unknown emails
+	common.RespondSuccess(c, gin.H{"message": "If an account exists for that email, a reset link has been sent"})
+}
+
+func (s *Service) resetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "token and password are required")
+		return
+	}
+	if err := validation.ValidatePassword(req.Password); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	_, err := s.authClient.ResetPassword(context.Background(), &authpb.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.Password,
+	})
+	if err != nil {
+		s.logger.Warn("grpc reset password failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", "Reset link is invalid or has expired")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to reset password")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"success": true})
 }
 
-func (s *Service) getPost(c *gin.Context) {
-	postID := c.Param("id")
-	currentUserId := middleware.GetUserID(c)
+func (s *Service) sendVerificationEmail(c *gin.Context) {
+	userID := middleware.GetUserID(c)
 
-	resp, err := s.blogClient.GetPost(context.Background(), &blogpb.GetPostRequest{
-		PostId:        postID,
-		CurrentUserId: currentUserId,
+	resp, err := s.authClient.SendVerificationEmail(context.Background(), &authpb.SendVerificationEmailRequest{
+		UserId: userID,
 	})
 	if err != nil {
-		s.logger.Error("grpc get post failed", zap.Error(err))
-		common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Post not found")
+		s.logger.Error("grpc send verification email failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to send verification email")
 		return
 	}
 
-	common.RespondSuccess(c, resp.Post)
+	common.RespondSuccess(c, gin.H{
+		"success":          resp.Success,
+		"already_verified": resp.AlreadyVerified,
+	})
 }
 
-func (s *Service) updatePost(c *gin.Context) {
-	postID := c.Param("id")
-	userID := middleware.GetUserID(c)
-
+func (s *Service) verifyEmail(c *gin.Context) {
 	var req struct {
-		Title      string   `json:"title"`
-		Content    string   `json:"content"`
-		Tags       []string `json:"tags"`
-		CoverImage string   `json:"cover_image"`
+		Token string `json:"token"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "token is required")
 		return
 	}
 
-	resp, err := s.blogClient.UpdatePost(context.Background(), &blogpb.UpdatePostRequest{
-		PostId:     postID,
-		UserId:     userID,
-		Title:      req.Title,
-		Content:    req.Content,
-		Tags:       req.Tags,
-		CoverImage: req.CoverImage,
+	_, err := s.authClient.VerifyEmail(context.Background(), &authpb.VerifyEmailRequest{
+		Token: req.Token,
 	})
 	if err != nil {
-		s.logger.Error("grpc update post failed", zap.Error(err))
-		common.RespondError(c, http.StatusForbidden, "FORBIDDEN", err.Error())
+		s.logger.Warn("grpc verify email failed", zap.Error(err))
+		if status.Code(err) == codes.InvalidArgument {
+			common.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", "Verification link is invalid or has expired")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to verify email")
 		return
 	}
 
-	common.RespondSuccess(c, resp.Post)
+	common.RespondSuccess(c, gin.H{"success": true})
 }
 
-func (s *Service) deletePost(c *gin.Context) {
-	postID := c.Param("id")
+func (s *Service) listAccessTokens(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
-	_, err := s.blogClient.DeletePost(context.Background(), &blogpb.DeletePostRequest{
-		PostId: postID,
+	resp, err := s.authClient.ListAccessTokens(context.Background(), &authpb.ListAccessTokensRequest{
 		UserId: userID,
 	})
 	if err != nil {
-		s.logger.Error("grpc delete post failed", zap.Error(err))
-		common.RespondError(c, http.StatusForbidden, "FORBIDDEN", err.Error())
+		s.logger.Error("grpc list access tokens failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list tokens")
 		return
 	}
 
-	common.RespondSuccess(c, gin.H{"success": true})
+	common.RespondSuccess(c, gin.H{"tokens": resp.Tokens})
 }
 
-func (s *Service) createPost(c *gin.Context) {
+func (s *Service) createAccessToken(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
 	var req struct {
-		Title      string   `json:"title"`
-		Content    string   `json:"content"`
-		Tags       []string `json:"tags"`
-		CoverImage string   `json:"cover_image"`
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int32    `json:"expires_in_days"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
 		return
 	}
 
-	// Get author ID from authenticated user context
-	authorId := middleware.GetUserID(c)
-	if authorId == "" {
-		common.RespondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated")
+	resp, err := s.authClient.CreateAccessToken(context.Background(), &authpb.CreateAccessTokenRequest{
+		UserId:        userID,
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+	})
+	if err != nil {
+		s.logger.Warn("grpc create access token failed", zap.Error(err))
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", status.Convert(err).Message())
+		case codes.ResourceExhausted:
+			common.RespondError(c, http.StatusConflict, "TOO_MANY_TOKENS", status.Convert(err).Message())
+		default:
+			common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create token")
+		}
 		return
 	}
 
-	resp, err := s.blogClient.CreatePost(context.Background(), &blogpb.CreatePostRequest{
-		Title:      req.Title,
-		Content:    req.Content,
-		AuthorId:   authorId,
-		Tags:       req.Tags,
-		CoverImage: req.CoverImage,
+	// The token is only ever shown here
+	common.RespondCreated(c, gin.H{
+		"token":        resp.Token,
+		"access_token": resp.AccessToken,
+	})
+}
+
+func (s *Service) revokeAccessToken(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	_, err := s.authClient.RevokeAccessToken(context.Background(), &authpb.RevokeAccessTokenRequest{
+		UserId:  userID,
+		TokenId: c.Param("tokenId"),
 	})
 	if err != nil {
-		s.logger.Error("grpc create post failed", zap.Error(err))
-		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create post")
+		s.logger.Warn("grpc revoke access token failed", zap.Error(err))
+		if status.Code(err) == codes.NotFound {
+			common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Token not found")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke token")
 		return
 	}
 
-	common.RespondCreated(c, resp.Post)
+	common.RespondSuccess(c, gin.H{"success": true})
 }
 
-func (s *Service) healthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"timestamp": time.Now().UTC(),
-		"service":   "api-gateway",
-	})
-}
+func (s *Service) listSessions(c *gin.Context) {
+	userID := middleware.GetUserID(c)
 
-func (s *Service) login(c *gin.Context) {
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	resp, err := s.authClient.ListSessions(context.Background(), &authpb.ListSessionsRequest{
+		UserId:           userID,
+		CurrentSessionId: middleware.GetSessionID(c),
+	})
+	if err != nil {
+		s.logger.Error("grpc list sessions failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list sessions")
 		return
 	}
 
-	// Call gRPC service
-	resp, err := s.authClient.Login(context.Background(), &authpb.LoginRequest{
-		Email:    req.Email,
-		Password: req.Password,
+	common.RespondSuccess(c, gin.H{"sessions": resp.Sessions})
+}
+
+func (s *Service) revokeSession(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	_, err := s.authClient.RevokeSession(context.Background(), &authpb.RevokeSessionRequest{
+		UserId:    userID,
+		SessionId: c.Param("sessionId"),
 	})
 	if err != nil {
-		s.logger.Error("grpc login failed", zap.Error(err))
-		common.RespondError(c, http.StatusUnauthorized, "AUTH_FAILED", "Invalid credentials")
+		s.logger.Warn("grpc revoke session failed", zap.Error(err))
+		if status.Code(err) == codes.NotFound {
+			common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Session not found")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke session")
 		return
 	}
 
-	common.RespondSuccess(c, gin.H{
-		"token": resp.Token,
-		"user":  resp.User,
-	})
+	common.RespondSuccess(c, gin.H{"success": true})
 }
 
-func (s *Service) register(c *gin.Context) {
+func (s *Service) updateProfile(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
 	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Name      string `json:"name"`
+		Bio       string `json:"bio"`
+		AvatarUrl string `json:"avatar_url"`
+		Username  string `json:"username"`
+		Version   int64  `json:"version"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
 		return
 	}
 
-	// Validate email format
-	if err := validation.ValidateEmail(req.Email); err != nil {
-		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	// Unlike posts, profiles are only checked when a version is sent
+	version, ok := requestVersion(c, req.Version)
+	if !ok {
 		return
 	}
 
-	// Validate password strength
-	if err := validation.ValidatePassword(req.Password); err != nil {
-		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
-		return
+	if req.Username != "" {
+		if err := validation.ValidateUsername(req.Username); err != nil {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
 	}
 
-	// Call gRPC service
-	resp, err := s.authClient.Register(context.Background(), &authpb.RegisterRequest{
-		Email:    req.Email,
-		Password: req.Password,
+	resp, err := s.authClient.UpdateUser(context.Background(), &authpb.UpdateUserRequest{
+		UserId:    userID,
+		Name:      req.Name,
+		Bio:       req.Bio,
+		AvatarUrl: req.AvatarUrl,
+		Username:  req.Username,
+		Version:   version,
 	})
 	if err != nil {
-		s.logger.Error("grpc register failed", zap.Error(err))
-		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Registration failed")
+		s.logger.Error("grpc update user failed", zap.Error(err))
+		if respondVersionConflict(c, err) || s.respondUsernameError(c, err) {
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update profile")
 		return
 	}
 
-	common.RespondCreated(c, gin.H{
-		"id":    resp.Id,
-		"email": resp.Email,
+	setETag(c, resp.User.Version)
+	common.RespondSuccess(c, gin.H{
+		"id":         resp.User.Id,
+		"email":      resp.User.Email,
+		"username":   resp.User.Username,
+		"name":       resp.User.Name,
+		"bio":        resp.User.Bio,
+		"avatar_url": resp.User.AvatarUrl,
+		"version":    resp.User.Version,
 	})
 }
 
-func (s *Service) updateProfile(c *gin.Context) {
+// patchProfile changes only the profile fields present in the body, so
+// bio and avatar_url can be cleared by sending them empty or null
+func (s *Service) patchProfile(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
 	var req struct {
 		Name      string `json:"name"`
 		Bio       string `json:"bio"`
 		AvatarUrl string `json:"avatar_url"`
+		Username  string `json:"username"`
+		Version   int64  `json:"version"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	paths, ok := bindPatch(c, &req, "name", "bio", "avatar_url", "username")
+	if !ok {
+		return
+	}
+
+	version, ok := requestVersion(c, req.Version)
+	if !ok {
 		return
 	}
 
+	if slices.Contains(paths, "username") {
+		if err := validation.ValidateUsername(req.Username); err != nil {
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+	}
+
 	resp, err := s.authClient.UpdateUser(context.Background(), &authpb.UpdateUserRequest{
-		UserId:    userID,
-		Name:      req.Name,
-		Bio:       req.Bio,
-		AvatarUrl: req.AvatarUrl,
+		UserId:     userID,
+		Name:       req.Name,
+		Bio:        req.Bio,
+		AvatarUrl:  req.AvatarUrl,
+		Username:   req.Username,
+		Version:    version,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
 	})
 	if err != nil {
-		s.logger.Error("grpc update user failed", zap.Error(err))
+		s.logger.Error("grpc patch user failed", zap.Error(err))
+		if respondVersionConflict(c, err) || s.respondUsernameError(c, err) {
+			return
+		}
 		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update profile")
 		return
 	}
 
+	setETag(c, resp.User.Version)
 	common.RespondSuccess(c, gin.H{
 		"id":         resp.User.Id,
 		"email":      resp.User.Email,
+		"username":   resp.User.Username,
 		"name":       resp.User.Name,
 		"bio":        resp.User.Bio,
 		"avatar_url": resp.User.AvatarUrl,
+		"version":    resp.User.Version,
+	})
+}
+
+// respondUsernameError answers for auth service errors about the requested
+// handle and reports whether err was one
+func (s *Service) respondUsernameError(c *gin.Context, err error) bool {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.AlreadyExists:
+		common.RespondError(c, http.StatusConflict, "USERNAME_TAKEN", st.Message())
+	case codes.InvalidArgument:
+		common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", st.Message())
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Service) deleteAccount(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	// Accounts without a password confirm with a 2FA code instead, or send
+	// no body right after signing in again
+	var req struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	resp, err := s.authClient.DeleteAccount(context.Background(), &authpb.DeleteAccountRequest{
+		UserId:    userID,
+		Password:  req.Password,
+		Code:      req.Code,
+		SessionId: middleware.GetSessionID(c),
 	})
+	if err != nil {
+		s.logger.Warn("grpc delete account failed", zap.Error(err))
+		switch status.Code(err) {
+		case codes.PermissionDenied:
+			if req.Password == "" {
+				common.RespondError(c, http.StatusForbidden, "INVALID_CODE", "Code is incorrect")
+				return
+			}
+			common.RespondError(c, http.StatusForbidden, "INVALID_PASSWORD", "Password is incorrect")
+		case codes.FailedPrecondition:
+			common.RespondError(c, http.StatusForbidden, "REAUTHENTICATION_REQUIRED", "Sign in again to delete your account")
+		default:
+			common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete account")
+		}
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{"delete_after": resp.DeleteAfter})
+}
+
+// exportUserData streams a ZIP of everything both services hold about the
+// user. Auth data goes under auth/, blog data under blog/.
+func (s *Service) exportUserData(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	authResp, err := s.authClient.ExportUserData(context.Background(), &authpb.ExportUserDataRequest{UserId: userID})
+	if err != nil {
+		s.logger.Error("grpc export auth data failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to export data")
+		return
+	}
+	blogResp, err := s.blogClient.ExportUserData(context.Background(), &blogpb.ExportUserDataRequest{UserId: userID})
+	if err != nil {
+		s.logger.Error("grpc export blog data failed", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to export data")
+		return
+	}
+
+	// Build the archive in memory so a failure can still become a JSON error
+	var buf bytes.Buffer
+	if err := writeExportArchive(&buf, authResp.Files, blogResp.Files); err != nil {
+		s.logger.Error("failed to build export archive", zap.Error(err))
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to export data")
+		return
+	}
+
+	filename := "minimum-export-" + time.Now().UTC().Format("2006-01-02") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+func writeExportArchive(w io.Writer, authFiles []*authpb.ExportFile, blogFiles []*blogpb.ExportFile) error {
+	zw := zip.NewWriter(w)
+	add := func(name string, content []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	}
+	for _, f := range authFiles {
+		if err := add("auth/"+f.Name, f.Content); err != nil {
+			return err
+		}
+	}
+	for _, f := range blogFiles {
+		if err := add("blog/"+f.Name, f.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
 }
 
 func (s *Service) getUser(c *gin.Context) {
@@ -447,9 +1996,44 @@ func (s *Service) getUser(c *gin.Context) {
 		return
 	}
 
+	// The user has changed handle since; point clients at the current one
+	if resp.Moved {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/users/@"+resp.User.Username)
+		return
+	}
+
+	// Users read their own profile before editing it. The version they'll
+	// send back is checked by the auth service, which the blog's copy of
+	// the profile may trail.
+	if resp.User.Id == currentUserId {
+		account, err := s.authClient.GetUser(context.Background(), &authpb.GetUserRequest{UserId: currentUserId})
+		if err != nil {
+			s.logger.Warn("grpc get account failed", zap.Error(err))
+		} else {
+			resp.User.Version = account.User.Version
+			setETag(c, resp.User.Version)
+		}
+	}
 	common.RespondSuccess(c, resp.User)
 }
 
+// resolveUserParam returns the user ID for the :id route parameter, which
+// may also be an @handle. It responds with 404 and returns false when the
+// handle doesn't belong to anyone.
+func (s *Service) resolveUserParam(c *gin.Context) (string, bool) {
+	ref := c.Param("id")
+	if !strings.HasPrefix(ref, "@") {
+		return ref, true
+	}
+
+	resp, err := s.blogClient.GetUser(context.Background(), &blogpb.GetUserRequest{Id: ref})
+	if err != nil {
+		common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User not found")
+		return "", false
+	}
+	return resp.User.Id, true
+}
+
 func (s *Service) toggleClap(c *gin.Context) {
 	postId := c.Param("id")
 	userId := middleware.GetUserID(c)
@@ -471,7 +2055,10 @@ func (s *Service) toggleClap(c *gin.Context) {
 }
 
 func (s *Service) toggleFollow(c *gin.Context) {
-	followeeId := c.Param("id")
+	followeeId, ok := s.resolveUserParam(c)
+	if !ok {
+		return
+	}
 	followerId := middleware.GetUserID(c)
 
 	resp, err := s.blogClient.ToggleFollow(context.Background(), &blogpb.ToggleFollowRequest{
@@ -480,6 +2067,10 @@ func (s *Service) toggleFollow(c *gin.Context) {
 	})
 	if err != nil {
 		s.logger.Error("grpc toggle follow failed", zap.Error(err))
+		if status.Code(err) == codes.PermissionDenied {
+			common.RespondError(c, http.StatusForbidden, "FORBIDDEN", status.Convert(err).Message())
+			return
+		}
 		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to toggle follow")
 		return
 	}
@@ -489,6 +2080,55 @@ func (s *Service) toggleFollow(c *gin.Context) {
 	})
 }
 
+func (s *Service) toggleBlock(c *gin.Context) {
+	blockedID, ok := s.resolveUserParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := s.blogClient.ToggleBlock(context.Background(), &blogpb.ToggleBlockRequest{
+		BlockerId: middleware.GetUserID(c),
+		BlockedId: blockedID,
+	})
+	if err != nil {
+		s.logger.Error("grpc toggle block failed", zap.Error(err))
+		st := status.Convert(err)
+		switch st.Code() {
+		case codes.InvalidArgument:
+			common.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", st.Message())
+		case codes.NotFound:
+			common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User not found")
+		default:
+			common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to toggle block")
+		}
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"blocked": resp.Blocked,
+	})
+}
+
+func (s *Service) toggleTagFollow(c *gin.Context) {
+	resp, err := s.blogClient.ToggleTagFollow(context.Background(), &blogpb.ToggleTagFollowRequest{
+		UserId: middleware.GetUserID(c),
+		Tag:    c.Param("name"),
+	})
+	if err != nil {
+		s.logger.Error("grpc toggle tag follow failed", zap.Error(err))
+		if status.Code(err) == codes.NotFound {
+			common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Tag not found")
+			return
+		}
+		common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to toggle tag follow")
+		return
+	}
+
+	common.RespondSuccess(c, gin.H{
+		"following": resp.Following,
+	})
+}
+
 func (s *Service) toggleBookmark(c *gin.Context) {
 	postId := c.Param("id")
 	userId := middleware.GetUserID(c)
@@ -589,8 +2229,9 @@ func (s *Service) deleteComment(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
 	_, err := s.blogClient.DeleteComment(context.Background(), &blogpb.DeleteCommentRequest{
-		CommentId: commentID,
-		UserId:    userID,
+		CommentId:   commentID,
+		UserId:      userID,
+		Permissions: middleware.GetPermissions(c),
 	})
 	if err != nil {
 		s.logger.Error("grpc delete comment failed", zap.Error(err))
@@ -601,6 +2242,44 @@ func (s *Service) deleteComment(c *gin.Context) {
 	common.RespondSuccess(c, gin.H{"success": true})
 }
 
+func (s *Service) setUserRoles(c *gin.Context) {
+	var req struct {
+		Roles []string `json:"roles"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	userID, ok := s.resolveUserParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := s.authClient.SetUserRoles(context.Background(), &authpb.SetUserRolesRequest{
+		ActorId: middleware.GetUserID(c),
+		UserId:  userID,
+		Roles:   req.Roles,
+	})
+	if err != nil {
+		s.logger.Warn("grpc set user roles failed", zap.Error(err))
+		st := status.Convert(err)
+		switch st.Code() {
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			common.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", st.Message())
+		case codes.PermissionDenied:
+			common.RespondError(c, http.StatusForbidden, "INSUFFICIENT_PERMISSIONS", st.Message())
+		case codes.NotFound:
+			common.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User not found")
+		default:
+			common.RespondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update roles")
+		}
+		return
+	}
+
+	common.RespondSuccess(c, resp.User)
+}
+
 // WebSocket upgrader with origin validation
 func (s *Service) newWSUpgrader() websocket.Upgrader {
 	return websocket.Upgrader{
@@ -630,14 +2309,18 @@ func (s *Service) handleWebSocket(c *gin.Context) {
 	}
 
 	// Validate token
-	resp, err := s.authClient.Validate(context.Background(), &authpb.ValidateRequest{Token: token})
-	if err != nil || !resp.Valid {
+	identity, err := s.verifier.Verify(c.Request.Context(), token)
+	if err != nil {
 		s.logger.Warn("WebSocket auth failed", zap.Error(err))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
 	}
+	if !identity.HasScope(tokens.ScopeRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the read scope"})
+		return
+	}
 
-	userID := resp.UserId
+	userID := identity.UserID
 
 	// Upgrade HTTP to WebSocket
 	upgrader := s.newWSUpgrader()
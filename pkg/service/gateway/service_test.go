@@ -0,0 +1,206 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"project/pkg/common"
+	blogpb "project/pkg/proto/blog"
+)
+
+func TestRequestVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion int64
+		want        int64
+		wantOK      bool
+	}{
+		{"etag", `"7"`, 0, 7, true},
+		{"weak etag", `W/"7"`, 3, 7, true},
+		{"body version", "", 3, 3, true},
+		{"any version", "*", 3, 0, true},
+		{"malformed", "abc", 0, 0, false},
+		{"missing", "", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/posts/1", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, ok := requestVersion(c, tt.bodyVersion)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("requestVersion() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if !ok && w.Code != http.StatusBadRequest {
+				t.Errorf("requestVersion() status = %d, want 400", w.Code)
+			}
+		})
+	}
+}
+
+func TestPostVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion int64
+		want        int64
+		wantStatus  int // 0 when the version is accepted
+	}{
+		{"etag", `"7"`, 0, 7, 0},
+		{"weak etag", `W/"7"`, 3, 7, 0},
+		{"body version", "", 3, 3, 0},
+		{"any version", "*", 3, 0, http.StatusBadRequest},
+		{"malformed", "abc", 0, 0, http.StatusBadRequest},
+		{"missing", "", 0, 0, http.StatusPreconditionRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/posts/1", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, ok := postVersion(c, tt.bodyVersion)
+			if ok != (tt.wantStatus == 0) || got != tt.want {
+				t.Fatalf("postVersion() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantStatus == 0)
+			}
+			if !ok && w.Code != tt.wantStatus {
+				t.Errorf("postVersion() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRespondVersionConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if respondVersionConflict(c, errors.New("boom")) {
+		t.Fatal("respondVersionConflict() handled an unrelated error")
+	}
+
+	if !respondVersionConflict(c, common.VersionConflictError("post", 5)) {
+		t.Fatal("respondVersionConflict() didn't handle a version conflict")
+	}
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", w.Code)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"5"` {
+		t.Errorf("ETag = %s, want \"5\"", etag)
+	}
+	var body struct {
+		Error struct{ Code string }
+		Data  struct {
+			CurrentVersion int64 `json:"current_version"`
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.Code != "VERSION_CONFLICT" || body.Data.CurrentVersion != 5 {
+		t.Errorf("body = %s, want VERSION_CONFLICT with current_version 5", w.Body.String())
+	}
+}
+
+// fakeBlog keeps the UpdatePost request it gets and answers it with err
+type fakeBlog struct {
+	blogpb.BlogServiceClient
+	err error
+	got *blogpb.UpdatePostRequest
+}
+
+func (f *fakeBlog) UpdatePost(ctx context.Context, in *blogpb.UpdatePostRequest, opts ...grpc.CallOption) (*blogpb.UpdatePostResponse, error) {
+	f.got = in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &blogpb.UpdatePostResponse{Post: &blogpb.Post{Id: in.PostId, Version: in.Version + 1}}, nil
+}
+
+func TestUpdatePostKeepsOmittedFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{`{"title":"T","content":"C"}`, []string{"title", "content"}},
+		{`{"title":"T","content":"C","tags":[],"cover_image":""}`, []string{"title", "content", "tags", "cover_image"}},
+		{`{"title":"T","content":"C","subtitle":"S"}`, []string{"title", "content", "subtitle"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			blog := &fakeBlog{}
+			svc := &Service{logger: zap.NewNop(), blogClient: blog}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/posts/1", strings.NewReader(tt.body))
+			c.Request.Header.Set("If-Match", `"3"`)
+
+			svc.updatePost(c)
+			if w.Code != http.StatusOK {
+				t.Fatalf("updatePost() = %d %s, want 200", w.Code, w.Body.String())
+			}
+			if got := blog.got.UpdateMask.GetPaths(); !slices.Equal(got, tt.want) {
+				t.Errorf("update mask = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePostErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{status.Error(codes.NotFound, "post not found"), http.StatusNotFound, "NOT_FOUND"},
+		{status.Error(codes.PermissionDenied, "only the author can change this post"), http.StatusForbidden, "FORBIDDEN"},
+		{status.Error(codes.InvalidArgument, "title is required"), http.StatusBadRequest, "VALIDATION_ERROR"},
+		// Internal details stay out of the response
+		{status.Error(codes.Internal, "pq: connection refused"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(status.Code(tt.err).String(), func(t *testing.T) {
+			svc := &Service{logger: zap.NewNop(), blogClient: &fakeBlog{err: tt.err}}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/posts/1", strings.NewReader(`{"title":"T","content":"C"}`))
+			c.Request.Header.Set("If-Match", `"3"`)
+
+			svc.updatePost(c)
+			if w.Code != tt.wantCode || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("updatePost() = %d %s, want %d %s", w.Code, w.Body.String(), tt.wantCode, tt.wantBody)
+			}
+			if strings.Contains(w.Body.String(), "pq:") {
+				t.Errorf("updatePost() leaked the error: %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package tokens
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer is the iss claim of every token minted by the auth service
+const Issuer = "minimum-auth"
+
+// TypeMFA marks the short-lived challenge token issued between a correct
+// password and a correct second factor. It must never be accepted as an
+// access token.
+const TypeMFA = "mfa"
+
+// Claims are the claims carried by access tokens
+type Claims struct {
+	UserID    string   `json:"user_id"`
+	Email     string   `json:"email,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Type      string   `json:"typ,omitempty"` // Empty for access tokens
+	jwt.RegisteredClaims
+}
+
+// Parse verifies a token with keyfunc and returns its claims
+func Parse(tokenString string, keyfunc jwt.Keyfunc, opts ...jwt.ParserOption) (*Claims, error) {
+	opts = append([]jwt.ParserOption{
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(Issuer),
+	}, opts...)
+
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, keyfunc, opts...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
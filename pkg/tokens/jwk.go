@@ -0,0 +1,160 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// PublicJWK encodes an RSA or Ed25519 public key as a JWK
+func PublicJWK(kid, alg string, pub crypto.PublicKey) (JWK, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: alg,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Alg: alg,
+			Use: "sig",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+}
+
+// ParseJWK decodes the public key held by a JWK
+func ParseJWK(jwk JWK) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// KeySet is a set of public keys used to verify tokens without access to
+// the private keys, e.g. one fetched from the auth service's JWKS
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]JWK
+	pubs map[string]crypto.PublicKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]JWK), pubs: make(map[string]crypto.PublicKey)}
+}
+
+// Replace swaps the set's contents for jwks. Keys that fail to parse are
+// skipped; the first parse error is returned alongside.
+func (ks *KeySet) Replace(jwks []JWK) error {
+	keys := make(map[string]JWK, len(jwks))
+	pubs := make(map[string]crypto.PublicKey, len(jwks))
+	var firstErr error
+	for _, jwk := range jwks {
+		pub, err := ParseJWK(jwk)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		keys[jwk.Kid] = jwk
+		pubs[jwk.Kid] = pub
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+	ks.pubs = pubs
+	return firstErr
+}
+
+// Len returns the number of keys in the set
+func (ks *KeySet) Len() int {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return len(ks.pubs)
+}
+
+// Has reports whether the set contains a key with the given ID
+func (ks *KeySet) Has(kid string) bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	_, ok := ks.pubs[kid]
+	return ok
+}
+
+// Keyfunc resolves the verification key for a token by its kid header
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	pub, ok := ks.pubs[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	// Fall back to the algorithm implied by the key type when alg is absent
+	alg := ks.keys[kid].Alg
+	if alg == "" {
+		alg = AlgEdDSA
+		if _, isRSA := pub.(*rsa.PublicKey); isRSA {
+			alg = AlgRS256
+		}
+	}
+	if token.Method.Alg() != alg {
+		return nil, ErrAlgMismatch
+	}
+	return pub, nil
+}
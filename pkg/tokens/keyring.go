@@ -0,0 +1,327 @@
+// Package tokens manages the asymmetric keys used to sign and verify JWTs
+// issued by the auth service.
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrNoKeys         = errors.New("keyring has no signing keys")
+	ErrUnknownKey     = errors.New("unknown key id")
+	ErrAlgMismatch    = errors.New("token algorithm does not match key")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+// Key is a private signing key identified by its key ID (kid)
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// Public returns the public half of the key
+func (k *Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// SigningMethod returns the jwt signing method for the key's algorithm
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	return signingMethod(k.Algorithm)
+}
+
+// Keyring holds every key that may still verify tokens, and signs new
+// tokens with the newest one. Keys are PKCS#8 PEM files named <kid>.pem.
+type Keyring struct {
+	mu        sync.RWMutex
+	dir       string
+	algorithm string
+	keys      map[string]*Key
+	current   *Key
+}
+
+// NewKeyring loads keys from dir. An empty dir yields an in-memory keyring,
+// which is only suitable for development. algorithm is used for generated keys.
+func NewKeyring(dir, algorithm string) (*Keyring, error) {
+	if algorithm != AlgRS256 && algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	r := &Keyring{dir: dir, algorithm: algorithm, keys: make(map[string]*Key)}
+	if dir != "" {
+		if err := r.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Persistent reports whether keys are backed by files
+func (r *Keyring) Persistent() bool {
+	return r.dir != ""
+}
+
+// Len returns the number of keys in the ring
+func (r *Keyring) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.keys)
+}
+
+// Reload re-reads the key directory, picking up keys added or removed externally
+func (r *Keyring) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return fmt.Errorf("read key directory: %w", err)
+	}
+
+	keys := make(map[string]*Key)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+		path := filepath.Join(r.dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		key, err := loadKeyFile(path)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", entry.Name(), err)
+		}
+		key.ID = strings.TrimSuffix(entry.Name(), ".pem")
+		key.CreatedAt = info.ModTime()
+		keys[key.ID] = key
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = keys
+	r.current = newest(keys)
+	return nil
+}
+
+// Generate creates a new key, persists it when the ring is file-backed,
+// and makes it the current signing key
+func (r *Keyring) Generate() (*Key, error) {
+	var signer crypto.Signer
+	var err error
+	switch r.algorithm {
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Time-ordered prefix for readability, random suffix for uniqueness
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	key := &Key{
+		ID:        now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		Algorithm: r.algorithm,
+		Private:   signer,
+		CreatedAt: now,
+	}
+
+	if r.dir != "" {
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return nil, err
+		}
+		data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(filepath.Join(r.dir, key.ID+".pem"), data, 0o600); err != nil {
+			return nil, fmt.Errorf("write key: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[key.ID] = key
+	r.current = key
+	return key, nil
+}
+
+// Rotate generates a new signing key once the current one is older than
+// maxAge, and drops keys that stopped signing more than retain ago. retain
+// must exceed the lifetime of any token signed by the ring.
+func (r *Keyring) Rotate(maxAge, retain time.Duration) (rotated bool, err error) {
+	current := r.Current()
+	if current == nil || time.Since(current.CreatedAt) >= maxAge {
+		if _, err := r.Generate(); err != nil {
+			return false, err
+		}
+		rotated = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// A key stops signing when its successor is created, so expiry is
+	// measured from the successor's creation time
+	ordered := sortedKeys(r.keys)
+	for i := 0; i < len(ordered)-1; i++ {
+		retiredAt := ordered[i+1].CreatedAt
+		if time.Since(retiredAt) <= retain {
+			continue
+		}
+		old := ordered[i]
+		if r.dir != "" {
+			if err := os.Remove(filepath.Join(r.dir, old.ID+".pem")); err != nil && !os.IsNotExist(err) {
+				return rotated, fmt.Errorf("remove retired key %s: %w", old.ID, err)
+			}
+		}
+		delete(r.keys, old.ID)
+	}
+	return rotated, nil
+}
+
+// Current returns the key used to sign new tokens
+func (r *Keyring) Current() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Lookup returns the key with the given ID
+func (r *Keyring) Lookup(kid string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Keys returns all keys, oldest first
+func (r *Keyring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.keys)
+}
+
+// Sign signs claims with the current key and stamps its kid in the header
+func (r *Keyring) Sign(claims jwt.Claims) (string, error) {
+	key := r.Current()
+	if key == nil {
+		return "", ErrNoKeys
+	}
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc resolves the verification key for a token by its kid header
+func (r *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.Lookup(kid)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, ErrAlgMismatch
+	}
+	return key.Public(), nil
+}
+
+// JWKS returns the public keys in JSON Web Key form
+func (r *Keyring) JWKS() []JWK {
+	keys := r.Keys()
+	jwks := make([]JWK, 0, len(keys))
+	for _, key := range keys {
+		jwk, err := PublicJWK(key.ID, key.Algorithm, key.Public())
+		if err != nil {
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+func loadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &Key{Algorithm: AlgRS256, Private: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{Algorithm: AlgEdDSA, Private: k}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	if alg == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+func sortedKeys(keys map[string]*Key) []*Key {
+	list := make([]*Key, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, k)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+func newest(keys map[string]*Key) *Key {
+	list := sortedKeys(keys)
+	if len(list) == 0 {
+		return nil
+	}
+	return list[len(list)-1]
+}
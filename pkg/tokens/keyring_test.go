@@ -0,0 +1,115 @@
+package tokens
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testClaims() *Claims {
+	now := time.Now()
+	return &Claims{
+		UserID:    "user-1",
+		SessionID: "session-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+}
+
+func TestKeyringSignAndParse(t *testing.T) {
+	for _, alg := range []string{AlgEdDSA, AlgRS256} {
+		t.Run(alg, func(t *testing.T) {
+			ring, err := NewKeyring("", alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ring.Sign(testClaims()); err != ErrNoKeys {
+				t.Fatalf("Sign() on empty ring error = %v, want ErrNoKeys", err)
+			}
+			if _, err := ring.Generate(); err != nil {
+				t.Fatal(err)
+			}
+
+			signed, err := ring.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			claims, err := Parse(signed, ring.Keyfunc)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if claims.UserID != "user-1" || claims.SessionID != "session-1" {
+				t.Errorf("Parse() claims = %+v", claims)
+			}
+
+			// A token from another ring must not verify
+			other, _ := NewKeyring("", alg)
+			other.Generate()
+			if _, err := Parse(signed, other.Keyfunc); err == nil {
+				t.Error("Parse() accepted a token signed by an unknown key")
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	dir := t.TempDir()
+	ring, err := NewKeyring(dir, AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := ring.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, _ := ring.Sign(testClaims())
+
+	// Pretend the first key is old enough to rotate
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(dir, first.ID+".pem"), old, old)
+	if err := ring.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := ring.Rotate(24*time.Hour, time.Hour)
+	if err != nil || !rotated {
+		t.Fatalf("Rotate() = %v, %v; want rotation", rotated, err)
+	}
+	if ring.Current().ID == first.ID {
+		t.Fatal("Rotate() kept the old signing key")
+	}
+
+	// Tokens signed by the previous key still verify during retention
+	if _, err := Parse(signed, ring.Keyfunc); err != nil {
+		t.Errorf("Parse() with retired key error = %v", err)
+	}
+	if len(ring.JWKS()) != 2 {
+		t.Errorf("JWKS() returned %d keys, want 2", len(ring.JWKS()))
+	}
+
+	// A fresh ring over the same directory sees both keys
+	reloaded, err := NewKeyring(dir, AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Len() != 2 || reloaded.Current().ID != ring.Current().ID {
+		t.Errorf("reloaded ring has %d keys, current %q", reloaded.Len(), reloaded.Current().ID)
+	}
+
+	// Once retention has passed the retired key is dropped
+	if _, err := ring.Rotate(24*time.Hour, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ring.Lookup(first.ID); ok {
+		t.Error("Rotate() kept a key past its retention")
+	}
+	if _, err := os.Stat(filepath.Join(dir, first.ID+".pem")); !os.IsNotExist(err) {
+		t.Error("Rotate() did not delete the retired key file")
+	}
+}
@@ -0,0 +1,46 @@
+package tokens
+
+import "slices"
+
+// Roles that can be assigned to users. Every account is implicitly a plain
+// user; these grant extra permissions on top.
+const (
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Roles lists every assignable role
+var Roles = []string{RoleModerator, RoleAdmin}
+
+// Permissions held through roles. They let a caller act on content and
+// accounts that are not their own.
+const (
+	PermPostsEditAny      = "posts:edit_any"
+	PermPostsDeleteAny    = "posts:delete_any"
+	PermCommentsDeleteAny = "comments:delete_any"
+	PermUsersManage       = "users:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleModerator: {PermPostsDeleteAny, PermCommentsDeleteAny},
+	RoleAdmin:     {PermPostsEditAny, PermPostsDeleteAny, PermCommentsDeleteAny, PermUsersManage},
+}
+
+// ValidRole reports whether role can be assigned
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// PermissionsFor returns the permissions granted by roles, without
+// duplicates. Unknown roles grant nothing.
+func PermissionsFor(roles []string) []string {
+	var perms []string
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if !slices.Contains(perms, p) {
+				perms = append(perms, p)
+			}
+		}
+	}
+	return perms
+}
@@ -0,0 +1,34 @@
+package tokens
+
+import "strings"
+
+// PersonalAccessTokenPrefix starts every personal access token, so they can
+// be told apart from JWTs without a lookup (and spotted by secret scanners)
+const PersonalAccessTokenPrefix = "mpat_"
+
+// Scopes that can be granted to personal access tokens. Session tokens from
+// an interactive login are not scoped.
+const (
+	ScopeRead          = "read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+	ScopeUsersWrite    = "users:write"
+)
+
+// Scopes lists every grantable scope
+var Scopes = []string{ScopeRead, ScopePostsWrite, ScopeCommentsWrite, ScopeUsersWrite}
+
+// ValidScope reports whether scope can be granted
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPersonalAccessToken reports whether token is a personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
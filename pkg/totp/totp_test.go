@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret "12345678901234567890" from RFC 6238 Appendix B
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := Code(rfcSecret, now.Add(-Period*time.Second))
+
+	step, ok := Validate(rfcSecret, code, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("Validate() = %d, %v, want previous step accepted", step, ok)
+	}
+	if _, ok := Validate(rfcSecret, code, now, 0); ok {
+		t.Error("Validate() accepted a code outside the skew window")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Error("Validate() accepted a short code")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Minimum", "alice@example.com", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/Minimum:alice@example.com?") || !strings.Contains(uri, "secret=ABC") {
+		t.Errorf("URI() = %s", uri)
+	}
+}
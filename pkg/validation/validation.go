@@ -18,6 +18,8 @@ var (
 	ErrTitleTooLong     = errors.New("title must be less than 255 characters")
 	ErrContentRequired  = errors.New("content is required")
 	ErrContentTooLong   = errors.New("content must be less than 50000 characters")
+	ErrUsernameInvalid  = errors.New("username must be 3-30 characters of letters, numbers and underscores, starting with a letter")
+	ErrUsernameReserved = errors.New("username is reserved")
 )
 
 // Email validation regex (RFC 5322 simplified)
@@ -63,6 +65,30 @@ func ValidatePassword(password string) error {
 	return nil
 }
 
+var usernameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{2,29}$`)
+
+// reservedUsernames would clash with routes or pass for staff accounts
+var reservedUsernames = map[string]bool{
+	"about": true, "admin": true, "administrator": true, "api": true, "auth": true,
+	"bookmarks": true, "explore": true, "feed": true, "help": true, "login": true,
+	"logout": true, "me": true, "minimum": true, "moderator": true, "new": true,
+	"notifications": true, "null": true, "posts": true, "register": true, "root": true,
+	"search": true, "settings": true, "signup": true, "staff": true, "support": true,
+	"system": true, "tags": true, "undefined": true, "users": true, "www": true,
+}
+
+// ValidateUsername checks a user handle. Handles are compared without
+// regard to case.
+func ValidateUsername(username string) error {
+	if !usernameRegex.MatchString(username) {
+		return ErrUsernameInvalid
+	}
+	if reservedUsernames[strings.ToLower(username)] {
+		return ErrUsernameReserved
+	}
+	return nil
+}
+
 // ValidatePostTitle checks post title
 func ValidatePostTitle(title string) error {
 	title = strings.TrimSpace(title)